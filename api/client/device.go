@@ -0,0 +1,77 @@
+package client
+
+import (
+	"fmt"
+
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/engine-api/types/container"
+)
+
+// CmdDevice is the parent subcommand for all device commands
+//
+// Usage: docker device <COMMAND> <OPTS>
+func (cli *DockerCli) CmdDevice(args ...string) error {
+	description := Cli.DockerCommands["device"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"add", "Add devices to a running container"},
+		{"rm", "Remove devices from a running container"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker device COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("device", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdDeviceAdd adds devices and device cgroup rules to a running container.
+//
+// Usage: docker device add [OPTIONS] CONTAINER [DEVICE...]
+func (cli *DockerCli) CmdDeviceAdd(args ...string) error {
+	return cli.updateDevices("add", "Add devices to a running container", false, args...)
+}
+
+// CmdDeviceRm removes devices and device cgroup rules from a running container.
+//
+// Usage: docker device rm [OPTIONS] CONTAINER [DEVICE...]
+func (cli *DockerCli) CmdDeviceRm(args ...string) error {
+	return cli.updateDevices("rm", "Remove devices from a running container", true, args...)
+}
+
+func (cli *DockerCli) updateDevices(name, description string, remove bool, args ...string) error {
+	cmd := Cli.Subcmd("device "+name, []string{"CONTAINER [DEVICE...]"}, description, true)
+	flRules := opts.NewListOpts(runconfigopts.ValidateDeviceCgroupRule)
+	cmd.Var(&flRules, []string{"-device-cgroup-rule"}, "Device cgroup rule")
+	cmd.Require(flag.Min, 1)
+	cmd.ParseFlags(args, true)
+
+	config := container.DevicesUpdateConfig{
+		Remove:            remove,
+		DeviceCgroupRules: flRules.GetAll(),
+	}
+	for _, arg := range cmd.Args()[1:] {
+		if _, err := runconfigopts.ValidateDevice(arg); err != nil {
+			return err
+		}
+		device, err := runconfigopts.ParseDevice(arg)
+		if err != nil {
+			return err
+		}
+		config.Devices = append(config.Devices, device)
+	}
+	if len(config.Devices) == 0 && len(config.DeviceCgroupRules) == 0 {
+		cmd.Usage()
+		return fmt.Errorf("No devices or device cgroup rules given")
+	}
+
+	return cli.client.ContainerDevicesUpdate(cmd.Arg(0), config)
+}
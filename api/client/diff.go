@@ -2,10 +2,14 @@ package client
 
 import (
 	"fmt"
+	"path"
+	"sort"
+	"strings"
 
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/pkg/archive"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/engine-api/types"
 )
 
 // CmdDiff shows changes on a container's filesystem.
@@ -14,9 +18,15 @@ import (
 // character that indicates the status of the file: C (modified), A (added),
 // or D (deleted).
 //
-// Usage: docker diff CONTAINER
+// With --read-only-advice, the changes are instead read as the writes a
+// trial run of the container made, and the paths that would fail with a
+// read-only root filesystem are printed along with the tmpfs mounts that
+// keep them writable.
+//
+// Usage: docker diff [OPTIONS] CONTAINER
 func (cli *DockerCli) CmdDiff(args ...string) error {
 	cmd := Cli.Subcmd("diff", []string{"CONTAINER"}, Cli.DockerCommands["diff"].Description, true)
+	advice := cmd.Bool([]string{"-read-only-advice"}, false, "Report the paths the container writes that fail with --read-only")
 	cmd.Require(flag.Exact, 1)
 
 	cmd.ParseFlags(args, true)
@@ -30,6 +40,23 @@ func (cli *DockerCli) CmdDiff(args ...string) error {
 		return err
 	}
 
+	if *advice {
+		writes := readOnlyWrites(changes)
+		if len(writes) == 0 {
+			fmt.Fprintln(cli.out, "The container did not write to its root filesystem.")
+			return nil
+		}
+		fmt.Fprintln(cli.out, "The container wrote to the following paths, which fail with --read-only:")
+		for _, p := range writes {
+			fmt.Fprintf(cli.out, "  %s\n", p)
+		}
+		if mounts := tmpfsAdvice(changes); len(mounts) > 0 {
+			fmt.Fprintln(cli.out, "Mount a tmpfs (or a volume) at these paths to keep them writable:")
+			fmt.Fprintf(cli.out, "  --tmpfs %s\n", strings.Join(mounts, " --tmpfs "))
+		}
+		return nil
+	}
+
 	for _, change := range changes {
 		var kind string
 		switch change.Kind {
@@ -45,3 +72,68 @@ func (cli *DockerCli) CmdDiff(args ...string) error {
 
 	return nil
 }
+
+// readOnlyWrites returns the paths of changes that are not a parent
+// directory of another change, sorted. Parent directories show up as
+// modified only because of the entries below them.
+func readOnlyWrites(changes []types.ContainerChange) []string {
+	var writes []string
+	for _, c := range changes {
+		if !hasChangeBelow(changes, c.Path) {
+			writes = append(writes, c.Path)
+		}
+	}
+	sort.Strings(writes)
+	return writes
+}
+
+// tmpfsAdvice returns the directories at which a tmpfs covers all the writes
+// in changes. A new directory is mounted over as a whole, anything else is
+// covered by a mount of its parent directory. Writes directly below / are
+// left out, as a tmpfs over / is the same as not using --read-only.
+func tmpfsAdvice(changes []types.ContainerChange) []string {
+	added := make(map[string]bool)
+	for _, c := range changes {
+		if c.Kind == archive.ChangeAdd {
+			added[c.Path] = true
+		}
+	}
+
+	var dirs []string
+	for _, p := range readOnlyWrites(changes) {
+		// Move up to the topmost directory created by the container.
+		for added[path.Dir(p)] {
+			p = path.Dir(p)
+		}
+		dir := path.Dir(p)
+		if added[p] && hasChangeBelow(changes, p) {
+			dir = p
+		}
+		if dir != "/" {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	// Drop duplicates and the directories below another one.
+	var advice []string
+next:
+	for _, d := range dirs {
+		for _, a := range advice {
+			if a == d || strings.HasPrefix(d, a+"/") {
+				continue next
+			}
+		}
+		advice = append(advice, d)
+	}
+	return advice
+}
+
+func hasChangeBelow(changes []types.ContainerChange, dir string) bool {
+	for _, c := range changes {
+		if strings.HasPrefix(c.Path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
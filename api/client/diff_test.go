@@ -0,0 +1,38 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/engine-api/types"
+)
+
+func TestReadOnlyAdvice(t *testing.T) {
+	changes := []types.ContainerChange{
+		{Kind: archive.ChangeModify, Path: "/etc"},
+		{Kind: archive.ChangeModify, Path: "/etc/hosts.allow"},
+		{Kind: archive.ChangeModify, Path: "/tmp"},
+		{Kind: archive.ChangeAdd, Path: "/tmp/app.sock"},
+		{Kind: archive.ChangeModify, Path: "/var"},
+		{Kind: archive.ChangeModify, Path: "/var/lib"},
+		{Kind: archive.ChangeAdd, Path: "/var/lib/app"},
+		{Kind: archive.ChangeAdd, Path: "/var/lib/app/data"},
+		{Kind: archive.ChangeAdd, Path: "/var/lib/app/data/db"},
+		{Kind: archive.ChangeAdd, Path: "/var/lib/app/lock"},
+		{Kind: archive.ChangeDelete, Path: "/var/lib/old"},
+		{Kind: archive.ChangeAdd, Path: "/.initialized"},
+	}
+
+	writes := readOnlyWrites(changes)
+	expected := []string{"/.initialized", "/etc/hosts.allow", "/tmp/app.sock", "/var/lib/app/data/db", "/var/lib/app/lock", "/var/lib/old"}
+	if !reflect.DeepEqual(writes, expected) {
+		t.Fatalf("Expected writes %v, got %v", expected, writes)
+	}
+
+	advice := tmpfsAdvice(changes)
+	expected = []string{"/etc", "/tmp", "/var/lib"}
+	if !reflect.DeepEqual(advice, expected) {
+		t.Fatalf("Expected tmpfs advice %v, got %v", expected, advice)
+	}
+}
@@ -43,6 +43,24 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 
 	}
 	ioutils.FprintfIfNotEmpty(cli.out, "Execution Driver: %s\n", info.ExecutionDriver)
+	if len(info.SecurityOptions) != 0 {
+		fmt.Fprintf(cli.out, "Security Options:\n")
+		for _, o := range info.SecurityOptions {
+			fmt.Fprintf(cli.out, " %s\n", o)
+		}
+	}
+	if len(info.DefaultCapabilities) != 0 {
+		fmt.Fprintf(cli.out, "Default Capabilities: %s\n", strings.Join(info.DefaultCapabilities, " "))
+	}
+	if len(info.DefaultUlimits) != 0 {
+		fmt.Fprintf(cli.out, "Default Ulimits:\n")
+		for _, u := range info.DefaultUlimits {
+			fmt.Fprintf(cli.out, " %s\n", u)
+		}
+	}
+	if info.DefaultPidsLimit != 0 {
+		fmt.Fprintf(cli.out, "Default Pids Limit: %d\n", info.DefaultPidsLimit)
+	}
 	ioutils.FprintfIfNotEmpty(cli.out, "Logging Driver: %s\n", info.LoggingDriver)
 
 	fmt.Fprintf(cli.out, "Plugins: \n")
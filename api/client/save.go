@@ -7,6 +7,7 @@ import (
 
 	Cli "github.com/docker/docker/cli"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/engine-api/types"
 )
 
 // CmdSave saves one or more images to a tar archive.
@@ -17,6 +18,9 @@ import (
 func (cli *DockerCli) CmdSave(args ...string) error {
 	cmd := Cli.Subcmd("save", []string{"IMAGE [IMAGE...]"}, Cli.DockerCommands["save"].Description+" (streamed to STDOUT by default)", true)
 	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
+	format := cmd.String([]string{"-format"}, "docker", "Archive format (docker, oci)")
+	compression := cmd.String([]string{"-compression"}, "", "Compression for the layers in the archive (none, gzip, zstd)")
+	excludeBase := cmd.String([]string{"-exclude-base"}, "", "Leave out the layers of this base image")
 	cmd.Require(flag.Min, 1)
 
 	cmd.ParseFlags(args, true)
@@ -35,7 +39,14 @@ func (cli *DockerCli) CmdSave(args ...string) error {
 		}
 	}
 
-	responseBody, err := cli.client.ImageSave(cmd.Args())
+	options := types.ImageSaveOptions{
+		ImageIDs:    cmd.Args(),
+		Format:      *format,
+		Compression: *compression,
+		ExcludeBase: *excludeBase,
+	}
+
+	responseBody, err := cli.client.ImageSave(options)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"text/tabwriter"
+	"time"
+
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/stringid"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
+)
+
+// CmdSecret is the parent subcommand for all secret commands
+//
+// Usage: docker secret <COMMAND> <OPTS>
+func (cli *DockerCli) CmdSecret(args ...string) error {
+	description := Cli.DockerCommands["secret"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"create", "Create a secret"},
+		{"inspect", "Return low-level information on a secret"},
+		{"ls", "List secrets"},
+		{"rm", "Remove a secret"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker secret COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("secret", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdSecretCreate creates a secret with the content of a file, or of STDIN
+// if the file is "-".
+//
+// Usage: docker secret create [OPTIONS] NAME FILE|-
+func (cli *DockerCli) CmdSecretCreate(args ...string) error {
+	cmd := Cli.Subcmd("secret create", []string{"NAME FILE|-"}, "Create a secret from a file or STDIN", true)
+	flLabels := opts.NewListOpts(opts.ValidateLabel)
+	cmd.Var(&flLabels, []string{"-label"}, "Set metadata on the secret")
+
+	cmd.Require(flag.Exact, 2)
+	cmd.ParseFlags(args, true)
+
+	var (
+		data []byte
+		err  error
+	)
+	if file := cmd.Arg(1); file == "-" {
+		data, err = ioutil.ReadAll(cli.in)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	secretReq := types.SecretCreateRequest{
+		Name:   cmd.Arg(0),
+		Data:   data,
+		Labels: runconfigopts.ConvertKVStringsToMap(flLabels.GetAll()),
+	}
+	secret, err := cli.client.SecretCreate(secretReq)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "%s\n", secret.ID)
+	return nil
+}
+
+// CmdSecretLs outputs a list of the secrets of the daemon.
+//
+// Usage: docker secret ls [OPTIONS]
+func (cli *DockerCli) CmdSecretLs(args ...string) error {
+	cmd := Cli.Subcmd("secret ls", nil, "List secrets", true)
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display secret names")
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	secrets, err := cli.client.SecretList()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintf(w, "SECRET ID\tNAME\tCREATED")
+		fmt.Fprintf(w, "\n")
+	}
+
+	for _, secret := range secrets {
+		if *quiet {
+			fmt.Fprintln(w, secret.Name)
+			continue
+		}
+		created := units.HumanDuration(time.Now().UTC().Sub(time.Unix(secret.Created, 0))) + " ago"
+		fmt.Fprintf(w, "%s\t%s\t%s\n", stringid.TruncateID(secret.ID), secret.Name, created)
+	}
+	w.Flush()
+	return nil
+}
+
+// CmdSecretInspect displays low-level information on one or more secrets.
+// The values of the secrets are never returned by the daemon.
+//
+// Usage: docker secret inspect [OPTIONS] SECRET [SECRET...]
+func (cli *DockerCli) CmdSecretInspect(args ...string) error {
+	cmd := Cli.Subcmd("secret inspect", []string{"SECRET [SECRET...]"}, "Return low-level information on a secret", true)
+	tmplStr := cmd.String([]string{"f", "-format"}, "", "Format the output using the given go template")
+
+	cmd.Require(flag.Min, 1)
+	cmd.ParseFlags(args, true)
+
+	inspectSearcher := func(name string) (interface{}, []byte, error) {
+		i, err := cli.client.SecretInspect(name)
+		return i, nil, err
+	}
+
+	return cli.inspectElements(*tmplStr, cmd.Args(), inspectSearcher)
+}
+
+// CmdSecretRm removes one or more secrets.
+//
+// Usage: docker secret rm SECRET [SECRET...]
+func (cli *DockerCli) CmdSecretRm(args ...string) error {
+	cmd := Cli.Subcmd("secret rm", []string{"SECRET [SECRET...]"}, "Remove a secret", true)
+	cmd.Require(flag.Min, 1)
+	cmd.ParseFlags(args, true)
+
+	var status = 0
+
+	for _, name := range cmd.Args() {
+		if err := cli.client.SecretRemove(name); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(cli.out, "%s\n", name)
+	}
+
+	if status != 0 {
+		return Cli.StatusError{StatusCode: status}
+	}
+	return nil
+}
@@ -5,12 +5,14 @@ import (
 	"strings"
 
 	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/go-units"
 )
 
-// CmdUpdate updates resources of one or more containers.
+// CmdUpdate updates resources and the restart policy of one or more containers.
 //
 // Usage: docker update [OPTIONS] CONTAINER [CONTAINER...]
 func (cli *DockerCli) CmdUpdate(args ...string) error {
@@ -25,6 +27,16 @@ func (cli *DockerCli) CmdUpdate(args ...string) error {
 	flMemoryReservation := cmd.String([]string{"-memory-reservation"}, "", "Memory soft limit")
 	flMemorySwap := cmd.String([]string{"-memory-swap"}, "", "Total memory (memory + swap), '-1' to disable swap")
 	flKernelMemory := cmd.String([]string{"-kernel-memory"}, "", "Kernel memory limit")
+	flPidsLimit := cmd.Int64([]string{"-pids-limit"}, 0, "Tune container pids limit (set -1 for unlimited)")
+	flRestartPolicy := cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits")
+	flUlimits := runconfigopts.NewUlimitOpt(nil)
+	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options")
+	flNetEgressRate := opts.NewListOpts(runconfigopts.ValidateNetworkRate)
+	cmd.Var(&flNetEgressRate, []string{"-net-egress-rate"}, "Limit the egress rate (bytes per second) of an interface ([interface:]rate)")
+	flNetIngressRate := opts.NewListOpts(runconfigopts.ValidateNetworkRate)
+	cmd.Var(&flNetIngressRate, []string{"-net-ingress-rate"}, "Limit the ingress rate (bytes per second) of an interface ([interface:]rate)")
+	flDeviceCgroupRules := opts.NewListOpts(runconfigopts.ValidateDeviceCgroupRule)
+	cmd.Var(&flDeviceCgroupRules, []string{"-device-cgroup-rule"}, "Replace the rules of the cgroup allowed devices list")
 
 	cmd.Require(flag.Min, 1)
 	cmd.ParseFlags(args, true)
@@ -69,6 +81,16 @@ func (cli *DockerCli) CmdUpdate(args ...string) error {
 		}
 	}
 
+	restartPolicy, err := runconfigopts.ParseRestartPolicy(*flRestartPolicy)
+	if err != nil {
+		return err
+	}
+
+	networkRateLimits, err := runconfigopts.ParseNetworkRateLimits(flNetEgressRate.GetAll(), flNetIngressRate.GetAll())
+	if err != nil {
+		return err
+	}
+
 	resources := container.Resources{
 		BlkioWeight:       *flBlkioWeight,
 		CpusetCpus:        *flCpusetCpus,
@@ -80,10 +102,18 @@ func (cli *DockerCli) CmdUpdate(args ...string) error {
 		KernelMemory:      kernelMemory,
 		CPUPeriod:         *flCPUPeriod,
 		CPUQuota:          *flCPUQuota,
+		PidsLimit:         *flPidsLimit,
+		Ulimits:           flUlimits.GetList(),
+		NetworkRateLimits: networkRateLimits,
+	}
+	// the rules are only replaced if any is given
+	if flDeviceCgroupRules.Len() > 0 {
+		resources.DeviceCgroupRules = flDeviceCgroupRules.GetAll()
 	}
 
 	updateConfig := container.UpdateConfig{
-		Resources: resources,
+		Resources:     resources,
+		RestartPolicy: restartPolicy,
 	}
 
 	names := cmd.Args()
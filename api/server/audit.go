@@ -0,0 +1,377 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	syslog "github.com/RackSec/srslog"
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/docker/engine-api/types/strslice"
+	"github.com/docker/go-units"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// auditSyslog is the audit log destination of the syslog daemon
+	auditSyslog = "syslog"
+	// auditSyslogTag is the tag of the audit records sent to syslog
+	auditSyslogTag = "docker-audit"
+	// auditMaxBody is the size of the request and response bodies read for
+	// the audit records
+	auditMaxBody = 64 * 1024
+)
+
+// auditRouteClasses are the classes of routes which can be audited
+var auditRouteClasses = []string{"container", "exec", "image", "volume", "network", "secret", "system"}
+
+// auditRedactedParams are the query parameters which may hold secrets, and
+// are never written to the audit log
+var auditRedactedParams = map[string]bool{
+	"buildargs": true,
+}
+
+// auditRecord is the record of a mutating API request in the audit log
+type auditRecord struct {
+	Time       time.Time              `json:"time"`
+	User       string                 `json:"user,omitempty"`
+	AuthN      string                 `json:"authn,omitempty"`
+	RemoteAddr string                 `json:"remote_addr,omitempty"`
+	Method     string                 `json:"method"`
+	Route      string                 `json:"route"`
+	Class      string                 `json:"class"`
+	Container  string                 `json:"container,omitempty"`
+	Image      string                 `json:"image,omitempty"`
+	Exec       string                 `json:"exec,omitempty"`
+	Volume     string                 `json:"volume,omitempty"`
+	Network    string                 `json:"network,omitempty"`
+	Secret     string                 `json:"secret,omitempty"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	Status     int                    `json:"status"`
+}
+
+// auditor writes a record for each mutating API request of the audited
+// route classes
+type auditor struct {
+	mu      sync.Mutex
+	w       io.WriteCloser
+	classes map[string]bool
+}
+
+// newAuditor returns an auditor writing to the destination, either a file
+// or syslog, with the options of the destination.
+func newAuditor(dest string, opts map[string]string) (*auditor, error) {
+	a := &auditor{classes: make(map[string]bool)}
+	for _, class := range auditRouteClasses {
+		a.classes[class] = true
+	}
+	fileOpts := map[string]string{}
+	var syslogAddress string
+	for k, v := range opts {
+		switch k {
+		case "routes":
+			a.classes = make(map[string]bool)
+			for _, class := range strings.Split(v, ",") {
+				if !isAuditRouteClass(class) {
+					return nil, fmt.Errorf("invalid audit route class %q, expected one of %s", class, strings.Join(auditRouteClasses, ", "))
+				}
+				a.classes[class] = true
+			}
+		case "max-size", "max-file":
+			if dest == auditSyslog {
+				return nil, fmt.Errorf("audit log option %s is not supported with syslog", k)
+			}
+			fileOpts[k] = v
+		case "syslog-address":
+			if dest != auditSyslog {
+				return nil, fmt.Errorf("audit log option %s is only supported with syslog", k)
+			}
+			syslogAddress = v
+		default:
+			return nil, fmt.Errorf("unknown audit log option %s", k)
+		}
+	}
+
+	var err error
+	if dest == auditSyslog {
+		a.w, err = newAuditSyslogWriter(syslogAddress)
+	} else {
+		a.w, err = newAuditFileWriter(dest, fileOpts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the audit log: %v", err)
+	}
+	return a, nil
+}
+
+func isAuditRouteClass(class string) bool {
+	for _, c := range auditRouteClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// newAuditFileWriter opens the audit log file, rotated as the json-file
+// log driver with the max-size and max-file options.
+func newAuditFileWriter(path string, opts map[string]string) (io.WriteCloser, error) {
+	var capacity int64 = -1
+	if size, ok := opts["max-size"]; ok {
+		var err error
+		if capacity, err = units.FromHumanSize(size); err != nil {
+			return nil, err
+		}
+	}
+	maxFiles := 1
+	if files, ok := opts["max-file"]; ok {
+		var err error
+		if maxFiles, err = strconv.Atoi(files); err != nil {
+			return nil, err
+		}
+		if maxFiles < 1 {
+			return nil, fmt.Errorf("max-file cannot be less than 1")
+		}
+	}
+	return loggerutils.NewRotateFileWriter(path, capacity, maxFiles)
+}
+
+// newAuditSyslogWriter connects to the syslog daemon at address, such as
+// udp://host:514, or to the local syslog daemon if address is empty.
+func newAuditSyslogWriter(address string) (io.WriteCloser, error) {
+	var proto, addr string
+	if address != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		switch u.Scheme {
+		case "unix", "unixgram":
+			proto, addr = u.Scheme, u.Path
+		case "tcp", "udp":
+			proto, addr = u.Scheme, u.Host
+		default:
+			return nil, fmt.Errorf("unsupported syslog address %s", address)
+		}
+	}
+	return syslog.Dial(proto, addr, syslog.LOG_AUTHPRIV|syslog.LOG_INFO, auditSyslogTag)
+}
+
+// Close closes the audit log
+func (a *auditor) Close() error {
+	return a.w.Close()
+}
+
+// isAuditedMethod returns whether the requests with method change the state
+// of the daemon
+func isAuditedMethod(method string) bool {
+	return method == "POST" || method == "PUT" || method == "DELETE"
+}
+
+// auditRouteClass returns the class of the route with the path template
+func auditRouteClass(route string) string {
+	switch {
+	case route == "/containers/{name:.*}/exec", strings.HasPrefix(route, "/exec/"):
+		return "exec"
+	case strings.HasPrefix(route, "/containers/"):
+		return "container"
+	case strings.HasPrefix(route, "/images/"), route == "/build", route == "/commit":
+		return "image"
+	case strings.HasPrefix(route, "/volumes/"):
+		return "volume"
+	case strings.HasPrefix(route, "/networks/"):
+		return "network"
+	case strings.HasPrefix(route, "/secrets/"):
+		return "secret"
+	default:
+		return "system"
+	}
+}
+
+// auditHandler writes a record for each request of the route with the
+// method and path template served by handler, if the route is audited.
+func (a *auditor) auditHandler(method, route string, handler http.HandlerFunc) http.HandlerFunc {
+	class := auditRouteClass(route)
+	if !isAuditedMethod(method) || !a.classes[class] {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &auditRecord{
+			Time:       time.Now().UTC(),
+			RemoteAddr: r.RemoteAddr,
+			Method:     method,
+			Route:      route,
+			Class:      class,
+		}
+		if cert := authorization.NewCertificate(r.TLS); cert != nil {
+			rec.User = cert.CommonName
+			rec.AuthN = authorization.TLSAuthNMethod
+		}
+		rec.setResources(route, mux.Vars(r))
+		rec.setParams(route, r)
+
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		if createsResource(route) {
+			sr.body = &bytes.Buffer{}
+		}
+		handler(sr, r)
+		rec.Status = sr.status
+		if sr.body != nil {
+			rec.setCreatedID(route, sr.body.Bytes())
+		}
+		a.write(rec)
+	}
+}
+
+// write writes a record to the audit log as a line of JSON
+func (a *auditor) write(rec *auditRecord) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		logrus.Errorf("Error marshalling the audit record of %s %s: %v", rec.Method, rec.Route, err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(b, '\n')); err != nil {
+		logrus.Errorf("Error writing the audit record of %s %s: %v", rec.Method, rec.Route, err)
+	}
+}
+
+// setResources sets the container, image, exec, volume or network of the
+// request from the route variables
+func (rec *auditRecord) setResources(route string, vars map[string]string) {
+	switch rec.Class {
+	case "container":
+		rec.Container = vars["name"]
+	case "exec":
+		if strings.HasPrefix(route, "/exec/") {
+			rec.Exec = vars["name"]
+		} else {
+			rec.Container = vars["name"]
+		}
+	case "image":
+		rec.Image = vars["name"]
+	case "volume":
+		rec.Volume = vars["name"]
+	case "network":
+		rec.Network = vars["id"]
+	case "secret":
+		rec.Secret = vars["name"]
+	}
+}
+
+// setParams sets the parameters of the request from its query, and from
+// its body for the creation of containers and execs. Only the parameters
+// relevant to the security of the daemon are read from the body, so that
+// the environment variables and other secrets are not logged.
+func (rec *auditRecord) setParams(route string, r *http.Request) {
+	params := make(map[string]interface{})
+	for k, v := range r.URL.Query() {
+		if auditRedactedParams[k] {
+			continue
+		}
+		if len(v) == 1 {
+			params[k] = v[0]
+		} else {
+			params[k] = v
+		}
+	}
+
+	var body interface{}
+	switch route {
+	case "/containers/create":
+		body = &auditContainerParams{}
+	case "/containers/{name:.*}/exec":
+		body = &auditExecParams{}
+	}
+	if body != nil && r.Body != nil {
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, auditMaxBody))
+		if err == nil {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+			if err := json.Unmarshal(b, body); err == nil {
+				b, _ := json.Marshal(body)
+				json.Unmarshal(b, &params)
+			}
+		}
+	}
+
+	if len(params) > 0 {
+		rec.Params = params
+	}
+}
+
+// auditContainerParams are the parameters of the creation of a container
+// written to the audit log
+type auditContainerParams struct {
+	Image      string             `json:",omitempty"`
+	Cmd        *strslice.StrSlice `json:",omitempty"`
+	Entrypoint *strslice.StrSlice `json:",omitempty"`
+	User       string             `json:",omitempty"`
+	HostConfig *struct {
+		Privileged  bool     `json:",omitempty"`
+		CapAdd      []string `json:",omitempty"`
+		SecurityOpt []string `json:",omitempty"`
+		Binds       []string `json:",omitempty"`
+		NetworkMode string   `json:",omitempty"`
+		PidMode     string   `json:",omitempty"`
+		IpcMode     string   `json:",omitempty"`
+		UsernsMode  string   `json:",omitempty"`
+		Secrets     []struct {
+			Name   string
+			Target string `json:",omitempty"`
+		} `json:",omitempty"`
+	} `json:",omitempty"`
+}
+
+// auditExecParams are the parameters of the creation of an exec written to
+// the audit log
+type auditExecParams struct {
+	Cmd        []string `json:",omitempty"`
+	User       string   `json:",omitempty"`
+	Privileged bool     `json:",omitempty"`
+	Tty        bool     `json:",omitempty"`
+}
+
+// createsResource returns whether the requests of the route create a
+// container, exec, image or secret whose ID is returned in the response
+func createsResource(route string) bool {
+	switch route {
+	case "/containers/create", "/containers/{name:.*}/exec", "/commit", "/secrets/create":
+		return true
+	}
+	return false
+}
+
+// setCreatedID sets the ID of the container, exec, image or secret a
+// request created from the response body
+func (rec *auditRecord) setCreatedID(route string, body []byte) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if rec.Status >= http.StatusBadRequest || json.Unmarshal(body, &created) != nil || created.ID == "" {
+		return
+	}
+	switch route {
+	case "/containers/create":
+		rec.Container = created.ID
+	case "/containers/{name:.*}/exec":
+		rec.Exec = created.ID
+	case "/commit":
+		rec.Image = created.ID
+	case "/secrets/create":
+		rec.Secret = created.ID
+	}
+}
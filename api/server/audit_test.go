@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestAuditor(t *testing.T, opts map[string]string) (*auditor, string) {
+	dir, err := ioutil.TempDir("", "audit-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "audit.log")
+	a, err := newAuditor(path, opts)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return a, path
+}
+
+func readAuditRecords(t *testing.T, path string) []map[string]interface{} {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestNewAuditorInvalidOpts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	for _, tc := range []struct {
+		dest string
+		opts map[string]string
+	}{
+		{path, map[string]string{"routes": "container,unknown"}},
+		{path, map[string]string{"max-size": "big"}},
+		{path, map[string]string{"max-file": "0"}},
+		{path, map[string]string{"syslog-address": "udp://localhost:514"}},
+		{path, map[string]string{"unknown": "value"}},
+		{auditSyslog, map[string]string{"max-size": "10m"}},
+		{auditSyslog, map[string]string{"syslog-address": "http://localhost"}},
+	} {
+		if _, err := newAuditor(tc.dest, tc.opts); err == nil {
+			t.Fatalf("Expected an error for the audit log %s with options %v", tc.dest, tc.opts)
+		}
+	}
+}
+
+func TestAuditContainerCreate(t *testing.T) {
+	a, path := newTestAuditor(t, nil)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer a.Close()
+
+	var body []byte
+	h := a.auditHandler("POST", "/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abcdef", "Warnings": null}`))
+	})
+	reqBody := `{"Image": "busybox", "Cmd": "sh", "Env": ["PASSWORD=secret"], "HostConfig": {"Privileged": true}}`
+	req, _ := http.NewRequest("POST", "/v1.22/containers/create?name=web", strings.NewReader(reqBody))
+	req.Header.Set("X-Registry-Auth", "secret")
+	req.RemoteAddr = "10.0.0.1:1234"
+	h(httptest.NewRecorder(), req)
+
+	if string(body) != reqBody {
+		t.Fatalf("Expected the handler to read the request body, got %s", body)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Fatalf("Expected the audit log not to hold secrets, got %s", b)
+	}
+
+	records := readAuditRecords(t, path)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	rec := records[0]
+	if rec["container"] != "abcdef" || rec["class"] != "container" || rec["status"] != float64(http.StatusCreated) || rec["remote_addr"] != "10.0.0.1:1234" {
+		t.Fatalf("Unexpected audit record %v", rec)
+	}
+	params := rec["params"].(map[string]interface{})
+	hostConfig := params["HostConfig"].(map[string]interface{})
+	if params["name"] != "web" || params["Image"] != "busybox" || hostConfig["Privileged"] != true {
+		t.Fatalf("Unexpected audit record params %v", params)
+	}
+	if _, ok := params["Env"]; ok {
+		t.Fatalf("Expected the environment not to be logged, got %v", params)
+	}
+}
+
+func TestAuditRouteClasses(t *testing.T) {
+	a, path := newTestAuditor(t, map[string]string{"routes": "exec,image"})
+	defer os.RemoveAll(filepath.Dir(path))
+	defer a.Close()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	for _, tc := range []struct {
+		method, route, uri string
+	}{
+		{"POST", "/containers/{name:.*}/exec", "/containers/web/exec"},
+		{"POST", "/exec/{name:.*}/start", "/exec/123/start"},
+		{"DELETE", "/images/{name:.*}", "/images/busybox"},
+		{"POST", "/build", "/build?t=web&buildargs=%7B%22TOKEN%22%3A%22secret%22%7D"},
+		{"GET", "/images/{name:.*}/json", "/images/busybox/json"},
+		{"POST", "/containers/{name:.*}/start", "/containers/web/start"},
+		{"DELETE", "/volumes/{name:.*}", "/volumes/data"},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.uri, strings.NewReader(`{"Cmd": ["id"], "Privileged": true}`))
+		a.auditHandler(tc.method, tc.route, handler)(httptest.NewRecorder(), req)
+	}
+
+	records := readAuditRecords(t, path)
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 audit records, got %v", records)
+	}
+	for i, class := range []string{"exec", "exec", "image", "image"} {
+		if records[i]["class"] != class {
+			t.Fatalf("Expected the audit record %d of class %s, got %v", i, class, records[i])
+		}
+	}
+	if params := records[0]["params"].(map[string]interface{}); params["Privileged"] != true {
+		t.Fatalf("Expected the exec parameters to be logged, got %v", params)
+	}
+	if params := records[3]["params"].(map[string]interface{}); params["t"] != "web" || params["buildargs"] != nil {
+		t.Fatalf("Expected the build arguments not to be logged, got %v", params)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/metrics"
+	"github.com/docker/go-connections/sockets"
+)
+
+// apiRequests counts the API requests by route, method and status code.
+// The route is the path template of the route, so that requests for
+// different containers, images, etc. are counted together.
+var apiRequests = metrics.NewCounter(
+	"engine_daemon_api_requests_total",
+	"The number of API requests served by route, method and status code",
+	"route", "method", "code",
+)
+
+func init() {
+	metrics.MustRegister(apiRequests)
+}
+
+// newMetricsServer sets up the HTTPServer that exposes the daemon metrics in
+// the Prometheus text format on addr.
+func newMetricsServer(addr string) (*HTTPServer, error) {
+	l, err := sockets.NewTCPSocket(addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := http.NewServeMux()
+	m.Handle("/metrics", metrics.Handler())
+	return &HTTPServer{
+		&http.Server{
+			Addr:    addr,
+			Handler: m,
+		},
+		l,
+	}, nil
+}
+
+// serveMetrics serves the metrics until the metrics server is closed.
+func (s *Server) serveMetrics() {
+	logrus.Infof("Metrics listen on %s", s.metricsServer.l.Addr())
+	if err := s.metricsServer.Serve(); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+		logrus.Errorf("Error serving metrics: %v", err)
+	}
+}
+
+// instrumentHandler counts the requests served by handler for the route
+// with the method and path template.
+func instrumentHandler(method, route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(rec, r)
+		apiRequests.Inc(route, method, strconv.Itoa(rec.status))
+	}
+}
+
+// statusRecorder records the status code of a response. It forwards the
+// optional interfaces of the ResponseWriter the handlers rely on, such as
+// hijacking the connection for attach. If body is set, the beginning of the
+// response body is recorded as well.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	body   *bytes.Buffer
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.body != nil && r.body.Len() < auditMaxBody {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) CloseNotify() <-chan bool {
+	if cn, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return nil
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Internal response writer doesn't support the Hijacker interface")
+	}
+	return h.Hijack()
+}
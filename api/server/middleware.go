@@ -38,6 +38,9 @@ func debugRequestMiddleware(handler httputils.APIFunc) httputils.APIFunc {
 						if _, exists := postForm["password"]; exists {
 							postForm["password"] = "*****"
 						}
+						if _, exists := postForm["Data"]; exists && strings.HasSuffix(r.URL.Path, "/secrets/create") {
+							postForm["Data"] = "*****"
+						}
 						formStr, errMarshal := json.Marshal(postForm)
 						if errMarshal == nil {
 							logrus.Debugf("form data: %s", string(formStr))
@@ -61,7 +64,16 @@ func (s *Server) authorizationMiddleware(handler httputils.APIFunc) httputils.AP
 		// Currently tracked in https://github.com/docker/docker/pull/13994
 		user := ""
 		userAuthNMethod := ""
+
+		// Until then, the user of a connection with a verified TLS client
+		// certificate is the common name of the certificate subject
+		cert := authorization.NewCertificate(r.TLS)
+		if cert != nil {
+			user = cert.CommonName
+			userAuthNMethod = authorization.TLSAuthNMethod
+		}
 		authCtx := authorization.NewCtx(s.authZPlugins, user, userAuthNMethod, r.Method, r.RequestURI)
+		authCtx.SetUserCertificate(cert)
 
 		if err := authCtx.AuthZRequest(w, r); err != nil {
 			logrus.Errorf("AuthZRequest for %s %s returned error: %s", r.Method, r.RequestURI, err)
@@ -169,8 +181,13 @@ func (s *Server) handleWithGlobalMiddlewares(handler httputils.APIFunc) httputil
 		middlewares = append(middlewares, debugRequestMiddleware)
 	}
 
-	if len(s.cfg.AuthZPluginNames) > 0 {
-		s.authZPlugins = authorization.NewPlugins(s.cfg.AuthZPluginNames)
+	if s.authZPolicy != nil || len(s.cfg.AuthZPluginNames) > 0 {
+		var plugins []authorization.Plugin
+		if s.authZPolicy != nil {
+			// the built-in plugin evaluates the requests first
+			plugins = append(plugins, s.authZPolicy)
+		}
+		s.authZPlugins = append(plugins, authorization.NewPlugins(s.cfg.AuthZPluginNames)...)
 		middlewares = append(middlewares, s.authorizationMiddleware)
 	}
 
@@ -0,0 +1,11 @@
+package checkpoint
+
+import "github.com/docker/engine-api/types"
+
+// Backend is the methods that need to be implemented to provide
+// checkpoint specific functionality
+type Backend interface {
+	CheckpointCreate(container string, config types.CheckpointCreateOptions) error
+	CheckpointDelete(container string, checkpointID string) error
+	CheckpointList(container string) ([]types.Checkpoint, error)
+}
@@ -0,0 +1,37 @@
+package checkpoint
+
+import (
+	"github.com/docker/docker/api/server/router"
+	"github.com/docker/docker/api/server/router/local"
+)
+
+// checkpointRouter is a router to talk with the checkpoint controller
+type checkpointRouter struct {
+	backend Backend
+	routes  []router.Route
+}
+
+// NewRouter initializes a new checkpointRouter
+func NewRouter(b Backend) router.Router {
+	r := &checkpointRouter{
+		backend: b,
+	}
+	r.initRoutes()
+	return r
+}
+
+// Routes returns the available routers to the checkpoint controller
+func (r *checkpointRouter) Routes() []router.Route {
+	return r.routes
+}
+
+func (r *checkpointRouter) initRoutes() {
+	r.routes = []router.Route{
+		// GET
+		local.NewGetRoute("/containers/{name:.*}/checkpoints", r.getContainerCheckpoints),
+		// POST
+		local.NewPostRoute("/containers/{name:.*}/checkpoints", r.postContainerCheckpoint),
+		// DELETE
+		local.NewDeleteRoute("/containers/{name:.*}/checkpoints/{checkpoint}", r.deleteContainerCheckpoint),
+	}
+}
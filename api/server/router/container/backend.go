@@ -33,14 +33,15 @@ type copyBackend interface {
 // stateBackend includes functions to implement to provide container state lifecycle functionality.
 type stateBackend interface {
 	ContainerCreate(types.ContainerCreateConfig) (types.ContainerCreateResponse, error)
+	ContainerDevicesUpdate(name string, config *container.DevicesUpdateConfig) error
 	ContainerKill(name string, sig uint64) error
 	ContainerPause(name string) error
 	ContainerRename(oldName, newName string) error
 	ContainerResize(name string, height, width int) error
-	ContainerRestart(name string, seconds int) error
+	ContainerRestart(name string, seconds *int) error
 	ContainerRm(name string, config *types.ContainerRmConfig) error
-	ContainerStart(name string, hostConfig *container.HostConfig) error
-	ContainerStop(name string, seconds int) error
+	ContainerStart(name string, hostConfig *container.HostConfig, checkpoint string) error
+	ContainerStop(name string, seconds *int) error
 	ContainerUnpause(name string) error
 	ContainerUpdate(name string, hostConfig *container.HostConfig) ([]string, error)
 	ContainerWait(name string, timeout time.Duration) (int, error)
@@ -53,6 +54,7 @@ type monitorBackend interface {
 	ContainerInspect(name string, size bool, version version.Version) (interface{}, error)
 	ContainerLogs(name string, config *daemon.ContainerLogsConfig) error
 	ContainerStats(name string, config *daemon.ContainerStatsConfig) error
+	ContainerStatsHistory(name string) (*types.ContainerStatsHistory, error)
 	ContainerTop(name string, psArgs string) (*types.ContainerProcessList, error)
 
 	Containers(config *daemon.ContainersConfig) ([]*types.Container, error)
@@ -38,6 +38,7 @@ func (r *containerRouter) initRoutes() {
 		local.NewGetRoute("/containers/{name:.*}/top", r.getContainersTop),
 		local.NewGetRoute("/containers/{name:.*}/logs", r.getContainersLogs),
 		local.NewGetRoute("/containers/{name:.*}/stats", r.getContainersStats),
+		local.NewGetRoute("/containers/{name:.*}/stats/history", r.getContainersStatsHistory),
 		local.NewGetRoute("/containers/{name:.*}/attach/ws", r.wsContainersAttach),
 		local.NewGetRoute("/exec/{id:.*}/json", r.getExecByID),
 		local.NewGetRoute("/containers/{name:.*}/archive", r.getContainersArchive),
@@ -58,6 +59,7 @@ func (r *containerRouter) initRoutes() {
 		local.NewPostRoute("/exec/{name:.*}/resize", r.postContainerExecResize),
 		local.NewPostRoute("/containers/{name:.*}/rename", r.postContainerRename),
 		local.NewPostRoute("/containers/{name:.*}/update", r.postContainerUpdate),
+		local.NewPostRoute("/containers/{name:.*}/devices", r.postContainerDevices),
 		// PUT
 		local.NewPutRoute("/containers/{name:.*}/archive", r.putContainersArchive),
 		// DELETE
@@ -87,6 +87,15 @@ func (s *containerRouter) getContainersStats(ctx context.Context, w http.Respons
 	return s.backend.ContainerStats(vars["name"], config)
 }
 
+func (s *containerRouter) getContainersStatsHistory(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	history, err := s.backend.ContainerStatsHistory(vars["name"])
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, history)
+}
+
 func (s *containerRouter) getContainersLogs(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -165,6 +174,10 @@ func (s *containerRouter) postContainersStart(ctx context.Context, w http.Respon
 	// net/http otherwise seems to swallow any headers related to chunked encoding
 	// including r.TransferEncoding
 	// allow a nil body for backwards compatibility
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
 	var hostConfig *container.HostConfig
 	if r.Body != nil && (r.ContentLength > 0 || r.ContentLength == -1) {
 		if err := httputils.CheckForJSON(r); err != nil {
@@ -179,21 +192,29 @@ func (s *containerRouter) postContainersStart(ctx context.Context, w http.Respon
 		hostConfig = c
 	}
 
-	if err := s.backend.ContainerStart(vars["name"], hostConfig); err != nil {
+	if err := s.backend.ContainerStart(vars["name"], hostConfig, r.Form.Get("checkpoint")); err != nil {
 		return err
 	}
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
+// stopTimeout returns the "t" parameter of a stop or restart request, or nil
+// if it is not set, to use the stop timeout of the container.
+func stopTimeout(r *http.Request) *int {
+	if r.Form.Get("t") == "" {
+		return nil
+	}
+	seconds, _ := strconv.Atoi(r.Form.Get("t"))
+	return &seconds
+}
+
 func (s *containerRouter) postContainersStop(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
 	}
 
-	seconds, _ := strconv.Atoi(r.Form.Get("t"))
-
-	if err := s.backend.ContainerStop(vars["name"], seconds); err != nil {
+	if err := s.backend.ContainerStop(vars["name"], stopTimeout(r)); err != nil {
 		return err
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -239,9 +260,7 @@ func (s *containerRouter) postContainersRestart(ctx context.Context, w http.Resp
 		return err
 	}
 
-	timeout, _ := strconv.Atoi(r.Form.Get("t"))
-
-	if err := s.backend.ContainerRestart(vars["name"], timeout); err != nil {
+	if err := s.backend.ContainerRestart(vars["name"], stopTimeout(r)); err != nil {
 		return err
 	}
 
@@ -341,7 +360,8 @@ func (s *containerRouter) postContainerUpdate(ctx context.Context, w http.Respon
 	}
 
 	hostConfig := &container.HostConfig{
-		Resources: updateConfig.Resources,
+		Resources:     updateConfig.Resources,
+		RestartPolicy: updateConfig.RestartPolicy,
 	}
 
 	name := vars["name"]
@@ -355,6 +375,27 @@ func (s *containerRouter) postContainerUpdate(ctx context.Context, w http.Respon
 	})
 }
 
+func (s *containerRouter) postContainerDevices(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if err := httputils.CheckForJSON(r); err != nil {
+		return err
+	}
+
+	var devicesConfig container.DevicesUpdateConfig
+	if err := json.NewDecoder(r.Body).Decode(&devicesConfig); err != nil {
+		return err
+	}
+
+	if err := s.backend.ContainerDevicesUpdate(vars["name"], &devicesConfig); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *containerRouter) postContainersCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
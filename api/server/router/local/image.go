@@ -13,6 +13,8 @@ import (
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/builder/dockerfile"
 	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/reference"
@@ -222,12 +224,19 @@ func (s *router) postImagesPush(ctx context.Context, w http.ResponseWriter, r *h
 		}
 	}
 
+	layerCompression := archive.Gzip
+	if c := r.Form.Get("compression"); c != "" {
+		if layerCompression, err = archive.ParseCompression(c); err != nil {
+			return err
+		}
+	}
+
 	output := ioutils.NewWriteFlusher(w)
 	defer output.Close()
 
 	w.Header().Set("Content-Type", "application/json")
 
-	if err := s.daemon.PushImage(ref, metaHeaders, authConfig, output); err != nil {
+	if err := s.daemon.PushImage(ref, metaHeaders, authConfig, layerCompression, output); err != nil {
 		if !output.Flushed() {
 			return err
 		}
@@ -242,6 +251,16 @@ func (s *router) getImagesGet(ctx context.Context, w http.ResponseWriter, r *htt
 		return err
 	}
 
+	layerCompression, err := archive.ParseCompression(r.Form.Get("compression"))
+	if err != nil {
+		return err
+	}
+	options := image.ExportOptions{
+		Format:           r.Form.Get("format"),
+		LayerCompression: layerCompression,
+		ExcludeBase:      r.Form.Get("excludebase"),
+	}
+
 	w.Header().Set("Content-Type", "application/x-tar")
 
 	output := ioutils.NewWriteFlusher(w)
@@ -253,7 +272,7 @@ func (s *router) getImagesGet(ctx context.Context, w http.ResponseWriter, r *htt
 		names = r.Form["names"]
 	}
 
-	if err := s.daemon.ExportImage(names, output); err != nil {
+	if err := s.daemon.ExportImage(names, options, output); err != nil {
 		if !output.Flushed() {
 			return err
 		}
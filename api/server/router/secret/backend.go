@@ -0,0 +1,12 @@
+package secret
+
+import "github.com/docker/engine-api/types"
+
+// Backend is the methods that need to be implemented to provide
+// secret specific functionality
+type Backend interface {
+	SecretCreate(req types.SecretCreateRequest) (string, error)
+	SecretList() []types.Secret
+	SecretInspect(name string) (*types.Secret, error)
+	SecretRemove(name string) error
+}
@@ -0,0 +1,38 @@
+package secret
+
+import (
+	"github.com/docker/docker/api/server/router"
+	"github.com/docker/docker/api/server/router/local"
+)
+
+// secretRouter is a router to talk with the secret controller
+type secretRouter struct {
+	backend Backend
+	routes  []router.Route
+}
+
+// NewRouter initializes a new secretRouter
+func NewRouter(b Backend) router.Router {
+	r := &secretRouter{
+		backend: b,
+	}
+	r.initRoutes()
+	return r
+}
+
+// Routes returns the available routers to the secret controller
+func (r *secretRouter) Routes() []router.Route {
+	return r.routes
+}
+
+func (r *secretRouter) initRoutes() {
+	r.routes = []router.Route{
+		// GET
+		local.NewGetRoute("/secrets", r.getSecretsList),
+		local.NewGetRoute("/secrets/{name:.*}", r.getSecretByName),
+		// POST
+		local.NewPostRoute("/secrets/create", r.postSecretsCreate),
+		// DELETE
+		local.NewDeleteRoute("/secrets/{name:.*}", r.deleteSecrets),
+	}
+}
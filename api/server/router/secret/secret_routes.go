@@ -0,0 +1,61 @@
+package secret
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+func (r *secretRouter) getSecretsList(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, r.backend.SecretList())
+}
+
+func (r *secretRouter) getSecretByName(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+
+	secret, err := r.backend.SecretInspect(vars["name"])
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, secret)
+}
+
+func (r *secretRouter) postSecretsCreate(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+
+	if err := httputils.CheckForJSON(req); err != nil {
+		return err
+	}
+
+	var config types.SecretCreateRequest
+	if err := json.NewDecoder(req.Body).Decode(&config); err != nil {
+		return err
+	}
+
+	id, err := r.backend.SecretCreate(config)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusCreated, &types.SecretCreateResponse{ID: id})
+}
+
+func (r *secretRouter) deleteSecrets(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+	if err := r.backend.SecretRemove(vars["name"]); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
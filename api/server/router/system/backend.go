@@ -14,4 +14,7 @@ type Backend interface {
 	SubscribeToEvents(since, sinceNano int64, ef filters.Args) ([]events.Message, chan interface{})
 	UnsubscribeFromEvents(chan interface{})
 	AuthenticateToRegistry(authConfig *types.AuthConfig) (string, error)
+	ReloadSecurityProfiles() error
+	ReloadAuthZPolicy() error
+	ReloadAdmissionPolicy() error
 }
@@ -26,6 +26,9 @@ func NewRouter(b Backend) router.Router {
 		local.NewGetRoute("/info", r.getInfo),
 		local.NewGetRoute("/version", r.getVersion),
 		local.NewPostRoute("/auth", r.postAuth),
+		local.NewPostRoute("/security/reload", r.postSecurityReload),
+		local.NewPostRoute("/authz/reload", r.postAuthZReload),
+		local.NewPostRoute("/admission/reload", r.postAdmissionReload),
 	}
 
 	return r
@@ -130,3 +130,27 @@ func (s *systemRouter) postAuth(ctx context.Context, w http.ResponseWriter, r *h
 		Status: status,
 	})
 }
+
+func (s *systemRouter) postSecurityReload(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := s.backend.ReloadSecurityProfiles(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *systemRouter) postAuthZReload(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := s.backend.ReloadAuthZPolicy(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *systemRouter) postAdmissionReload(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := s.backend.ReloadAdmissionPolicy(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
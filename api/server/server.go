@@ -11,15 +11,18 @@ import (
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/router"
 	"github.com/docker/docker/api/server/router/build"
+	"github.com/docker/docker/api/server/router/checkpoint"
 	"github.com/docker/docker/api/server/router/container"
 	"github.com/docker/docker/api/server/router/local"
 	"github.com/docker/docker/api/server/router/network"
+	"github.com/docker/docker/api/server/router/secret"
 	"github.com/docker/docker/api/server/router/system"
 	"github.com/docker/docker/api/server/router/volume"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/utils"
 	"github.com/docker/go-connections/sockets"
+	"github.com/docker/go-connections/tlsconfig"
 	"github.com/gorilla/mux"
 	"golang.org/x/net/context"
 )
@@ -37,15 +40,23 @@ type Config struct {
 	Version          string
 	SocketGroup      string
 	TLSConfig        *tls.Config
+	TLSOptions       *tlsconfig.Options
 	Addrs            []Addr
+	MetricsAddr      string
+	AuditLog         string
+	AuditLogOpts     map[string]string
 }
 
 // Server contains instance details for the server
 type Server struct {
-	cfg          *Config
-	servers      []*HTTPServer
-	routers      []router.Router
-	authZPlugins []authorization.Plugin
+	cfg           *Config
+	servers       []*HTTPServer
+	metricsServer *HTTPServer
+	routers       []router.Router
+	authZPlugins  []authorization.Plugin
+	authZPolicy   authorization.Plugin
+	audit         *auditor
+	tls           *tlsReloader
 }
 
 // Addr contains string representation of address and its protocol (tcp, unix...).
@@ -60,6 +71,13 @@ func New(cfg *Config) (*Server, error) {
 	s := &Server{
 		cfg: cfg,
 	}
+	if cfg.TLSConfig != nil && cfg.TLSOptions != nil {
+		r, err := newTLSReloader(cfg.TLSConfig, *cfg.TLSOptions)
+		if err != nil {
+			return nil, err
+		}
+		s.tls = r
+	}
 	for _, addr := range cfg.Addrs {
 		srv, err := s.newServer(addr.Proto, addr.Addr)
 		if err != nil {
@@ -68,6 +86,20 @@ func New(cfg *Config) (*Server, error) {
 		logrus.Debugf("Server created for HTTP on %s (%s)", addr.Proto, addr.Addr)
 		s.servers = append(s.servers, srv...)
 	}
+	if cfg.MetricsAddr != "" {
+		srv, err := newMetricsServer(cfg.MetricsAddr)
+		if err != nil {
+			return nil, err
+		}
+		s.metricsServer = srv
+	}
+	if cfg.AuditLog != "" {
+		a, err := newAuditor(cfg.AuditLog, cfg.AuditLogOpts)
+		if err != nil {
+			return nil, err
+		}
+		s.audit = a
+	}
 	return s, nil
 }
 
@@ -78,11 +110,31 @@ func (s *Server) Close() {
 			logrus.Error(err)
 		}
 	}
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Close(); err != nil {
+			logrus.Error(err)
+		}
+	}
+	if s.audit != nil {
+		if err := s.audit.Close(); err != nil {
+			logrus.Error(err)
+		}
+	}
+	if s.tls != nil {
+		if err := s.tls.Close(); err != nil {
+			logrus.Error(err)
+		}
+	}
 }
 
 // ServeAPI loops through all initialized servers and spawns goroutine
 // with Server method for each. It sets CreateMux() as Handler also.
+// The metrics server, if any, is served alongside.
 func (s *Server) ServeAPI() error {
+	if s.metricsServer != nil {
+		go s.serveMetrics()
+	}
+
 	var chErrors = make(chan error, len(s.servers))
 	for _, srv := range s.servers {
 		srv.srv.Handler = s.CreateMux()
@@ -135,15 +187,28 @@ func (s *Server) initTCPSocket(addr string) (l net.Listener, err error) {
 	if s.cfg.TLSConfig == nil || s.cfg.TLSConfig.ClientAuth != tls.RequireAndVerifyClientCert {
 		logrus.Warn("/!\\ DON'T BIND ON ANY IP ADDRESS WITHOUT setting -tlsverify IF YOU DON'T KNOW WHAT YOU'RE DOING /!\\")
 	}
-	if l, err = sockets.NewTCPSocket(addr, s.cfg.TLSConfig); err != nil {
+	if l, err = sockets.NewTCPSocket(addr, s.listenerTLSConfig()); err != nil {
 		return nil, err
 	}
 	if err := allocateDaemonPort(addr); err != nil {
 		return nil, err
 	}
+	if s.tls != nil {
+		l = s.tls.newListener(l)
+	}
 	return
 }
 
+// listenerTLSConfig returns the TLS configuration the listeners are created
+// with, or nil if the TLS files are watched, in which case the TCP listeners
+// are wrapped by the reloader instead.
+func (s *Server) listenerTLSConfig() *tls.Config {
+	if s.tls != nil {
+		return nil
+	}
+	return s.cfg.TLSConfig
+}
+
 func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// log the handler call
@@ -173,14 +238,32 @@ func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 
 // InitRouters initializes a list of routers for the server.
 func (s *Server) InitRouters(d *daemon.Daemon) {
+	// checkpoint routes are added first, as DELETE /containers/{name:.*}
+	// would match the removal of a checkpoint
+	s.addRouter(checkpoint.NewRouter(d))
 	s.addRouter(container.NewRouter(d))
 	s.addRouter(local.NewRouter(d))
 	s.addRouter(network.NewRouter(d))
+	s.addRouter(secret.NewRouter(d))
 	s.addRouter(system.NewRouter(d))
 	s.addRouter(volume.NewRouter(d))
 	s.addRouter(build.NewRouter(d))
 }
 
+// SetAuthZPolicy sets the built-in authorization plugin, which authorizes the
+// requests before the plugins of the configuration.
+func (s *Server) SetAuthZPolicy(p authorization.Plugin) {
+	s.authZPolicy = p
+}
+
+// SetTLSReloadHook sets the function called with the subject and expiry of
+// the new certificate each time the TLS files of the API are reloaded.
+func (s *Server) SetTLSReloadHook(hook func(attributes map[string]string)) {
+	if s.tls != nil {
+		s.tls.setHook(hook)
+	}
+}
+
 // addRouter adds a new router to the server.
 func (s *Server) addRouter(r router.Router) {
 	s.routers = append(s.routers, r)
@@ -197,7 +280,10 @@ func (s *Server) CreateMux() *mux.Router {
 	logrus.Debugf("Registering routers")
 	for _, apiRouter := range s.routers {
 		for _, r := range apiRouter.Routes() {
-			f := s.makeHTTPHandler(r.Handler())
+			f := instrumentHandler(r.Method(), r.Path(), s.makeHTTPHandler(r.Handler()))
+			if s.audit != nil {
+				f = s.audit.auditHandler(r.Method(), r.Path(), f)
+			}
 
 			logrus.Debugf("Registering %s, %s", r.Method(), r.Path())
 			m.Path(versionMatcher + r.Path()).Methods(r.Method()).Handler(f)
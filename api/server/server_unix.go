@@ -25,10 +25,18 @@ func (s *Server) newServer(proto, addr string) ([]*HTTPServer, error) {
 	)
 	switch proto {
 	case "fd":
-		ls, err = listenFD(addr, s.cfg.TLSConfig)
+		ls, err = listenFD(addr, s.listenerTLSConfig())
 		if err != nil {
 			return nil, err
 		}
+		if s.tls != nil {
+			for i, l := range ls {
+				// Activate TLS only for TCP sockets
+				if l.Addr().Network() == "tcp" {
+					ls[i] = s.tls.newListener(l)
+				}
+			}
+		}
 	case "tcp":
 		l, err := s.initTCPSocket(addr)
 		if err != nil {
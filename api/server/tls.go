@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/filenotify"
+	"github.com/docker/go-connections/tlsconfig"
+	"gopkg.in/fsnotify.v1"
+)
+
+const (
+	// tlsReloadDelay is the time to wait after a change of the TLS files
+	// before loading them, so that a certificate and its key written one
+	// after the other are loaded together
+	tlsReloadDelay = 500 * time.Millisecond
+	// tlsRewatchAttempts is the number of attempts to watch a TLS file
+	// again after it was replaced
+	tlsRewatchAttempts = 10
+)
+
+// tlsReloader watches the certificate, key and CA files of the TLS
+// listeners, and swaps the configuration of the listeners, with their
+// certificate and client CA pool, when they change. The current
+// configuration is kept if the new files are not valid.
+type tlsReloader struct {
+	options tlsconfig.Options
+	watcher filenotify.FileWatcher
+
+	mu      sync.RWMutex
+	config  *tls.Config
+	content []byte
+	hook    func(attributes map[string]string)
+}
+
+// newTLSReloader watches the files of options, config being the
+// configuration of the listeners built from options.
+func newTLSReloader(config *tls.Config, options tlsconfig.Options) (*tlsReloader, error) {
+	content, err := readTLSFiles(options)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := filenotify.New()
+	if err != nil {
+		return nil, err
+	}
+	r := &tlsReloader{
+		options: options,
+		watcher: watcher,
+		config:  config,
+		content: content,
+	}
+	for _, name := range r.files() {
+		if err := watcher.Add(name); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("cannot watch %s: %v", name, err)
+		}
+	}
+	config.NextProtos = []string{"http/1.1"}
+	go r.watch()
+	return r, nil
+}
+
+// files returns the TLS files which are set
+func (r *tlsReloader) files() []string {
+	var files []string
+	for _, name := range []string{r.options.CAFile, r.options.CertFile, r.options.KeyFile} {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func readTLSFiles(options tlsconfig.Options) ([]byte, error) {
+	var content []byte
+	for _, name := range []string{options.CAFile, options.CertFile, options.KeyFile} {
+		if name == "" {
+			continue
+		}
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, b...)
+	}
+	return content, nil
+}
+
+// current returns the configuration loaded last.
+func (r *tlsReloader) current() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config
+}
+
+// newListener returns a listener setting up the connections accepted by l
+// with the configuration loaded last.
+func (r *tlsReloader) newListener(l net.Listener) net.Listener {
+	return &tlsListener{Listener: l, reloader: r}
+}
+
+// tlsListener is a TLS listener whose configuration is swapped by a
+// tlsReloader.
+type tlsListener struct {
+	net.Listener
+	reloader *tlsReloader
+}
+
+func (l *tlsListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return tls.Server(c, l.reloader.current()), nil
+}
+
+// setHook sets the function called with the subject and expiry of the new
+// certificate after each rotation.
+func (r *tlsReloader) setHook(hook func(attributes map[string]string)) {
+	r.mu.Lock()
+	r.hook = hook
+	r.mu.Unlock()
+}
+
+// watch loads the TLS files again after they change, until the watcher is
+// closed.
+func (r *tlsReloader) watch() {
+	var (
+		timer  *time.Timer
+		reload <-chan time.Time
+	)
+	for {
+		select {
+		case event, ok := <-r.watcher.Events():
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				// The file was replaced, and the watch of an event
+				// watcher is gone with it.
+				go r.rewatch(event.Name)
+			}
+			if timer == nil {
+				timer = time.NewTimer(tlsReloadDelay)
+			} else {
+				timer.Reset(tlsReloadDelay)
+			}
+			reload = timer.C
+		case err, ok := <-r.watcher.Errors():
+			if !ok {
+				return
+			}
+			logrus.Errorf("Error watching the TLS files of the API: %v", err)
+		case <-reload:
+			reload = nil
+			if err := r.reload(); err != nil {
+				logrus.Errorf("Error reloading the TLS files of the API, keeping the current certificate: %v", err)
+			}
+		}
+	}
+}
+
+// rewatch watches a TLS file again after it was replaced, waiting for the
+// new file to be written if needed.
+func (r *tlsReloader) rewatch(name string) {
+	r.watcher.Remove(name)
+	var err error
+	for i := 0; i < tlsRewatchAttempts; i++ {
+		if err = r.watcher.Add(name); err == nil {
+			return
+		}
+		time.Sleep(tlsReloadDelay)
+	}
+	logrus.Errorf("Cannot watch the TLS file %s of the API: %v", name, err)
+}
+
+// reload loads the TLS files, and swaps the configuration of the listeners
+// if they changed and are valid.
+func (r *tlsReloader) reload() error {
+	content, err := readTLSFiles(r.options)
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	unchanged := bytes.Equal(content, r.content)
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	config, err := tlsconfig.Server(r.options)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	config.NextProtos = []string{"http/1.1"}
+	r.mu.Lock()
+	r.config = config
+	r.content = content
+	hook := r.hook
+	r.mu.Unlock()
+
+	attributes := map[string]string{
+		"subject": leaf.Subject.CommonName,
+		"expires": leaf.NotAfter.UTC().Format(time.RFC3339),
+	}
+	logrus.Infof("Reloaded the TLS certificate of the API for %s, expiring %s", attributes["subject"], attributes["expires"])
+	if hook != nil {
+		hook(attributes)
+	}
+	return nil
+}
+
+// Close stops watching the TLS files.
+func (r *tlsReloader) Close() error {
+	return r.watcher.Close()
+}
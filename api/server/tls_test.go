@@ -0,0 +1,133 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// writeTestCertificate writes a self-signed certificate for commonName and
+// its key to the files of options.
+func writeTestCertificate(t *testing.T, options tlsconfig.Options, commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(options.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(options.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedCertificateName returns the common name of the certificate l serves
+// the TLS connections with.
+func servedCertificateName(t *testing.T, l net.Listener) string {
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		c.(*tls.Conn).Handshake()
+		c.Close()
+	}()
+	c, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	return c.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestTLSReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	options := tlsconfig.Options{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	}
+	writeTestCertificate(t, options, "daemon-1")
+
+	config, err := tlsconfig.Server(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newTLSReloader(config, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	reloaded := make(chan map[string]string, 1)
+	r.setHook(func(attributes map[string]string) {
+		reloaded <- attributes
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = r.newListener(l)
+	defer l.Close()
+
+	if name := servedCertificateName(t, l); name != "daemon-1" {
+		t.Fatalf("Expected the certificate of daemon-1, got %s", name)
+	}
+
+	writeTestCertificate(t, options, "daemon-2")
+	select {
+	case attributes := <-reloaded:
+		if attributes["subject"] != "daemon-2" || attributes["expires"] == "" {
+			t.Fatalf("Unexpected attributes of the rotation %v", attributes)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout waiting for the certificate to be reloaded")
+	}
+	if name := servedCertificateName(t, l); name != "daemon-2" {
+		t.Fatalf("Expected the certificate of daemon-2, got %s", name)
+	}
+	if current := r.current(); current.ClientAuth != config.ClientAuth || len(current.NextProtos) == 0 {
+		t.Fatalf("Expected the reloaded configuration to keep the options, got %+v", current)
+	}
+
+	if err := ioutil.WriteFile(options.CertFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err == nil {
+		t.Fatal("Expected an error reloading an invalid certificate")
+	}
+	if name := servedCertificateName(t, l); name != "daemon-2" {
+		t.Fatalf("Expected the certificate of daemon-2 to be kept, got %s", name)
+	}
+}
@@ -28,9 +28,11 @@ type Command struct {
 var dockerCommands = []Command{
 	{"attach", "Attach to a running container"},
 	{"build", "Build an image from a Dockerfile"},
+	{"checkpoint", "Manage checkpoints of containers"},
 	{"commit", "Create a new image from a container's changes"},
 	{"cp", "Copy files/folders between a container and the local filesystem"},
 	{"create", "Create a new container"},
+	{"device", "Manage devices of running containers"},
 	{"diff", "Inspect changes on a container's filesystem"},
 	{"events", "Get real time events from the server"},
 	{"exec", "Run a command in a running container"},
@@ -58,6 +60,7 @@ var dockerCommands = []Command{
 	{"run", "Run a command in a new container"},
 	{"save", "Save an image(s) to a tar archive"},
 	{"search", "Search the Docker Hub for images"},
+	{"secret", "Manage Docker secrets"},
 	{"start", "Start one or more stopped containers"},
 	{"stats", "Display a live stream of container(s) resource usage statistics"},
 	{"stop", "Stop a running container"},
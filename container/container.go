@@ -31,6 +31,10 @@ import (
 
 const configFileName = "config.v2.json"
 
+// DefaultStopTimeout is the number of seconds to wait for a container to stop
+// before killing it, unless the container sets its own timeout.
+const DefaultStopTimeout = 10
+
 // CommonContainer holds the fields for a container which are
 // applicable across all platforms supported by the daemon.
 type CommonContainer struct {
@@ -229,6 +233,20 @@ func (container *Container) ExitOnNext() {
 	container.monitor.ExitOnNext()
 }
 
+// CancelExitOnNext signals to the monitor that it should apply the restart
+// policy again, when the process was not killed after ExitOnNext.
+func (container *Container) CancelExitOnNext() {
+	container.monitor.CancelExitOnNext()
+}
+
+// UpdateMonitor updates the restart policy of the monitor of a running
+// container, if there is one.
+func (container *Container) UpdateMonitor(policy containertypes.RestartPolicy) {
+	if container.monitor != nil {
+		container.monitor.setRestartPolicy(policy)
+	}
+}
+
 // Resize changes the TTY of the process running inside the container
 // to the given height and width. The container must be running.
 func (container *Container) Resize(h, w int) error {
@@ -251,6 +269,12 @@ func (container *Container) ConfigPath() (string, error) {
 	return container.GetRootResourcePath(configFileName)
 }
 
+// CheckpointDir returns the directory checkpoints of the container are
+// stored in.
+func (container *Container) CheckpointDir() (string, error) {
+	return container.GetRootResourcePath("checkpoints")
+}
+
 func validateID(id string) error {
 	if id == "" {
 		return derr.ErrorCodeEmptyID
@@ -493,7 +517,8 @@ func copyEscapable(dst io.Writer, src io.ReadCloser, keys []byte) (written int64
 func (container *Container) ShouldRestart() bool {
 	return container.HostConfig.RestartPolicy.Name == "always" ||
 		(container.HostConfig.RestartPolicy.Name == "unless-stopped" && !container.HasBeenManuallyStopped) ||
-		(container.HostConfig.RestartPolicy.Name == "on-failure" && container.ExitCode != 0)
+		(container.HostConfig.RestartPolicy.Name == "on-failure" && container.ExitCode != 0) ||
+		(container.HostConfig.RestartPolicy.Name == "on-exit-codes" && container.HostConfig.RestartPolicy.RestartsOnExitCode(container.ExitCode))
 }
 
 // AddBindMountPoint adds a new bind mount point configuration to the container.
@@ -532,6 +557,15 @@ func (container *Container) IsDestinationMounted(destination string) bool {
 	return container.MountPoints[destination] != nil
 }
 
+// StopTimeout returns the number of seconds to wait for the container to
+// stop before killing it, when no timeout is given to stop it.
+func (container *Container) StopTimeout() int {
+	if container.Config.StopTimeout != nil {
+		return *container.Config.StopTimeout
+	}
+	return DefaultStopTimeout
+}
+
 // StopSignal returns the signal used to stop the container.
 func (container *Container) StopSignal() int {
 	var stopSignal syscall.Signal
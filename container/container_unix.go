@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"syscall"
@@ -16,6 +17,7 @@ import (
 	"github.com/docker/docker/daemon/execdriver"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
 	runconfigopts "github.com/docker/docker/runconfig/opts"
@@ -24,6 +26,7 @@ import (
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/engine-api/types/network"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 	"github.com/docker/libnetwork"
 	"github.com/docker/libnetwork/netlabel"
 	"github.com/docker/libnetwork/options"
@@ -47,6 +50,12 @@ type Container struct {
 	MqueuePath      string
 	ResolvConfPath  string
 	SeccompProfile  string
+	NoNewPrivileges bool
+
+	// UIDMaps and GIDMaps are the ID mappings of the range of subordinate
+	// IDs allocated to a container with a private user namespace
+	UIDMaps []idtools.IDMap `json:",omitempty"`
+	GIDMaps []idtools.IDMap `json:",omitempty"`
 }
 
 // CreateDaemonEnvironment returns the list of all environment variables given the list of
@@ -502,6 +511,22 @@ func (container *Container) MqueueResourcePath() (string, error) {
 	return container.GetRootResourcePath("mqueue")
 }
 
+// SecretsResourcePath returns the path of the tmpfs holding the secrets of
+// the container
+func (container *Container) SecretsResourcePath() (string, error) {
+	return container.GetRootResourcePath("secrets")
+}
+
+// RootfsPath returns the path of the root filesystem the container runs on.
+// The root filesystem of a container with a private user namespace is bind
+// mounted in the container directory.
+func (container *Container) RootfsPath() (string, error) {
+	if container.UIDMaps == nil {
+		return container.BaseFS, nil
+	}
+	return container.GetRootResourcePath("rootfs")
+}
+
 // HasMountFor checks if path is a mountpoint
 func (container *Container) HasMountFor(path string) bool {
 	_, exists := container.MountPoints[path]
@@ -546,6 +571,22 @@ func (container *Container) UnmountIpcMounts(unmount func(pth string) error) {
 	}
 }
 
+// UnmountSecrets uses the provided unmount function to unmount the tmpfs
+// holding the secrets of the container, if it has any.
+func (container *Container) UnmountSecrets(unmount func(pth string) error) {
+	if len(container.HostConfig.Secrets) == 0 {
+		return
+	}
+	secretsPath, err := container.SecretsResourcePath()
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+	if err := unmount(secretsPath); err != nil {
+		logrus.Warnf("failed to umount %s: %v", secretsPath, err)
+	}
+}
+
 // IpcMounts returns the list of IPC mounts
 func (container *Container) IpcMounts() []execdriver.Mount {
 	var mounts []execdriver.Mount
@@ -583,62 +624,196 @@ func updateCommand(c *execdriver.Command, resources container.Resources) {
 	c.Resources.MemorySwap = resources.MemorySwap
 	c.Resources.MemoryReservation = resources.MemoryReservation
 	c.Resources.KernelMemory = resources.KernelMemory
+	c.Resources.PidsLimit = resources.PidsLimit
+
+	// The rlimits of the command include the daemon defaults, only the
+	// ones set for the container replace them.
+	for _, ul := range resources.Ulimits {
+		rl, err := ul.GetRlimit()
+		if err != nil {
+			continue
+		}
+		c.Resources.Rlimits = mergeRlimit(c.Resources.Rlimits, rl)
+	}
+
+	// Interfaces which are no longer limited are kept with a rate of 0, so
+	// the driver removes their limits.
+	var rateLimits []execdriver.NetworkRateLimit
+	for _, l := range resources.NetworkRateLimits {
+		rateLimits = append(rateLimits, execdriver.NetworkRateLimit{
+			Interface:   l.Interface,
+			EgressRate:  l.EgressRate,
+			IngressRate: l.IngressRate,
+		})
+	}
+	for _, l := range c.Resources.NetworkRateLimits {
+		if (l.EgressRate != 0 || l.IngressRate != 0) && !hasNetworkRateLimit(resources.NetworkRateLimits, l.Interface) {
+			rateLimits = append(rateLimits, execdriver.NetworkRateLimit{Interface: l.Interface})
+		}
+	}
+	c.Resources.NetworkRateLimits = rateLimits
 }
 
-// UpdateContainer updates resources of a container.
-func (container *Container) UpdateContainer(hostConfig *container.HostConfig) error {
+func mergeRlimit(rlimits []*units.Rlimit, rl *units.Rlimit) []*units.Rlimit {
+	merged := make([]*units.Rlimit, 0, len(rlimits)+1)
+	for _, r := range rlimits {
+		if r.Type != rl.Type {
+			merged = append(merged, r)
+		}
+	}
+	return append(merged, rl)
+}
+
+func hasNetworkRateLimit(limits []container.NetworkRateLimit, iface string) bool {
+	for _, l := range limits {
+		if l.Interface == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateContainer updates the resources and the restart policy of a
+// container. It returns the names of the fields of the host config that
+// changed.
+func (container *Container) UpdateContainer(hostConfig *container.HostConfig) ([]string, error) {
 	container.Lock()
 
+	var changed []string
 	resources := hostConfig.Resources
 	cResources := &container.HostConfig.Resources
-	if resources.BlkioWeight != 0 {
+	if resources.BlkioWeight != 0 && resources.BlkioWeight != cResources.BlkioWeight {
 		cResources.BlkioWeight = resources.BlkioWeight
+		changed = append(changed, "BlkioWeight")
 	}
-	if resources.CPUShares != 0 {
+	if resources.CPUShares != 0 && resources.CPUShares != cResources.CPUShares {
 		cResources.CPUShares = resources.CPUShares
+		changed = append(changed, "CpuShares")
 	}
-	if resources.CPUPeriod != 0 {
+	if resources.CPUPeriod != 0 && resources.CPUPeriod != cResources.CPUPeriod {
 		cResources.CPUPeriod = resources.CPUPeriod
+		changed = append(changed, "CpuPeriod")
 	}
-	if resources.CPUQuota != 0 {
+	if resources.CPUQuota != 0 && resources.CPUQuota != cResources.CPUQuota {
 		cResources.CPUQuota = resources.CPUQuota
+		changed = append(changed, "CpuQuota")
 	}
-	if resources.CpusetCpus != "" {
+	if resources.CpusetCpus != "" && resources.CpusetCpus != cResources.CpusetCpus {
 		cResources.CpusetCpus = resources.CpusetCpus
+		changed = append(changed, "CpusetCpus")
 	}
-	if resources.CpusetMems != "" {
+	if resources.CpusetMems != "" && resources.CpusetMems != cResources.CpusetMems {
 		cResources.CpusetMems = resources.CpusetMems
+		changed = append(changed, "CpusetMems")
 	}
-	if resources.Memory != 0 {
+	if resources.Memory != 0 && resources.Memory != cResources.Memory {
 		cResources.Memory = resources.Memory
+		changed = append(changed, "Memory")
 	}
-	if resources.MemorySwap != 0 {
+	if resources.MemorySwap != 0 && resources.MemorySwap != cResources.MemorySwap {
 		cResources.MemorySwap = resources.MemorySwap
+		changed = append(changed, "MemorySwap")
 	}
-	if resources.MemoryReservation != 0 {
+	if resources.MemoryReservation != 0 && resources.MemoryReservation != cResources.MemoryReservation {
 		cResources.MemoryReservation = resources.MemoryReservation
+		changed = append(changed, "MemoryReservation")
 	}
-	if resources.KernelMemory != 0 {
+	if resources.KernelMemory != 0 && resources.KernelMemory != cResources.KernelMemory {
 		cResources.KernelMemory = resources.KernelMemory
+		changed = append(changed, "KernelMemory")
+	}
+	if resources.PidsLimit != 0 && resources.PidsLimit != cResources.PidsLimit {
+		cResources.PidsLimit = resources.PidsLimit
+		changed = append(changed, "PidsLimit")
+	}
+	if ulimits, ok := mergeUlimits(cResources.Ulimits, resources.Ulimits); ok {
+		cResources.Ulimits = ulimits
+		changed = append(changed, "Ulimits")
+	}
+	if resources.DeviceCgroupRules != nil && !reflect.DeepEqual(resources.DeviceCgroupRules, cResources.DeviceCgroupRules) {
+		cResources.DeviceCgroupRules = resources.DeviceCgroupRules
+		changed = append(changed, "DeviceCgroupRules")
+	}
+	if limits, ok := mergeNetworkRateLimits(cResources.NetworkRateLimits, resources.NetworkRateLimits); ok {
+		cResources.NetworkRateLimits = limits
+		changed = append(changed, "NetworkRateLimits")
+	}
+	if policy := hostConfig.RestartPolicy; policy.Name != "" && !reflect.DeepEqual(policy, container.HostConfig.RestartPolicy) {
+		container.HostConfig.RestartPolicy = policy
+		container.UpdateMonitor(policy)
+		changed = append(changed, "RestartPolicy")
 	}
-	container.Unlock()
 
 	// If container is not running, update hostConfig struct is enough,
 	// resources will be updated when the container is started again.
 	// If container is running (including paused), we need to update
 	// the command so we can update configs to the real world.
-	if container.IsRunning() {
-		container.Lock()
+	if container.Running {
 		updateCommand(container.Command, *cResources)
-		container.Unlock()
 	}
+	container.Unlock()
 
 	if err := container.ToDiskLocking(); err != nil {
 		logrus.Errorf("Error saving updated container: %v", err)
-		return err
+		return changed, err
 	}
 
-	return nil
+	return changed, nil
+}
+
+// mergeUlimits returns ulimits with the ones in update replacing the ulimits
+// of the same name, and whether that changed any of them.
+func mergeUlimits(ulimits, update []*units.Ulimit) ([]*units.Ulimit, bool) {
+	changed := false
+	merged := append([]*units.Ulimit{}, ulimits...)
+next:
+	for _, ul := range update {
+		for i, u := range merged {
+			if u.Name == ul.Name {
+				if *u != *ul {
+					merged[i] = ul
+					changed = true
+				}
+				continue next
+			}
+		}
+		merged = append(merged, ul)
+		changed = true
+	}
+	return merged, changed
+}
+
+// mergeNetworkRateLimits returns limits with the ones in update replacing the
+// limits of the same interface, and whether that changed any of them.
+// Interfaces with neither an egress nor an ingress rate are removed.
+func mergeNetworkRateLimits(limits, update []container.NetworkRateLimit) ([]container.NetworkRateLimit, bool) {
+	changed := false
+	merged := append([]container.NetworkRateLimit{}, limits...)
+next:
+	for _, l := range update {
+		for i, m := range merged {
+			if m.Interface == l.Interface {
+				if m != l {
+					merged[i] = l
+					changed = true
+				}
+				continue next
+			}
+		}
+		// removing the limit of an interface without one changes nothing
+		if l.EgressRate != 0 || l.IngressRate != 0 {
+			merged = append(merged, l)
+			changed = true
+		}
+	}
+
+	var kept []container.NetworkRateLimit
+	for _, l := range merged {
+		if l.EgressRate != 0 || l.IngressRate != 0 {
+			kept = append(kept, l)
+		}
+	}
+	return kept, changed
 }
 
 func detachMounted(path string) error {
@@ -0,0 +1,51 @@
+// +build linux freebsd
+
+package container
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/engine-api/types/container"
+	"github.com/docker/go-units"
+)
+
+func TestMergeUlimits(t *testing.T) {
+	ulimits := []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}
+
+	if _, changed := mergeUlimits(ulimits, []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}); changed {
+		t.Fatal("Expected an unchanged ulimit not to be reported as changed")
+	}
+
+	merged, changed := mergeUlimits(ulimits, []*units.Ulimit{{Name: "nofile", Soft: 4096, Hard: 4096}, {Name: "nproc", Soft: 100, Hard: 100}})
+	expected := []*units.Ulimit{{Name: "nofile", Soft: 4096, Hard: 4096}, {Name: "nproc", Soft: 100, Hard: 100}}
+	if !changed || !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("Expected ulimits %v, got %v", expected, merged)
+	}
+	if ulimits[0].Soft != 1024 {
+		t.Fatal("Expected the original ulimits to be left alone")
+	}
+}
+
+func TestMergeNetworkRateLimits(t *testing.T) {
+	limits := []container.NetworkRateLimit{
+		{Interface: "eth0", EgressRate: 1024},
+		{Interface: "eth1", IngressRate: 2048},
+	}
+
+	merged, changed := mergeNetworkRateLimits(limits, []container.NetworkRateLimit{
+		{Interface: "eth0"},
+		{Interface: "eth2", EgressRate: 4096},
+	})
+	expected := []container.NetworkRateLimit{
+		{Interface: "eth1", IngressRate: 2048},
+		{Interface: "eth2", EgressRate: 4096},
+	}
+	if !changed || !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("Expected network rate limits %v, got %v", expected, merged)
+	}
+
+	if _, changed := mergeNetworkRateLimits(limits, []container.NetworkRateLimit{{Interface: "eth3"}}); changed {
+		t.Fatal("Expected removing the limit of an interface without one not to be reported as changed")
+	}
+}
@@ -38,6 +38,11 @@ func (container *Container) IpcMounts() []execdriver.Mount {
 	return nil
 }
 
+// UnmountSecrets unmounts the secrets of the container.
+// This is a NOOP on windows.
+func (container *Container) UnmountSecrets(unmount func(pth string) error) {
+}
+
 // UnmountVolumes explicitly unmounts volumes from the container.
 func (container *Container) UnmountVolumes(forceSyscall bool, volumeEventLog func(name, action string, attributes map[string]string)) error {
 	return nil
@@ -49,8 +54,8 @@ func (container *Container) TmpfsMounts() []execdriver.Mount {
 }
 
 // UpdateContainer updates resources of a container
-func (container *Container) UpdateContainer(hostConfig *container.HostConfig) error {
-	return nil
+func (container *Container) UpdateContainer(hostConfig *container.HostConfig) ([]string, error) {
+	return nil, nil
 }
 
 // appendNetworkMounts appends any network mounts to the array of mount points passed in.
@@ -18,8 +18,9 @@ import (
 )
 
 const (
-	defaultTimeIncrement = 100
-	loggerCloseTimeout   = 10 * time.Second
+	defaultRestartDelay = 100 * time.Millisecond
+	defaultResetWindow  = 10 * time.Second
+	loggerCloseTimeout  = 10 * time.Second
 )
 
 // supervisor defines the interface that a supervisor must implement
@@ -32,6 +33,8 @@ type supervisor interface {
 	StartLogging(*Container) error
 	// Run starts a container
 	Run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error)
+	// Restore restores a container from the checkpoint in checkpointDir
+	Restore(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback, checkpointDir string) (execdriver.ExitStatus, error)
 	// IsShuttingDown tells whether the supervisor is shutting down or not
 	IsShuttingDown() bool
 }
@@ -69,31 +72,43 @@ type containerMonitor struct {
 	// left waiting for nothing to happen during this time
 	stopChan chan struct{}
 
-	// timeIncrement is the amount of time to wait between restarts
-	// this is in milliseconds
-	timeIncrement int
+	// backoff is the amount of time to wait before the next restart
+	backoff time.Duration
 
 	// lastStartTime is the time which the monitor last exec'd the container's process
 	lastStartTime time.Time
+
+	// checkpointDir is the directory of the checkpoint the container's process
+	// is restored from when it first starts, if any
+	checkpointDir string
 }
 
 // StartMonitor initializes a containerMonitor for this container with the provided supervisor and restart policy
-// and starts the container's process.
-func (container *Container) StartMonitor(s supervisor, policy container.RestartPolicy) error {
+// and starts the container's process. If checkpointDir is not empty, the process is restored from the
+// checkpoint in it.
+func (container *Container) StartMonitor(s supervisor, policy container.RestartPolicy, checkpointDir string) error {
 	container.Lock()
 	container.monitor = &containerMonitor{
 		supervisor:    s,
 		container:     container,
 		restartPolicy: policy,
-		timeIncrement: defaultTimeIncrement,
 		stopChan:      make(chan struct{}),
 		startSignal:   make(chan struct{}),
+		checkpointDir: checkpointDir,
 	}
 	container.Unlock()
 
 	return container.monitor.wait()
 }
 
+// setRestartPolicy replaces the restart policy applied the next time the
+// container's process exits.
+func (m *containerMonitor) setRestartPolicy(policy container.RestartPolicy) {
+	m.mux.Lock()
+	m.restartPolicy = policy
+	m.mux.Unlock()
+}
+
 // wait starts the container and wait until
 // we either receive an error from the initial start of the container's
 // process or until the process is running in the container
@@ -122,6 +137,17 @@ func (m *containerMonitor) ExitOnNext() {
 	m.mux.Unlock()
 }
 
+// CancelExitOnNext undoes ExitOnNext, so that the restart policy applies
+// again the next time the process dies
+func (m *containerMonitor) CancelExitOnNext() {
+	m.mux.Lock()
+	if m.shouldStop {
+		m.shouldStop = false
+		m.stopChan = make(chan struct{})
+	}
+	m.mux.Unlock()
+}
+
 // Close closes the container's resources such as networking allocations and
 // unmounts the container's root filesystem
 func (m *containerMonitor) Close() error {
@@ -180,6 +206,8 @@ func (m *containerMonitor) start() error {
 		}
 
 		pipes := execdriver.NewPipes(m.container.Stdin(), m.container.Stdout(), m.container.Stderr(), m.container.Config.OpenStdin)
+		// only the first run of the process is restored from the checkpoint
+		restore := m.checkpointDir != "" && m.container.RestartCount == 0
 		m.container.Unlock()
 
 		m.logEvent("start")
@@ -187,7 +215,12 @@ func (m *containerMonitor) start() error {
 		m.lastStartTime = time.Now()
 
 		// don't lock Run because m.callback has own lock
-		if exitStatus, err = m.supervisor.Run(m.container, pipes, m.callback); err != nil {
+		if restore {
+			exitStatus, err = m.supervisor.Restore(m.container, pipes, m.callback, m.checkpointDir)
+		} else {
+			exitStatus, err = m.supervisor.Run(m.container, pipes, m.callback)
+		}
+		if err != nil {
 			m.container.Lock()
 			// if we receive an internal error from the initial start of a container then lets
 			// return it instead of entering the restart loop
@@ -234,7 +267,7 @@ func (m *containerMonitor) start() error {
 			m.logEvent("die")
 			m.resetContainer(true)
 
-			// sleep with a small time increment between each restart to help avoid issues cased by quickly
+			// sleep with a growing delay between each restart to help avoid issues cased by quickly
 			// restarting the container because of some types of errors ( networking cut out, etc... )
 			m.waitForNextRestart()
 
@@ -255,16 +288,34 @@ func (m *containerMonitor) start() error {
 
 // resetMonitor resets the stateful fields on the containerMonitor based on the
 // previous runs success or failure.  Regardless of success, if the container had
-// an execution time of more than 10s then reset the timer back to the default
+// an execution time of more than the reset window of the restart policy (10s by
+// default) then reset the backoff back to the initial delay
 func (m *containerMonitor) resetMonitor(successful bool) {
-	executionTime := time.Now().Sub(m.lastStartTime).Seconds()
+	executionTime := time.Now().Sub(m.lastStartTime)
+
+	m.mux.Lock()
+	policy := m.restartPolicy
+	m.mux.Unlock()
+
+	resetWindow := policy.ResetWindow
+	if resetWindow == 0 {
+		resetWindow = defaultResetWindow
+	}
+	initialDelay := policy.InitialDelay
+	if initialDelay == 0 {
+		initialDelay = defaultRestartDelay
+	}
 
-	if executionTime > 10 {
-		m.timeIncrement = defaultTimeIncrement
+	if m.backoff == 0 || executionTime > resetWindow {
+		m.backoff = initialDelay
 	} else {
 		// otherwise we need to increment the amount of time we wait before restarting
-		// the process.  We will build up by multiplying the increment by 2
-		m.timeIncrement *= 2
+		// the process.  We will build up by multiplying the backoff by 2, up to the
+		// maximum delay of the restart policy
+		m.backoff *= 2
+		if max := policy.MaxDelay; max != 0 && m.backoff > max {
+			m.backoff = max
+		}
 	}
 
 	// the container exited successfully so we need to reset the failure counter
@@ -275,11 +326,18 @@ func (m *containerMonitor) resetMonitor(successful bool) {
 	}
 }
 
-// waitForNextRestart waits with the default time increment to restart the container unless
+// waitForNextRestart waits for the current backoff to restart the container unless
 // a user or docker asks for the container to be stopped
 func (m *containerMonitor) waitForNextRestart() {
+	m.container.Lock()
+	m.container.SetNextRestart(m.backoff)
+	if err := m.container.ToDisk(); err != nil {
+		logrus.Errorf("Error saving container to disk: %v", err)
+	}
+	m.container.Unlock()
+
 	select {
-	case <-time.After(time.Duration(m.timeIncrement) * time.Millisecond):
+	case <-time.After(m.backoff):
 	case <-m.stopChan:
 	}
 }
@@ -308,6 +366,14 @@ func (m *containerMonitor) shouldRestart(exitCode int) bool {
 		}
 
 		return exitCode != 0
+	case m.restartPolicy.IsOnExitCodes():
+		if max := m.restartPolicy.MaximumRetryCount; max != 0 && m.failureCount > max {
+			logrus.Debugf("stopping restart of container %s because maximum failure could of %d has been reached",
+				stringid.TruncateID(m.container.ID), max)
+			return false
+		}
+
+		return m.restartPolicy.RestartsOnExitCode(exitCode)
 	}
 
 	return false
@@ -318,6 +384,9 @@ func (m *containerMonitor) shouldRestart(exitCode int) bool {
 func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid int, chOOM <-chan struct{}) error {
 	go func() {
 		for range chOOM {
+			m.container.Lock()
+			m.container.IncrementOOMCount()
+			m.container.Unlock()
 			m.logEvent("oom")
 		}
 	}()
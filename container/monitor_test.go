@@ -0,0 +1,95 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/types/container"
+)
+
+func TestMonitorBackoff(t *testing.T) {
+	m := &containerMonitor{
+		restartPolicy: container.RestartPolicy{
+			Name:         "always",
+			InitialDelay: time.Second,
+			MaxDelay:     3 * time.Second,
+			ResetWindow:  time.Minute,
+		},
+		lastStartTime: time.Now(),
+	}
+
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		m.resetMonitor(false)
+		if m.backoff != expected {
+			t.Fatalf("Expected a backoff of %v, got %v", expected, m.backoff)
+		}
+	}
+
+	// a run longer than the reset window resets the backoff
+	m.lastStartTime = time.Now().Add(-2 * time.Minute)
+	m.resetMonitor(false)
+	if m.backoff != time.Second {
+		t.Fatalf("Expected the backoff to be reset to %v, got %v", time.Second, m.backoff)
+	}
+}
+
+func TestMonitorDefaultBackoff(t *testing.T) {
+	m := &containerMonitor{
+		restartPolicy: container.RestartPolicy{Name: "always"},
+		lastStartTime: time.Now(),
+	}
+	m.resetMonitor(false)
+	if m.backoff != defaultRestartDelay {
+		t.Fatalf("Expected a backoff of %v, got %v", defaultRestartDelay, m.backoff)
+	}
+	m.resetMonitor(false)
+	if m.backoff != 2*defaultRestartDelay {
+		t.Fatalf("Expected a backoff of %v, got %v", 2*defaultRestartDelay, m.backoff)
+	}
+}
+
+func TestMonitorShouldRestartOnExitCodes(t *testing.T) {
+	m := &containerMonitor{
+		container: &Container{},
+		restartPolicy: container.RestartPolicy{
+			Name:              "on-exit-codes",
+			ExitCodes:         []int{3, 137},
+			MaximumRetryCount: 2,
+		},
+	}
+
+	for exitCode, expected := range map[int]bool{0: false, 1: false, 3: true, 137: true} {
+		if actual := m.shouldRestart(exitCode); actual != expected {
+			t.Fatalf("Expected shouldRestart(%d) to be %v, got %v", exitCode, expected, actual)
+		}
+	}
+
+	m.failureCount = 3
+	if m.shouldRestart(3) {
+		t.Fatal("Expected no restart once the maximum retry count is reached")
+	}
+}
+
+func TestMonitorCancelExitOnNext(t *testing.T) {
+	m := &containerMonitor{
+		container:     &Container{},
+		restartPolicy: container.RestartPolicy{Name: "always"},
+		stopChan:      make(chan struct{}),
+	}
+	m.ExitOnNext()
+	m.CancelExitOnNext()
+
+	if !m.shouldRestart(0) {
+		t.Fatal("Expected the container to be restarted once ExitOnNext is cancelled")
+	}
+	select {
+	case <-m.stopChan:
+		t.Fatal("Expected the stop channel to be open once ExitOnNext is cancelled")
+	default:
+	}
+	// the monitor can be told to stop again
+	m.ExitOnNext()
+	if !m.shouldStop {
+		t.Fatal("Expected the monitor to stop on the next exit")
+	}
+}
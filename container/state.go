@@ -28,9 +28,31 @@ type State struct {
 	Error             string // contains last known error when starting the container
 	StartedAt         time.Time
 	FinishedAt        time.Time
+	PausedAt          time.Time       // time the container was last paused
+	RestartBackoff    time.Duration   // delay before the next restart, while restarting
+	NextRestartAt     time.Time       // time of the next restart, while restarting
+	Usage             *ResourceUsage  // resource usage of the current or last run
+	UsageHistory      []ResourceUsage // per-minute samples of the usage of the current or last run
 	waitChan          chan struct{}
 }
 
+// ResourceUsage is the resource usage of the process of a container,
+// accumulated since it was started.
+type ResourceUsage struct {
+	Read            time.Time // time the usage was read
+	CPUTime         uint64    // total CPU time in nanoseconds
+	MemoryUsage     uint64    // memory usage in bytes when the usage was read
+	MemoryMaxUsage  uint64    // peak memory usage in bytes
+	OOMCount        uint64    // number of times the container ran out of memory
+	BlkioReadBytes  uint64    // bytes read from block devices
+	BlkioWriteBytes uint64    // bytes written to block devices
+	NetworkRxBytes  uint64    // bytes received on all network interfaces
+	NetworkTxBytes  uint64    // bytes sent on all network interfaces
+}
+
+// maxUsageHistory is the number of usage samples kept, one hour of them.
+const maxUsageHistory = 60
+
 // NewState creates a default state object with a fresh channel for state changes.
 func NewState() *State {
 	return &State{
@@ -192,13 +214,43 @@ func (s *State) SetRunning(pid int) {
 	s.Running = true
 	s.Paused = false
 	s.Restarting = false
+	s.RestartBackoff = 0
+	s.NextRestartAt = time.Time{}
 	s.ExitCode = 0
 	s.Pid = pid
 	s.StartedAt = time.Now().UTC()
+	s.Usage = nil
+	s.UsageHistory = nil
 	close(s.waitChan) // fire waiters for start
 	s.waitChan = make(chan struct{})
 }
 
+// AddUsageSample records u as the current resource usage of the container
+// and adds it to the usage history, which keeps the most recent samples.
+// The OOM count is kept from the previous usage, as it is not part of the
+// samples.
+func (s *State) AddUsageSample(u ResourceUsage) {
+	if s.Usage != nil {
+		u.OOMCount = s.Usage.OOMCount
+		if s.Usage.MemoryMaxUsage > u.MemoryMaxUsage {
+			u.MemoryMaxUsage = s.Usage.MemoryMaxUsage
+		}
+	}
+	s.Usage = &u
+	s.UsageHistory = append(s.UsageHistory, u)
+	if n := len(s.UsageHistory); n > maxUsageHistory {
+		s.UsageHistory = append([]ResourceUsage(nil), s.UsageHistory[n-maxUsageHistory:]...)
+	}
+}
+
+// IncrementOOMCount counts an out of memory event of the container.
+func (s *State) IncrementOOMCount() {
+	if s.Usage == nil {
+		s.Usage = &ResourceUsage{Read: time.Now().UTC()}
+	}
+	s.Usage.OOMCount++
+}
+
 // SetStoppedLocking locks the container state and sets it to "stopped".
 func (s *State) SetStoppedLocking(exitStatus *execdriver.ExitStatus) {
 	s.Lock()
@@ -210,6 +262,8 @@ func (s *State) SetStoppedLocking(exitStatus *execdriver.ExitStatus) {
 func (s *State) SetStopped(exitStatus *execdriver.ExitStatus) {
 	s.Running = false
 	s.Restarting = false
+	s.RestartBackoff = 0
+	s.NextRestartAt = time.Time{}
 	s.Pid = 0
 	s.FinishedAt = time.Now().UTC()
 	s.setFromExitStatus(exitStatus)
@@ -239,6 +293,13 @@ func (s *State) SetRestarting(exitStatus *execdriver.ExitStatus) {
 	s.waitChan = make(chan struct{})
 }
 
+// SetNextRestart records the delay before the next restart of a restarting
+// container.
+func (s *State) SetNextRestart(backoff time.Duration) {
+	s.RestartBackoff = backoff
+	s.NextRestartAt = time.Now().UTC().Add(backoff)
+}
+
 // SetError sets the container's error state. This is useful when we want to
 // know the error that occurred when container transits to another state
 // when inspecting it
@@ -109,3 +109,35 @@ func TestStateTimeoutWait(t *testing.T) {
 	}
 
 }
+
+func TestStateAddUsageSample(t *testing.T) {
+	s := NewState()
+	s.IncrementOOMCount()
+	s.AddUsageSample(ResourceUsage{CPUTime: 10, MemoryMaxUsage: 200})
+	s.AddUsageSample(ResourceUsage{CPUTime: 20, MemoryMaxUsage: 100})
+
+	if s.Usage.CPUTime != 20 {
+		t.Fatalf("Expected CPU time 20, got %d", s.Usage.CPUTime)
+	}
+	if s.Usage.MemoryMaxUsage != 200 {
+		t.Fatalf("Expected peak memory usage 200, got %d", s.Usage.MemoryMaxUsage)
+	}
+	if s.Usage.OOMCount != 1 {
+		t.Fatalf("Expected OOM count 1, got %d", s.Usage.OOMCount)
+	}
+
+	for i := 0; i < maxUsageHistory+5; i++ {
+		s.AddUsageSample(ResourceUsage{CPUTime: uint64(i)})
+	}
+	if len(s.UsageHistory) != maxUsageHistory {
+		t.Fatalf("Expected %d samples in the usage history, got %d", maxUsageHistory, len(s.UsageHistory))
+	}
+	if last := s.UsageHistory[maxUsageHistory-1].CPUTime; last != maxUsageHistory+4 {
+		t.Fatalf("Expected the last sample to have CPU time %d, got %d", maxUsageHistory+4, last)
+	}
+
+	s.SetRunning(1)
+	if s.Usage != nil || s.UsageHistory != nil {
+		t.Fatal("Expected the usage to be reset when the container starts")
+	}
+}
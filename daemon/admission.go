@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/daemon/admission"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/reference"
+)
+
+// ReloadAdmissionPolicy loads the admission policy file of the daemon again.
+// The current policy is kept if the file is not valid.
+func (daemon *Daemon) ReloadAdmissionPolicy() error {
+	if daemon.admission == nil {
+		return derr.ErrorCodeNoAdmissionPolicy
+	}
+	if err := daemon.admission.Reload(); err != nil {
+		return err
+	}
+	logrus.Info("Reloaded the admission policy")
+	return nil
+}
+
+// verifyImageAdmission checks that img, which refOrID resolved to, complies
+// with the admission policy of the daemon. Images without references, such
+// as the intermediate images of builds, are checked with the references of
+// their nearest ancestor which has some.
+func (daemon *Daemon) verifyImageAdmission(refOrID string, img *image.Image) error {
+	if daemon.admission == nil {
+		return nil
+	}
+
+	ai := admission.Image{
+		Ref:    refOrID,
+		ID:     img.ID(),
+		Config: img,
+	}
+	if _, err := digest.ParseDigest(refOrID); err != nil {
+		if ref, err := reference.ParseNamed(refOrID); err == nil {
+			ref = reference.WithDefaultTag(ref)
+			if id, err := daemon.referenceStore.Get(ref); err == nil && id == img.ID() {
+				ai.Resolved = ref
+			}
+		}
+	}
+	for id := img.ID(); ; {
+		if ai.References = daemon.referenceStore.References(id); len(ai.References) > 0 {
+			break
+		}
+		parent, err := daemon.imageStore.GetParent(id)
+		if err != nil {
+			break
+		}
+		id = parent
+	}
+
+	if err := daemon.admission.Admit(ai); err != nil {
+		if v, ok := err.(admission.Violation); ok {
+			logrus.Warnf("Refused to create a container: %v", v)
+			return derr.ErrorCodeAdmissionPolicy.WithArgs(v)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+// Package admission decides whether an image may be used to create
+// containers, under a local policy of allowed registries, required and
+// denied labels, denied digests and maximum image age.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/reference"
+)
+
+// Rules of a policy, named in the violations.
+const (
+	// RuleDeniedDigests rejects the images whose ID, repository digest or
+	// layers are denied.
+	RuleDeniedDigests = "deniedDigests"
+	// RuleAllowedRegistries only accepts the images named in the allowed
+	// registries and repositories.
+	RuleAllowedRegistries = "allowedRegistries"
+	// RuleRequiredLabels only accepts the images with the required labels.
+	RuleRequiredLabels = "requiredLabels"
+	// RuleDeniedLabels rejects the images with the denied labels.
+	RuleDeniedLabels = "deniedLabels"
+	// RuleMaxAge rejects the images created longer ago than the maximum age.
+	RuleMaxAge = "maxAge"
+)
+
+// Policy holds the rules images must comply with. The rules which are not
+// set accept all images.
+type Policy struct {
+	// AllowedRegistries holds the scopes of the images which are accepted.
+	// A scope is a registry hostname, like "docker.io", or a full
+	// repository name or prefix, like "docker.io/library" or
+	// "docker.io/library/ubuntu". The scopes are matched against the
+	// references of the images, which anyone allowed to tag or load images
+	// can set, not against the repositories their layers were pulled from.
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+	// RequiredLabels maps the labels images must have to their value, or
+	// to "" if any value is accepted.
+	RequiredLabels map[string]string `json:"requiredLabels,omitempty"`
+	// DeniedLabels maps the labels images must not have to their value,
+	// or to "" if any value is denied.
+	DeniedLabels map[string]string `json:"deniedLabels,omitempty"`
+	// DeniedDigests holds the digests of the images which are rejected,
+	// either image IDs, repository digests or layer digests. A layer
+	// digest rejects all the images built on top of the layer, such as
+	// the images built from a forbidden base image.
+	DeniedDigests []digest.Digest `json:"deniedDigests,omitempty"`
+	// MaxAge is the maximum age of images, as a duration like "2160h".
+	MaxAge string `json:"maxAge,omitempty"`
+
+	maxAge time.Duration
+}
+
+// LoadPolicy reads and validates a JSON policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p Policy
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid admission policy %s: %v", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid admission policy %s: %v", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks the rules of the policy.
+func (p *Policy) Validate() error {
+	for _, scope := range p.AllowedRegistries {
+		if scope == "" || strings.HasSuffix(scope, "/") {
+			return fmt.Errorf("invalid allowed registry %q", scope)
+		}
+	}
+	for _, d := range p.DeniedDigests {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("invalid denied digest %q: %v", d, err)
+		}
+	}
+	if p.MaxAge != "" {
+		maxAge, err := time.ParseDuration(p.MaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid maximum age %q: %v", p.MaxAge, err)
+		}
+		if maxAge <= 0 {
+			return fmt.Errorf("invalid maximum age %q: must be positive", p.MaxAge)
+		}
+		p.maxAge = maxAge
+	}
+	return nil
+}
+
+// Image is an image a container is created from.
+type Image struct {
+	// Ref is the reference or ID the image was requested by.
+	Ref string
+	// ID is the ID of the image.
+	ID image.ID
+	// Config is the configuration of the image.
+	Config *image.Image
+	// Resolved is the reference Ref resolved to, or nil if the image was
+	// requested by ID.
+	Resolved reference.Named
+	// References are the references of the image, or of its nearest
+	// ancestor with references if it has none, such as the base image of
+	// an image built locally.
+	References []reference.Named
+}
+
+// Violation is returned when an image does not comply with a rule of the
+// policy.
+type Violation struct {
+	// Rule is the name of the rule the image violates.
+	Rule string
+	// Ref is the reference or ID the image was requested by.
+	Ref string
+	// Reason explains why the image violates the rule.
+	Reason string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("admission policy violation for %s: rule %s: %s", v.Ref, v.Rule, v.Reason)
+}
+
+// Evaluate checks img against the rules of the policy at the time now, and
+// returns the Violation of the first rule it does not comply with.
+func (p *Policy) Evaluate(img Image, now time.Time) error {
+	for _, check := range []func(Image, time.Time) (string, string){
+		p.checkDeniedDigests,
+		p.checkAllowedRegistries,
+		p.checkRequiredLabels,
+		p.checkDeniedLabels,
+		p.checkMaxAge,
+	} {
+		if rule, reason := check(img, now); rule != "" {
+			return Violation{Rule: rule, Ref: img.Ref, Reason: reason}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) checkDeniedDigests(img Image, now time.Time) (string, string) {
+	if len(p.DeniedDigests) == 0 {
+		return "", ""
+	}
+	denied := make(map[digest.Digest]bool)
+	for _, d := range p.DeniedDigests {
+		denied[d] = true
+	}
+	if denied[digest.Digest(img.ID)] {
+		return RuleDeniedDigests, fmt.Sprintf("the image %s is denied", img.ID)
+	}
+	for _, ref := range img.References {
+		if canonical, ok := ref.(reference.Canonical); ok && denied[canonical.Digest()] {
+			return RuleDeniedDigests, fmt.Sprintf("the image %s is denied", canonical.String())
+		}
+	}
+	if img.Config.RootFS != nil {
+		for _, diffID := range img.Config.RootFS.DiffIDs {
+			if denied[digest.Digest(diffID)] {
+				return RuleDeniedDigests, fmt.Sprintf("the layer %s of the image is denied", diffID)
+			}
+		}
+	}
+	return "", ""
+}
+
+func (p *Policy) checkAllowedRegistries(img Image, now time.Time) (string, string) {
+	if len(p.AllowedRegistries) == 0 {
+		return "", ""
+	}
+	if img.Resolved != nil {
+		if p.allowed(img.Resolved) {
+			return "", ""
+		}
+		return RuleAllowedRegistries, fmt.Sprintf("the repository %s is not allowed", img.Resolved.FullName())
+	}
+	for _, ref := range img.References {
+		if p.allowed(ref) {
+			return "", ""
+		}
+	}
+	return RuleAllowedRegistries, "the image has no reference in an allowed registry"
+}
+
+// allowed returns whether the repository of ref is in one of the allowed
+// scopes.
+func (p *Policy) allowed(ref reference.Named) bool {
+	name := ref.FullName()
+	for _, scope := range p.AllowedRegistries {
+		if name == scope || strings.HasPrefix(name, scope+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) checkRequiredLabels(img Image, now time.Time) (string, string) {
+	labels := imageLabels(img)
+	for _, key := range sortedKeys(p.RequiredLabels) {
+		value, ok := labels[key]
+		if !ok {
+			return RuleRequiredLabels, fmt.Sprintf("the image has no label %s", key)
+		}
+		if required := p.RequiredLabels[key]; required != "" && value != required {
+			return RuleRequiredLabels, fmt.Sprintf("the label %s of the image is %q, not %q", key, value, required)
+		}
+	}
+	return "", ""
+}
+
+func (p *Policy) checkDeniedLabels(img Image, now time.Time) (string, string) {
+	labels := imageLabels(img)
+	for _, key := range sortedKeys(p.DeniedLabels) {
+		value, ok := labels[key]
+		if !ok {
+			continue
+		}
+		if denied := p.DeniedLabels[key]; denied == "" || value == denied {
+			return RuleDeniedLabels, fmt.Sprintf("the label %s=%s of the image is denied", key, value)
+		}
+	}
+	return "", ""
+}
+
+func (p *Policy) checkMaxAge(img Image, now time.Time) (string, string) {
+	if p.maxAge == 0 {
+		return "", ""
+	}
+	if age := now.Sub(img.Config.Created); age > p.maxAge {
+		return RuleMaxAge, fmt.Sprintf("the image was created %s, more than %s ago", img.Config.Created.UTC().Format(time.RFC3339), p.MaxAge)
+	}
+	return "", ""
+}
+
+func imageLabels(img Image) map[string]string {
+	if img.Config.Config == nil {
+		return nil
+	}
+	return img.Config.Config.Labels
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Controller enforces the policy of a file, which can be loaded again.
+type Controller struct {
+	path string
+
+	mu     sync.RWMutex
+	policy *Policy
+}
+
+// NewController returns a Controller enforcing the policy file at path.
+func NewController(path string) (*Controller, error) {
+	c := &Controller{path: path}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload loads the policy file again. The current policy is kept if the
+// file is not valid.
+func (c *Controller) Reload() error {
+	policy, err := LoadPolicy(c.path)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.policy = policy
+	c.mu.Unlock()
+	return nil
+}
+
+// Admit checks img against the current policy.
+func (c *Controller) Admit(img Image) error {
+	c.mu.RLock()
+	policy := c.policy
+	c.mu.RUnlock()
+	return policy.Evaluate(img, time.Now())
+}
@@ -0,0 +1,153 @@
+package admission
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/reference"
+)
+
+const (
+	baseLayer = "sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"
+	appLayer  = "sha256:9ea3a6d2e5e3c4e93b3d6f5a0ff0e3c3e64c1ff0bdc3b6f3bd6d3f2cc8d5b4a1"
+)
+
+func newTestImage(t *testing.T, ref string, refs ...string) Image {
+	img, err := image.NewFromJSON([]byte(`{
+		"created": "2016-04-01T10:00:00Z",
+		"config": {"Labels": {"com.example.team": "web", "com.example.stage": "test"}},
+		"rootfs": {"type": "layers", "diff_ids": ["` + baseLayer + `", "` + appLayer + `"]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai := Image{Ref: ref, ID: image.ID(digest.FromBytes(img.RawJSON())), Config: img}
+	for _, r := range refs {
+		named, err := reference.ParseNamed(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r == ref {
+			ai.Resolved = named
+		}
+		ai.References = append(ai.References, named)
+	}
+	return ai
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	now := time.Date(2016, 5, 1, 10, 0, 0, 0, time.UTC)
+	img := newTestImage(t, "registry.example.com/web/app:1.0", "registry.example.com/web/app:1.0")
+
+	cases := []struct {
+		policy       Policy
+		img          Image
+		expectedRule string
+	}{
+		{Policy{}, img, ""},
+		{Policy{AllowedRegistries: []string{"registry.example.com"}}, img, ""},
+		{Policy{AllowedRegistries: []string{"registry.example.com/web"}}, img, ""},
+		{Policy{AllowedRegistries: []string{"registry.example.com/we"}}, img, RuleAllowedRegistries},
+		{Policy{AllowedRegistries: []string{"docker.io"}}, img, RuleAllowedRegistries},
+		{Policy{AllowedRegistries: []string{"registry.example.com"}}, newTestImage(t, "0123456789ab", "registry.example.com/web/app:1.0"), ""},
+		{Policy{AllowedRegistries: []string{"registry.example.com"}}, newTestImage(t, "0123456789ab"), RuleAllowedRegistries},
+		{Policy{RequiredLabels: map[string]string{"com.example.team": ""}}, img, ""},
+		{Policy{RequiredLabels: map[string]string{"com.example.team": "db"}}, img, RuleRequiredLabels},
+		{Policy{RequiredLabels: map[string]string{"com.example.approved": ""}}, img, RuleRequiredLabels},
+		{Policy{DeniedLabels: map[string]string{"com.example.stage": "prod"}}, img, ""},
+		{Policy{DeniedLabels: map[string]string{"com.example.stage": "test"}}, img, RuleDeniedLabels},
+		{Policy{DeniedLabels: map[string]string{"com.example.team": ""}}, img, RuleDeniedLabels},
+		{Policy{DeniedDigests: []digest.Digest{baseLayer}}, img, RuleDeniedDigests},
+		{Policy{DeniedDigests: []digest.Digest{digest.Digest(img.ID)}}, img, RuleDeniedDigests},
+		{Policy{MaxAge: "720h"}, img, ""},
+		{Policy{MaxAge: "240h"}, img, RuleMaxAge},
+		{Policy{DeniedDigests: []digest.Digest{appLayer}, MaxAge: "240h"}, img, RuleDeniedDigests},
+	}
+	for i, c := range cases {
+		if err := c.policy.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		err := c.policy.Evaluate(c.img, now)
+		if c.expectedRule == "" {
+			if err != nil {
+				t.Errorf("%d: expected %s to be admitted, got %v", i, c.img.Ref, err)
+			}
+			continue
+		}
+		v, ok := err.(Violation)
+		if !ok || v.Rule != c.expectedRule || v.Ref != c.img.Ref {
+			t.Errorf("%d: expected a violation of rule %s for %s, got %v", i, c.expectedRule, c.img.Ref, err)
+		}
+	}
+}
+
+func TestPolicyDeniedRepositoryDigest(t *testing.T) {
+	d := "sha256:0123456789012345678901234567890123456789012345678901234567890123"
+	img := newTestImage(t, "0123456789ab", "registry.example.com/web/app@"+d)
+	p := Policy{DeniedDigests: []digest.Digest{digest.Digest(d)}}
+	if err := p.Evaluate(img, time.Now()); err == nil {
+		t.Fatal("Expected the repository digest to be denied")
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "admission-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+
+	for _, invalid := range []string{
+		`{"allowedRegistries": ["docker.io/"]}`,
+		`{"deniedDigests": ["sha256:abc"]}`,
+		`{"maxAge": "30d"}`,
+		`{"maxAge": "-1h"}`,
+		`{"requiredLabels": ["com.example.team"]}`,
+	} {
+		if err := ioutil.WriteFile(path, []byte(invalid), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Fatalf("Expected an error loading the policy %s", invalid)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"maxAge": "240h"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewController(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := newTestImage(t, "busybox")
+	img.Config.Created = time.Now().Add(-48 * time.Hour)
+	if err := c.Admit(img); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"maxAge": "24h"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Admit(img); err == nil {
+		t.Fatal("Expected the reloaded policy to reject the image")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"maxAge": "1y"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err == nil {
+		t.Fatal("Expected an error reloading an invalid policy")
+	}
+	if err := c.Admit(img); err == nil {
+		t.Fatal("Expected the previous policy to be kept")
+	}
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"github.com/Sirupsen/logrus"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/docker/engine-api/types/events"
+)
+
+// AuthZPolicy returns the built-in authorization plugin enforcing the policy
+// file of the daemon, or nil if it has none.
+func (daemon *Daemon) AuthZPolicy() authorization.PolicyPlugin {
+	return daemon.authzPolicy
+}
+
+// ReloadAuthZPolicy loads the policy file of the built-in authorization
+// plugin again. The current policy is kept if the file is not valid.
+func (daemon *Daemon) ReloadAuthZPolicy() error {
+	if daemon.authzPolicy == nil {
+		return derr.ErrorCodeNoAuthZPolicy
+	}
+	if err := daemon.authzPolicy.Reload(); err != nil {
+		return err
+	}
+	logrus.Info("Reloaded the authorization policy")
+	return nil
+}
+
+// authzPolicyBackend provides the built-in authorization plugin with the
+// labels of the containers, and logs its decisions as events.
+type authzPolicyBackend struct {
+	daemon *Daemon
+}
+
+func (b authzPolicyBackend) ContainerLabels(name string) (map[string]string, error) {
+	container, err := b.daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+	return container.Config.Labels, nil
+}
+
+// LogAuthZDecision generates an allow or deny event for the user of a
+// request, with the request and the reason for the decision as attributes.
+func (b authzPolicyBackend) LogAuthZDecision(authReq *authorization.Request, allow bool, msg string) {
+	action := "deny"
+	if allow {
+		action = "allow"
+	}
+	attributes := map[string]string{
+		"method": authReq.RequestMethod,
+		"uri":    authReq.RequestURI,
+		"reason": msg,
+	}
+	if authReq.UserAuthNMethod != "" {
+		attributes["authn"] = authReq.UserAuthNMethod
+	}
+	actor := events.Actor{
+		ID:         authReq.User,
+		Attributes: attributes,
+	}
+	b.daemon.EventsService.Log(action, events.AuthZEventType, actor)
+}
@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
+)
+
+var validCheckpointName = regexp.MustCompile(`^` + utils.RestrictedNameChars + `+$`)
+
+// CheckpointCreate checkpoints the process of a running container to disk.
+// Unless config.Exit is set, the container keeps running.
+func (daemon *Daemon) CheckpointCreate(name string, config types.CheckpointCreateOptions) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	return daemon.containerCheckpoint(container, name, config)
+}
+
+// containerCheckpoint checkpoints the process of container, holding its lock
+// so that it is not stopped, paused or restarted meanwhile.
+func (daemon *Daemon) containerCheckpoint(container *container.Container, name string, config types.CheckpointCreateOptions) error {
+	container.Lock()
+	defer container.Unlock()
+
+	if !container.Running {
+		return derr.ErrorCodeNotRunning.WithArgs(name)
+	}
+	if container.Paused {
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, "the container is paused")
+	}
+	if container.Config.Tty {
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, "containers with a TTY are not supported")
+	}
+
+	dir, err := checkpointDir(container, config.CheckpointID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return derr.ErrorCodeCheckpointExists.WithArgs(config.CheckpointID)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if config.Exit {
+		// the process is killed once it is checkpointed, make sure it is
+		// not restarted by the restart policy, as when it is stopped
+		container.ExitOnNext()
+	}
+	opts := &execdriver.CheckpointOptions{
+		ImagesDirectory: dir,
+		WorkDirectory:   dir,
+		LeaveRunning:    !config.Exit,
+	}
+	if err := daemon.execDriver.Checkpoint(container.Command, opts); err != nil {
+		if config.Exit {
+			// the process was not checkpointed and keeps running
+			container.CancelExitOnNext()
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			logrus.Warnf("Failed to remove checkpoint %s of container %s: %v", config.CheckpointID, container.ID, err)
+		}
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, utils.GetErrorMessage(err))
+	}
+
+	daemon.LogContainerEventWithAttributes(container, "checkpoint", map[string]string{
+		"checkpoint": config.CheckpointID,
+	})
+	return nil
+}
+
+// CheckpointDelete removes a checkpoint of a container.
+func (daemon *Daemon) CheckpointDelete(name, checkpointID string) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	dir, err := existingCheckpointDir(container, checkpointID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// CheckpointList returns the checkpoints of a container.
+func (daemon *Daemon) CheckpointList(name string) ([]types.Checkpoint, error) {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+	root, err := container.CheckpointDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	checkpoints := []types.Checkpoint{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		checkpoints = append(checkpoints, types.Checkpoint{
+			Name:    e.Name(),
+			Created: e.ModTime().Unix(),
+		})
+	}
+	return checkpoints, nil
+}
+
+// checkpointDir returns the directory of the checkpoint of container named
+// checkpointID.
+func checkpointDir(container *container.Container, checkpointID string) (string, error) {
+	if !validCheckpointName.MatchString(checkpointID) {
+		return "", derr.ErrorCodeCheckpointName.WithArgs(checkpointID, utils.RestrictedNameChars)
+	}
+	root, err := container.CheckpointDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, checkpointID), nil
+}
+
+// existingCheckpointDir returns the directory of the checkpoint of container
+// named checkpointID, which must exist.
+func existingCheckpointDir(container *container.Container, checkpointID string) (string, error) {
+	dir, err := checkpointDir(container, checkpointID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return "", derr.ErrorCodeNoSuchCheckpoint.WithArgs(checkpointID)
+		}
+		return "", err
+	}
+	return dir, nil
+}
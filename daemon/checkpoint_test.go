@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// checkpointDriver is an execdriver.Driver which only implements Checkpoint.
+type checkpointDriver struct {
+	execdriver.Driver
+	opts *execdriver.CheckpointOptions
+	err  error
+}
+
+func (d *checkpointDriver) Checkpoint(c *execdriver.Command, opts *execdriver.CheckpointOptions) error {
+	d.opts = opts
+	return d.err
+}
+
+func newCheckpointTestDaemon(root string) (*Daemon, *container.Container) {
+	daemon := &Daemon{
+		repository:    root,
+		root:          root,
+		EventsService: events.New(),
+	}
+	daemon.containers = &contStore{s: make(map[string]*container.Container)}
+
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "test",
+			Root:   root,
+			State:  container.NewState(),
+			Config: &containertypes.Config{},
+		},
+	}
+	daemon.containers.Add(c.ID, c)
+	return daemon, c
+}
+
+func TestCheckpointCreate(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-daemon-checkpoint-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	daemon, c := newCheckpointTestDaemon(tmp)
+	driver := &checkpointDriver{}
+	daemon.execDriver = driver
+
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "cp1"}); err == nil {
+		t.Fatal("expected an error checkpointing a stopped container")
+	}
+
+	c.Running = true
+	c.Paused = true
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "cp1"}); err == nil {
+		t.Fatal("expected an error checkpointing a paused container")
+	}
+	c.Paused = false
+
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "../cp1"}); err == nil {
+		t.Fatal("expected an error for an invalid checkpoint name")
+	}
+
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "cp1"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmp, "checkpoints", "cp1")
+	if driver.opts == nil || driver.opts.ImagesDirectory != dir || !driver.opts.LeaveRunning {
+		t.Fatalf("expected a checkpoint to %s leaving the container running, got %+v", dir, driver.opts)
+	}
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "cp1"}); err == nil {
+		t.Fatal("expected an error for an existing checkpoint")
+	}
+
+	// a failed checkpoint is removed
+	driver.err = errors.New("criu failed")
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "cp2"}); err == nil {
+		t.Fatal("expected the error of the driver")
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "checkpoints", "cp2")); !os.IsNotExist(err) {
+		t.Fatalf("expected the failed checkpoint to be removed, got %v", err)
+	}
+}
+
+func TestContainerStartCheckpoint(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-daemon-checkpoint-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	daemon, c := newCheckpointTestDaemon(tmp)
+
+	if err := daemon.containerStart(c, "../cp1"); err == nil {
+		t.Fatal("expected an error for an invalid checkpoint name")
+	}
+	if err := daemon.containerStart(c, "cp1"); err == nil {
+		t.Fatal("expected an error restoring a missing checkpoint")
+	}
+	if c.Running {
+		t.Fatal("expected the container not to be started")
+	}
+}
+
+func TestCheckpointListAndDelete(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-daemon-checkpoint-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	daemon, c := newCheckpointTestDaemon(tmp)
+
+	checkpoints, err := daemon.CheckpointList(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 0 {
+		t.Fatalf("expected no checkpoints, got %v", checkpoints)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmp, "checkpoints", "cp1"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	checkpoints, err = daemon.CheckpointList(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].Name != "cp1" {
+		t.Fatalf("expected checkpoint cp1, got %v", checkpoints)
+	}
+
+	if err := daemon.CheckpointDelete(c.ID, "../test"); err == nil {
+		t.Fatal("expected an error for an invalid checkpoint name")
+	}
+	if err := daemon.CheckpointDelete(c.ID, "cp1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.CheckpointDelete(c.ID, "cp1"); err == nil {
+		t.Fatal("expected an error removing a missing checkpoint")
+	}
+}
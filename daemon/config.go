@@ -15,6 +15,7 @@ const (
 // common across platforms.
 type CommonConfig struct {
 	AuthZPlugins  []string // AuthZPlugins holds list of authorization plugins
+	AuthZPolicy   string   // AuthZPolicy holds the policy file of the built-in authorization plugin
 	AutoRestart   bool
 	Bridge        bridgeConfig // Bridge holds bridge network specific configuration.
 	Context       map[string][]string
@@ -33,6 +34,7 @@ type CommonConfig struct {
 	RemappedRoot  string
 	Root          string
 	TrustKeyPath  string
+	TrustPolicy   string
 
 	// ClusterStore is the storage backend used for the cluster information. It is used by both
 	// multihost networking (to store networks and endpoints information) and by the node discovery
@@ -47,6 +49,28 @@ type CommonConfig struct {
 	// discovery. This should be a 'host:port' combination on which that daemon instance is
 	// reachable by other hosts.
 	ClusterAdvertise string
+
+	// MetricsAddress is the TCP address on which the daemon metrics are
+	// exposed in the Prometheus text format. They are not exposed if it is
+	// empty.
+	MetricsAddress string
+
+	// AdmissionPolicy is the policy file the images of the containers
+	// created must comply with. Images are not checked if it is empty.
+	AdmissionPolicy string
+
+	// SecretsKeyPath is the file of the key encrypting the secrets of the
+	// daemon. It is kept out of the root of the daemon, so that the backups
+	// of the root do not hold both the secrets and their key.
+	SecretsKeyPath string
+
+	// AuditLog is the destination of the audit log of the mutating API
+	// requests, a file or syslog. There is no audit log if it is empty.
+	AuditLog string
+
+	// AuditLogOpts holds the options of the audit log, such as the rotation
+	// of the file and the audited route classes.
+	AuditLogOpts map[string]string
 }
 
 // InstallCommonFlags adds command-line options to the top-level flag parser for
@@ -56,6 +80,7 @@ type CommonConfig struct {
 func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string) string) {
 	cmd.Var(opts.NewListOptsRef(&config.GraphOptions, nil), []string{"-storage-opt"}, usageFn("Set storage driver options"))
 	cmd.Var(opts.NewListOptsRef(&config.AuthZPlugins, nil), []string{"-authz-plugin"}, usageFn("List authorization plugins in order from first evaluator to last"))
+	cmd.StringVar(&config.AuthZPolicy, []string{"-authz-policy"}, "", usageFn("Authorization policy file of the built-in authorization plugin"))
 	cmd.Var(opts.NewListOptsRef(&config.ExecOptions, nil), []string{"-exec-opt"}, usageFn("Set exec driver options"))
 	cmd.StringVar(&config.Pidfile, []string{"p", "-pidfile"}, defaultPidFile, usageFn("Path to use for daemon PID file"))
 	cmd.StringVar(&config.Root, []string{"g", "-graph"}, defaultGraph, usageFn("Root of the Docker runtime"))
@@ -73,4 +98,10 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.StringVar(&config.ClusterAdvertise, []string{"-cluster-advertise"}, "", usageFn("Address or interface name to advertise"))
 	cmd.StringVar(&config.ClusterStore, []string{"-cluster-store"}, "", usageFn("Set the cluster store"))
 	cmd.Var(opts.NewMapOpts(config.ClusterOpts, nil), []string{"-cluster-store-opt"}, usageFn("Set cluster store options"))
+	cmd.StringVar(&config.TrustPolicy, []string{"-trust-policy"}, "", usageFn("Trust policy file for image signatures"))
+	cmd.StringVar(&config.AdmissionPolicy, []string{"-admission-policy"}, "", usageFn("Admission policy file for the images of new containers"))
+	cmd.StringVar(&config.MetricsAddress, []string{"-metrics-addr"}, "", usageFn("Set address and port to serve the metrics API on"))
+	cmd.StringVar(&config.SecretsKeyPath, []string{"-secrets-key"}, "", usageFn("Path to the key encrypting the secrets, out of the root of the daemon"))
+	cmd.StringVar(&config.AuditLog, []string{"-audit-log"}, "", usageFn("Audit log of the mutating API requests, a file path or syslog"))
+	cmd.Var(opts.NewMapOpts(config.AuditLogOpts, nil), []string{"-audit-log-opt"}, usageFn("Set audit log options"))
 }
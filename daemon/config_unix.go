@@ -4,6 +4,7 @@ package daemon
 
 import (
 	"net"
+	"time"
 
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
@@ -27,9 +28,17 @@ type Config struct {
 	EnableCors           bool
 	EnableSelinuxSupport bool
 	RemappedRoot         string
+	UsernsPrivateRanges  int
 	SocketGroup          string
 	CgroupParent         string
 	Ulimits              map[string]*units.Ulimit
+	Init                 bool
+	ReadonlyTmpfsPaths   []string
+	PauseStopPolicy      string
+	PauseTimeout         time.Duration
+	NoNewPrivileges      bool
+	DefaultCapabilities  []string
+	DefaultPidsLimit     int64
 }
 
 // bridgeConfig stores all the bridge driver specific
@@ -63,6 +72,9 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	cmd.StringVar(&config.SocketGroup, []string{"G", "-group"}, "docker", usageFn("Group for the unix socket"))
 	config.Ulimits = make(map[string]*units.Ulimit)
 	cmd.Var(runconfigopts.NewUlimitOpt(&config.Ulimits), []string{"-default-ulimit"}, usageFn("Set default ulimits for containers"))
+	cmd.BoolVar(&config.NoNewPrivileges, []string{"-no-new-privileges"}, false, usageFn("Keep the processes of containers from gaining privileges by default"))
+	cmd.Var(opts.NewListOptsRef(&config.DefaultCapabilities, nil), []string{"-default-capability"}, usageFn("Default capability set for containers"))
+	cmd.Int64Var(&config.DefaultPidsLimit, []string{"-default-pids-limit"}, 0, usageFn("Default pids limit for containers, -1 for unlimited"))
 	cmd.BoolVar(&config.Bridge.EnableIPTables, []string{"#iptables", "-iptables"}, true, usageFn("Enable addition of iptables rules"))
 	cmd.BoolVar(&config.Bridge.EnableIPForward, []string{"#ip-forward", "-ip-forward"}, true, usageFn("Enable net.ipv4.ip_forward"))
 	cmd.BoolVar(&config.Bridge.EnableIPMasq, []string{"-ip-masq"}, true, usageFn("Enable IP masquerading"))
@@ -80,6 +92,11 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	cmd.StringVar(&config.CorsHeaders, []string{"-api-cors-header"}, "", usageFn("Set CORS headers in the remote API"))
 	cmd.StringVar(&config.CgroupParent, []string{"-cgroup-parent"}, "", usageFn("Set parent cgroup for all containers"))
 	cmd.StringVar(&config.RemappedRoot, []string{"-userns-remap"}, "", usageFn("User/Group setting for user namespaces"))
+	cmd.IntVar(&config.UsernsPrivateRanges, []string{"-userns-private-ranges"}, 0, usageFn("Number of subordinate ID ranges set aside for private user namespaces"))
+	cmd.BoolVar(&config.Init, []string{"-init"}, false, usageFn("Run an init in containers to forward signals and reap processes"))
+	cmd.Var(opts.NewListOptsRef(&config.ReadonlyTmpfsPaths, runconfigopts.ValidateTmpfs), []string{"-read-only-tmpfs-path"}, usageFn("Default tmpfs mounts for containers run with --read-only-tmpfs"))
+	cmd.StringVar(&config.PauseStopPolicy, []string{"-pause-stop-policy"}, pauseStopThaw, usageFn("How paused containers are stopped (thaw, kill)"))
+	cmd.DurationVar(&config.PauseTimeout, []string{"-pause-timeout"}, 0, usageFn("Resume paused containers after this duration, 0 to never resume them"))
 
 	config.attachExperimentalFlags(cmd, usageFn)
 }
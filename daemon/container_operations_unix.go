@@ -4,7 +4,9 @@ package daemon
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -24,6 +26,9 @@ import (
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/runconfig"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/docker/utils"
+	"github.com/docker/docker/volume"
 	containertypes "github.com/docker/engine-api/types/container"
 	networktypes "github.com/docker/engine-api/types/network"
 	"github.com/docker/go-units"
@@ -128,21 +133,11 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 		HostUTS: c.HostConfig.UTSMode.IsHost(),
 	}
 
-	// Build lists of devices allowed and created within the container.
-	var userSpecifiedDevices []*configs.Device
-	for _, deviceMapping := range c.HostConfig.Devices {
-		devs, err := getDevicesFromPath(deviceMapping)
-		if err != nil {
-			return err
-		}
-
-		userSpecifiedDevices = append(userSpecifiedDevices, devs...)
+	allowedDevices, autoCreatedDevices, err := getDevices(&c.HostConfig.Resources)
+	if err != nil {
+		return err
 	}
 
-	allowedDevices := mergeDevices(configs.DefaultAllowedDevices, userSpecifiedDevices)
-
-	autoCreatedDevices := mergeDevices(configs.DefaultAutoCreatedDevices, userSpecifiedDevices)
-
 	var rlimits []*units.Rlimit
 	ulimits := c.HostConfig.Ulimits
 
@@ -211,6 +206,8 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 		BlkioThrottleWriteIOpsDevice: writeIOpsDevice,
 		OomKillDisable:               *c.HostConfig.OomKillDisable,
 		MemorySwappiness:             -1,
+		PidsLimit:                    c.HostConfig.PidsLimit,
+		NetworkRateLimits:            getNetworkRateLimits(c.HostConfig.NetworkRateLimits),
 	}
 
 	if c.HostConfig.MemorySwappiness != nil {
@@ -231,12 +228,17 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 	processConfig.Env = env
 
 	remappedRoot := &execdriver.User{}
-	rootUID, rootGID := daemon.GetRemappedUIDGID()
+	rootUID, rootGID := daemon.containerRootUIDGID(c)
 	if rootUID != 0 {
 		remappedRoot.UID = rootUID
 		remappedRoot.GID = rootGID
 	}
-	uidMap, gidMap := daemon.GetUIDGIDMaps()
+	uidMap, gidMap := daemon.containerIDMaps(c)
+
+	rootfs, err := c.RootfsPath()
+	if err != nil {
+		return err
+	}
 
 	if !daemon.seccompEnabled {
 		if c.SeccompProfile != "" && c.SeccompProfile != "unconfined" {
@@ -268,7 +270,7 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 			Network:       en,
 			ProcessConfig: processConfig,
 			ProcessLabel:  c.GetProcessLabel(),
-			Rootfs:        c.BaseFS,
+			Rootfs:        rootfs,
 			Resources:     resources,
 			WorkingDir:    c.Config.WorkingDir,
 		},
@@ -280,22 +282,118 @@ func (daemon *Daemon) populateCommand(c *container.Container, env []string) erro
 		CgroupParent:       defaultCgroupParent,
 		GIDMapping:         gidMap,
 		GroupAdd:           c.HostConfig.GroupAdd,
+		Capabilities:       c.HostConfig.Capabilities,
 		Ipc:                ipc,
+		NoNewPrivileges:    c.NoNewPrivileges,
 		OomScoreAdj:        c.HostConfig.OomScoreAdj,
 		Pid:                pid,
 		ReadonlyRootfs:     c.HostConfig.ReadonlyRootfs,
 		RemappedRoot:       remappedRoot,
 		SeccompProfile:     c.SeccompProfile,
+		Sysctls:            c.HostConfig.Sysctls,
 		UIDMapping:         uidMap,
 		UTS:                uts,
 	}
 	if c.HostConfig.CgroupParent != "" {
 		c.Command.CgroupParent = c.HostConfig.CgroupParent
 	}
+	if daemon.useInit(c) {
+		initBinary, err := lookupInitBinary()
+		if err != nil {
+			return err
+		}
+		c.Command.InitBinary = initBinary
+	}
 
 	return nil
 }
 
+// useInit returns whether the process of container c is run under an init,
+// as set in its host config or else by the daemon default.
+func (daemon *Daemon) useInit(c *container.Container) bool {
+	if c.HostConfig.Init != nil {
+		return *c.HostConfig.Init
+	}
+	return daemon.configStore.Init
+}
+
+// initBinaryName is the name of the binary run as PID 1 of containers run
+// under an init.
+const initBinaryName = "docker-init"
+
+// lookupInitBinary returns the path of the init binary, which is installed
+// next to the daemon binary, in the PATH or in the libexec directory of
+// docker.
+func lookupInitBinary() (string, error) {
+	candidates := []string{
+		filepath.Join(filepath.Dir(utils.SelfPath()), initBinaryName),
+		initBinaryName,
+		filepath.Join("/usr/libexec/docker", initBinaryName),
+		filepath.Join("/usr/local/libexec/docker", initBinaryName),
+		filepath.Join("/usr/lib/docker", initBinaryName),
+		filepath.Join("/usr/local/lib/docker", initBinaryName),
+	}
+	for _, candidate := range candidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return filepath.Abs(path)
+		}
+	}
+	return "", derr.ErrorCodeNoInit
+}
+
+// defaultReadonlyTmpfsPaths are the paths at which a tmpfs is mounted for
+// containers run with --read-only-tmpfs, unless the daemon is configured with
+// --read-only-tmpfs-path.
+var defaultReadonlyTmpfsPaths = []string{"/tmp", "/run", "/var/cache"}
+
+// readonlyTmpfsMounts returns the tmpfs mounts to add to container c if its
+// root filesystem is read-only and it asked for ReadonlyTmpfs. Destinations
+// at or above the destination of one of mounts are skipped, so a volume or an
+// explicit --tmpfs always takes precedence over the daemon defaults, and is
+// not hidden by a tmpfs mounted over it.
+func (daemon *Daemon) readonlyTmpfsMounts(c *container.Container, mounts []execdriver.Mount) []execdriver.Mount {
+	if !c.HostConfig.ReadonlyRootfs || !c.HostConfig.ReadonlyTmpfs {
+		return nil
+	}
+	paths := daemon.configStore.ReadonlyTmpfsPaths
+	if len(paths) == 0 {
+		paths = defaultReadonlyTmpfsPaths
+	}
+
+	var tmpfs []execdriver.Mount
+	for _, p := range paths {
+		arr := strings.SplitN(p, ":", 2)
+		dest := filepath.Clean(arr[0])
+		if hasMountAtOrBelow(mounts, dest) || hasMountAtOrBelow(tmpfs, dest) {
+			continue
+		}
+		m := execdriver.Mount{
+			Source:      "tmpfs",
+			Destination: dest,
+		}
+		if len(arr) > 1 {
+			m.Data = arr[1]
+		}
+		tmpfs = append(tmpfs, m)
+	}
+	return tmpfs
+}
+
+// hasMountAtOrBelow returns whether one of mounts is mounted at dest or at a
+// path below it.
+func hasMountAtOrBelow(mounts []execdriver.Mount, dest string) bool {
+	prefix := dest
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	for _, m := range mounts {
+		if d := filepath.Clean(m.Destination); d == dest || strings.HasPrefix(d, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // getSize returns the real size & virtual size of the container.
 func (daemon *Daemon) getSize(container *container.Container) (int64, int64) {
 	var (
@@ -1018,7 +1116,7 @@ func (daemon *Daemon) releaseNetwork(container *container.Container) {
 }
 
 func (daemon *Daemon) setupIpcDirs(c *container.Container) error {
-	rootUID, rootGID := daemon.GetRemappedUIDGID()
+	rootUID, rootGID := daemon.containerRootUIDGID(c)
 	if !c.HasMountFor("/dev/shm") {
 		shmPath, err := c.ShmResourcePath()
 		if err != nil {
@@ -1060,6 +1158,65 @@ func (daemon *Daemon) setupIpcDirs(c *container.Container) error {
 	return nil
 }
 
+// setupSecrets writes the secrets of container c to a tmpfs in its
+// directory, owned and with the mode set in the secret references, and
+// returns the read-only bind mounts of their files. The values of the
+// secrets thus never reach the disk nor the layer of the container.
+func (daemon *Daemon) setupSecrets(c *container.Container) ([]execdriver.Mount, error) {
+	if len(c.HostConfig.Secrets) == 0 {
+		return nil, nil
+	}
+	secretsPath, err := c.SecretsResourcePath()
+	if err != nil {
+		return nil, err
+	}
+	rootUID, rootGID := daemon.containerRootUIDGID(c)
+	if err := idtools.MkdirAllAs(secretsPath, 0700, rootUID, rootGID); err != nil {
+		return nil, err
+	}
+	if err := syscall.Mount("secrets", secretsPath, "tmpfs", uintptr(syscall.MS_NOEXEC|syscall.MS_NOSUID|syscall.MS_NODEV), label.FormatMountLabel("mode=0700", c.GetMountLabel())); err != nil {
+		return nil, fmt.Errorf("mounting secrets tmpfs: %s", err)
+	}
+	if err := os.Chown(secretsPath, rootUID, rootGID); err != nil {
+		return nil, err
+	}
+
+	uidMaps, gidMaps := daemon.containerIDMaps(c)
+	var mounts []execdriver.Mount
+	for i, ref := range c.HostConfig.Secrets {
+		data, err := daemon.secrets.Data(ref.Name)
+		if err != nil {
+			return nil, secretError(err, ref.Name)
+		}
+		uid, err := idtools.ToHost(ref.UID, uidMaps)
+		if err != nil {
+			return nil, err
+		}
+		gid, err := idtools.ToHost(ref.GID, gidMaps)
+		if err != nil {
+			return nil, err
+		}
+
+		p := filepath.Join(secretsPath, strconv.Itoa(i))
+		if err := ioutil.WriteFile(p, data, 0600); err != nil {
+			return nil, err
+		}
+		if err := os.Chown(p, uid, gid); err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(p, secretMode(ref)); err != nil {
+			return nil, err
+		}
+		label.SetFileLabel(p, c.MountLabel)
+		mounts = append(mounts, execdriver.Mount{
+			Source:      p,
+			Destination: secretTarget(ref),
+			Propagation: volume.DefaultPropagationMode,
+		})
+	}
+	return mounts, nil
+}
+
 func (daemon *Daemon) mountVolumes(container *container.Container) error {
 	mounts, err := daemon.setupMounts(container)
 	if err != nil {
@@ -1110,6 +1267,81 @@ func killProcessDirectly(container *container.Container) error {
 	return nil
 }
 
+// getDevices builds the lists of devices allowed and created within a
+// container with the given resources.
+func getDevices(resources *containertypes.Resources) (allowed, autoCreated []*configs.Device, err error) {
+	var userSpecifiedDevices []*configs.Device
+	for _, deviceMapping := range resources.Devices {
+		devs, err := getDevicesFromPath(deviceMapping)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		userSpecifiedDevices = append(userSpecifiedDevices, devs...)
+	}
+
+	rules, err := getDeviceCgroupRules(resources.DeviceCgroupRules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allowed = append(mergeDevices(configs.DefaultAllowedDevices, userSpecifiedDevices), rules...)
+	autoCreated = mergeDevices(configs.DefaultAutoCreatedDevices, userSpecifiedDevices)
+	return allowed, autoCreated, nil
+}
+
+// getDeviceCgroupRules parses device cgroup rules into the devices they
+// allow access to, with the major or minor number set to configs.Wildcard
+// where the rule has a '*'.
+func getDeviceCgroupRules(rules []string) ([]*configs.Device, error) {
+	var devs []*configs.Device
+	for _, rule := range rules {
+		m := runconfigopts.DeviceCgroupRuleRegexp.FindStringSubmatch(rule)
+		if m == nil {
+			return nil, derr.ErrorCodeDeviceCgroupRule.WithArgs(rule)
+		}
+		dev := &configs.Device{
+			Type:        rune(m[1][0]),
+			Major:       configs.Wildcard,
+			Minor:       configs.Wildcard,
+			Permissions: m[4],
+		}
+		if m[2] != "*" {
+			major, err := strconv.ParseInt(m[2], 10, 64)
+			if err != nil {
+				return nil, derr.ErrorCodeDeviceCgroupRule.WithArgs(rule)
+			}
+			dev.Major = major
+		}
+		if m[3] != "*" {
+			minor, err := strconv.ParseInt(m[3], 10, 64)
+			if err != nil {
+				return nil, derr.ErrorCodeDeviceCgroupRule.WithArgs(rule)
+			}
+			dev.Minor = minor
+		}
+		devs = append(devs, dev)
+	}
+	return devs, nil
+}
+
+// getNetworkRateLimits converts the rate limits of the host config into the
+// ones of the exec driver, leaving out the interfaces that are not limited.
+func getNetworkRateLimits(limits []containertypes.NetworkRateLimit) []execdriver.NetworkRateLimit {
+	var rateLimits []execdriver.NetworkRateLimit
+	for _, l := range limits {
+		if l.EgressRate == 0 && l.IngressRate == 0 {
+			continue
+		}
+		rateLimits = append(rateLimits, execdriver.NetworkRateLimit{
+			Interface:   l.Interface,
+			EgressRate:  l.EgressRate,
+			IngressRate: l.IngressRate,
+		})
+	}
+	return rateLimits
+}
+
 func getDevicesFromPath(deviceMapping containertypes.DeviceMapping) (devs []*configs.Device, err error) {
 	device, err := devices.DeviceFromPath(deviceMapping.PathOnHost, deviceMapping.CgroupPermissions)
 	// if there was no error, return the device
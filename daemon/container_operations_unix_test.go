@@ -0,0 +1,73 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestReadonlyTmpfsMounts(t *testing.T) {
+	daemon := &Daemon{configStore: &Config{}}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			HostConfig: &containertypes.HostConfig{ReadonlyTmpfs: true},
+		},
+	}
+	if mounts := daemon.readonlyTmpfsMounts(c, nil); len(mounts) != 0 {
+		t.Fatalf("Expected no tmpfs mounts for a writable rootfs, got %v", mounts)
+	}
+
+	c.HostConfig.ReadonlyRootfs = true
+	mounts := []execdriver.Mount{{Source: "/var/lib/docker/volumes/cache", Destination: "/var/cache/"}}
+	expected := []execdriver.Mount{
+		{Source: "tmpfs", Destination: "/tmp"},
+		{Source: "tmpfs", Destination: "/run"},
+	}
+	if tmpfs := daemon.readonlyTmpfsMounts(c, mounts); !reflect.DeepEqual(tmpfs, expected) {
+		t.Fatalf("Expected tmpfs mounts %v, got %v", expected, tmpfs)
+	}
+
+	// a volume nested in a default path is not hidden by a tmpfs
+	mounts = []execdriver.Mount{
+		{Source: "/var/lib/docker/volumes/data", Destination: "/run/data"},
+		{Source: "tmpfs", Destination: "/var/cache/apt"},
+		{Source: "/var/lib/docker/volumes/tmp", Destination: "/tmpdata"},
+	}
+	expected = []execdriver.Mount{{Source: "tmpfs", Destination: "/tmp"}}
+	if tmpfs := daemon.readonlyTmpfsMounts(c, mounts); !reflect.DeepEqual(tmpfs, expected) {
+		t.Fatalf("Expected tmpfs mounts %v, got %v", expected, tmpfs)
+	}
+
+	daemon.configStore.ReadonlyTmpfsPaths = []string{"/tmp:size=16m", "/var/log"}
+	expected = []execdriver.Mount{
+		{Source: "tmpfs", Destination: "/tmp", Data: "size=16m"},
+		{Source: "tmpfs", Destination: "/var/log"},
+	}
+	if tmpfs := daemon.readonlyTmpfsMounts(c, nil); !reflect.DeepEqual(tmpfs, expected) {
+		t.Fatalf("Expected tmpfs mounts %v, got %v", expected, tmpfs)
+	}
+}
+
+func TestGetDeviceCgroupRules(t *testing.T) {
+	devs, err := getDeviceCgroupRules([]string{"c 189:* rmw", "b 8:16 r", "a *:* m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"c 189:* rmw", "b 8:16 r", "a *:* m"}
+	for i, d := range devs {
+		if d.CgroupString() != expected[i] {
+			t.Fatalf("Expected rule %q, got %q", expected[i], d.CgroupString())
+		}
+	}
+
+	for _, rule := range []string{"", "c 189 rmw", "x 1:1 r", "c 1:1 rx", "c -1:1 r"} {
+		if _, err := getDeviceCgroupRules([]string{rule}); err == nil {
+			t.Fatalf("Expected an error for the invalid rule %q", rule)
+		}
+	}
+}
@@ -14,6 +14,10 @@ import (
 	"github.com/docker/libnetwork"
 )
 
+func (daemon *Daemon) readonlyTmpfsMounts(c *container.Container, mounts []execdriver.Mount) []execdriver.Mount {
+	return nil
+}
+
 func (daemon *Daemon) setupLinkedContainers(container *container.Container) ([]string, error) {
 	return nil, nil
 }
@@ -166,6 +170,11 @@ func (daemon *Daemon) setupIpcDirs(container *container.Container) error {
 	return nil
 }
 
+// setupSecrets does nothing, secrets are not supported on Windows.
+func (daemon *Daemon) setupSecrets(container *container.Container) ([]execdriver.Mount, error) {
+	return nil, nil
+}
+
 // TODO Windows: Fix Post-TP4. This is a hack to allow docker cp to work
 // against containers which have volumes. You will still be able to cp
 // to somewhere on the container drive, but not to any mounted volumes
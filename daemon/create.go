@@ -1,6 +1,8 @@
 package daemon
 
 import (
+	"time"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	derr "github.com/docker/docker/errors"
@@ -17,6 +19,8 @@ import (
 
 // ContainerCreate creates a container.
 func (daemon *Daemon) ContainerCreate(params types.ContainerCreateConfig) (types.ContainerCreateResponse, error) {
+	defer containerActions.ObserveSince(time.Now(), "create")
+
 	if params.Config == nil {
 		return types.ContainerCreateResponse{}, derr.ErrorCodeEmptyConfig
 	}
@@ -33,6 +37,7 @@ func (daemon *Daemon) ContainerCreate(params types.ContainerCreateConfig) (types
 	if err != nil {
 		return types.ContainerCreateResponse{Warnings: warnings}, err
 	}
+	daemon.mergeHostConfigDefaults(params.HostConfig)
 
 	container, err := daemon.create(params)
 	if err != nil {
@@ -56,6 +61,12 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 		if err != nil {
 			return nil, err
 		}
+		if err := daemon.verifyImageTrust(params.Config.Image, img); err != nil {
+			return nil, err
+		}
+		if err := daemon.verifyImageAdmission(params.Config.Image, img); err != nil {
+			return nil, err
+		}
 		imgID = img.ID()
 	}
 
@@ -78,15 +89,24 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 		return nil, err
 	}
 
+	if err := daemon.setIDMaps(container, params.HostConfig); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			daemon.releaseIDMaps(container)
+		}
+	}()
+
 	// Set RWLayer for container after mount labels have been set
-	if err := daemon.setRWLayer(container); err != nil {
+	if err := daemon.setRWLayer(container, params.HostConfig); err != nil {
 		return nil, err
 	}
 
 	if err := daemon.Register(container); err != nil {
 		return nil, err
 	}
-	rootUID, rootGID, err := idtools.GetRootUIDGID(daemon.uidMaps, daemon.gidMaps)
+	rootUID, rootGID, err := idtools.GetRootUIDGID(daemon.idMaps(container, params.HostConfig))
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +161,7 @@ func (daemon *Daemon) generateSecurityOpt(ipcMode containertypes.IpcMode, pidMod
 	return nil, nil
 }
 
-func (daemon *Daemon) setRWLayer(container *container.Container) error {
+func (daemon *Daemon) setRWLayer(container *container.Container, hostConfig *containertypes.HostConfig) error {
 	var layerID layer.ChainID
 	if container.ImageID != "" {
 		img, err := daemon.imageStore.Get(container.ImageID)
@@ -150,7 +170,15 @@ func (daemon *Daemon) setRWLayer(container *container.Container) error {
 		}
 		layerID = img.RootFS.ChainID()
 	}
-	rwLayer, err := daemon.layerStore.CreateRWLayer(container.ID, layerID, container.MountLabel, daemon.setupInitLayer)
+	uidMaps, gidMaps := daemon.idMaps(container, hostConfig)
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return err
+	}
+	setupInit := func(initPath string) error {
+		return setupInitLayer(initPath, rootUID, rootGID)
+	}
+	rwLayer, err := daemon.layerStore.CreateRWLayerWithIDMaps(container.ID, layerID, container.MountLabel, setupInit, uidMaps, gidMaps)
 	if err != nil {
 		return err
 	}
@@ -23,6 +23,7 @@ import (
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/admission"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/daemon/execdriver"
@@ -37,6 +38,8 @@ import (
 	_ "github.com/docker/docker/daemon/graphdriver/register"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/daemon/secrets"
+	"github.com/docker/docker/daemon/trust"
 	"github.com/docker/docker/distribution"
 	dmetadata "github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/distribution/xfer"
@@ -46,6 +49,7 @@ import (
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/migrate/v1"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/discovery"
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/graphdb"
@@ -150,6 +154,7 @@ type Daemon struct {
 	configStore               *Config
 	execDriver                execdriver.Driver
 	statsCollector            *statsCollector
+	usageStop                 chan struct{}
 	defaultLogConfig          containertypes.LogConfig
 	RegistryService           *registry.Service
 	EventsService             *events.Events
@@ -161,8 +166,13 @@ type Daemon struct {
 	shutdown                  bool
 	uidMaps                   []idtools.IDMap
 	gidMaps                   []idtools.IDMap
+	idRanges                  *idtools.RangeAllocator
 	layerStore                layer.Store
 	imageStore                image.Store
+	trustVerifier             *trust.Verifier
+	admission                 *admission.Controller
+	authzPolicy               authorization.PolicyPlugin
+	secrets                   *secrets.Store
 	nameIndex                 *registrar.Registrar
 	linkIndex                 *linkIndex
 }
@@ -276,8 +286,9 @@ func (daemon *Daemon) Register(container *container.Container) error {
 		daemon.execDriver.Terminate(cmd)
 
 		container.UnmountIpcMounts(mount.Unmount)
+		container.UnmountSecrets(mount.Unmount)
 
-		daemon.Unmount(container)
+		daemon.conditionalUnmountOnCleanup(container)
 		if err := container.ToDiskLocking(); err != nil {
 			logrus.Errorf("Error saving stopped state to disk: %v", err)
 		}
@@ -323,6 +334,11 @@ func (daemon *Daemon) restore() error {
 		}
 		container.RWLayer = rwlayer
 
+		if err := daemon.reserveIDMaps(container); err != nil {
+			logrus.Errorf("Failed to load container %v: %v", id, err)
+			continue
+		}
+
 		// Ignore the container if it does not support the current driver being used by the graph
 		if (container.Driver == "" && currentDriver == "aufs") || container.Driver == currentDriver {
 			logrus.Debugf("Loaded container %v", container.ID)
@@ -400,7 +416,7 @@ func (daemon *Daemon) restore() error {
 					}
 				}
 			}
-			if err := daemon.containerStart(c); err != nil {
+			if err := daemon.containerStart(c, ""); err != nil {
 				logrus.Errorf("Failed to start container %s: %s", c.ID, err)
 			}
 			close(chNotify)
@@ -617,6 +633,9 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	if err := checkConfigOptions(config); err != nil {
 		return nil, err
 	}
+	if err := checkDefaults(config); err != nil {
+		return nil, err
+	}
 
 	// Do we have a disabled network?
 	config.DisableBridge = isBridgeNetworkDisabled(config)
@@ -635,10 +654,14 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	// on Windows to dump Go routine stacks
 	setupDumpStackTrap()
 
-	uidMaps, gidMaps, err := setupRemappedRoot(config)
+	idRanges, err := setupRemappedRoot(config)
 	if err != nil {
 		return nil, err
 	}
+	var uidMaps, gidMaps []idtools.IDMap
+	if idRanges != nil {
+		uidMaps, gidMaps = idRanges.Default()
+	}
 	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
 	if err != nil {
 		return nil, err
@@ -748,6 +771,22 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 		return nil, err
 	}
 
+	if config.TrustPolicy != "" {
+		if d.trustVerifier, err = newTrustVerifier(config.TrustPolicy, trustDir, registryService); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.AdmissionPolicy != "" {
+		if d.admission, err = admission.NewController(config.AdmissionPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.secrets, err = secrets.New(filepath.Join(config.Root, "secrets"), config.SecretsKeyPath); err != nil {
+		return nil, err
+	}
+
 	distributionMetadataStore, err := dmetadata.NewFSMetadataStore(filepath.Join(imageRoot, "distribution"))
 	if err != nil {
 		return nil, err
@@ -798,6 +837,7 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	if runtime.GOOS != "windows" && !sysInfo.CgroupDevicesEnabled {
 		return nil, fmt.Errorf("Devices cgroup isn't mounted")
 	}
+	checkDefaultsSupport(config, sysInfo)
 
 	ed, err := execdrivers.NewDriver(config.ExecOptions, config.ExecRoot, config.Root, sysInfo)
 	if err != nil {
@@ -815,13 +855,21 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	d.configStore = config
 	d.execDriver = ed
 	d.statsCollector = d.newStatsCollector(1 * time.Second)
+	d.usageStop = make(chan struct{})
+	go d.collectUsage(usageInterval, d.usageStop)
 	d.defaultLogConfig = config.LogConfig
 	d.RegistryService = registryService
 	d.EventsService = eventsService
+	if config.AuthZPolicy != "" {
+		if d.authzPolicy, err = authorization.NewPolicyPlugin(config.AuthZPolicy, authzPolicyBackend{d}); err != nil {
+			return nil, err
+		}
+	}
 	d.volumes = volStore
 	d.root = config.Root
 	d.uidMaps = uidMaps
 	d.gidMaps = gidMaps
+	d.idRanges = idRanges
 	d.seccompEnabled = sysInfo.Seccomp
 
 	d.nameIndex = registrar.NewRegistrar()
@@ -836,6 +884,10 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 		return nil, err
 	}
 
+	if err := d.registerMetrics(); err != nil {
+		return nil, err
+	}
+
 	return d, nil
 }
 
@@ -856,8 +908,8 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 		if err := daemon.containerUnpause(c); err != nil {
 			return fmt.Errorf("Failed to unpause container %s with error: %v", c.ID, err)
 		}
-		if _, err := c.WaitStop(10 * time.Second); err != nil {
-			logrus.Debugf("container %s failed to exit in 10 second of SIGTERM, sending SIGKILL to force", c.ID)
+		if _, err := c.WaitStop(time.Duration(c.StopTimeout()) * time.Second); err != nil {
+			logrus.Debugf("container %s failed to exit in %d seconds of SIGTERM, sending SIGKILL to force", c.ID, c.StopTimeout())
 			sig, ok := signal.SignalMap["KILL"]
 			if !ok {
 				return fmt.Errorf("System does not support SIGKILL")
@@ -869,8 +921,8 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 			return err
 		}
 	}
-	// If container failed to exit in 10 seconds of SIGTERM, then using the force
-	if err := daemon.containerStop(c, 10); err != nil {
+	// If container failed to exit in its stop timeout of SIGTERM, then using the force
+	if err := daemon.containerStop(c, c.StopTimeout()); err != nil {
 		return fmt.Errorf("Stop container %s with error: %v", c.ID, err)
 	}
 
@@ -881,6 +933,9 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	if daemon.usageStop != nil {
+		close(daemon.usageStop)
+	}
 	if daemon.containers != nil {
 		group := sync.WaitGroup{}
 		logrus.Debug("starting clean shutdown of all containers...")
@@ -952,13 +1007,31 @@ func (daemon *Daemon) Unmount(container *container.Container) {
 
 // Run uses the execution driver to run a given container
 func (daemon *Daemon) Run(c *container.Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
+	exitStatus, err := daemon.execDriver.Run(c.Command, pipes, daemon.driverHooks(c, startCallback))
+	daemon.recordExitUsage(c, exitStatus)
+	return exitStatus, err
+}
+
+// Restore restores the process of a container from the checkpoint in
+// checkpointDir, and waits for it to exit.
+func (daemon *Daemon) Restore(c *container.Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback, checkpointDir string) (execdriver.ExitStatus, error) {
+	opts := &execdriver.RestoreOptions{
+		ImagesDirectory: checkpointDir,
+		WorkDirectory:   checkpointDir,
+	}
+	exitStatus, err := daemon.execDriver.Restore(c.Command, pipes, daemon.driverHooks(c, startCallback), opts)
+	daemon.recordExitUsage(c, exitStatus)
+	return exitStatus, err
+}
+
+func (daemon *Daemon) driverHooks(c *container.Container, startCallback execdriver.DriverCallback) execdriver.Hooks {
 	hooks := execdriver.Hooks{
 		Start: startCallback,
 	}
 	hooks.PreStart = append(hooks.PreStart, func(processConfig *execdriver.ProcessConfig, pid int, chOOM <-chan struct{}) error {
 		return daemon.setNetworkNamespaceKey(c.ID, pid)
 	})
-	return daemon.execDriver.Run(c.Command, pipes, hooks)
+	return hooks
 }
 
 func (daemon *Daemon) kill(c *container.Container, sig int) error {
@@ -1029,6 +1102,16 @@ func isBrokenPipe(e error) bool {
 // PullImage initiates a pull operation. image is the repository name to pull, and
 // tag may be either empty, or indicate a specific tag to pull.
 func (daemon *Daemon) PullImage(ref reference.Named, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+	// Under a trust policy, tags are pulled by their signed digest.
+	trustedRef, err := daemon.trustedReference(ref, authConfig)
+	if err != nil {
+		return err
+	}
+	pullRef := ref
+	if trustedRef != nil {
+		pullRef = trustedRef
+	}
+
 	// Include a buffer so that slow client connections don't affect
 	// transfer performance.
 	progressChan := make(chan progress.Progress, 100)
@@ -1054,24 +1137,41 @@ func (daemon *Daemon) PullImage(ref reference.Named, metaHeaders map[string][]st
 		DownloadManager:  daemon.downloadManager,
 	}
 
-	err := distribution.Pull(ctx, ref, imagePullConfig)
+	err = distribution.Pull(ctx, pullRef, imagePullConfig)
 	close(progressChan)
 	<-writesDone
-	return err
+	if err != nil {
+		return err
+	}
+
+	if tagged, ok := ref.(reference.NamedTagged); ok && trustedRef != nil {
+		id, err := daemon.referenceStore.Get(trustedRef)
+		if err != nil {
+			return err
+		}
+		if err := daemon.referenceStore.AddTag(tagged, id, true); err != nil {
+			return err
+		}
+		daemon.LogImageEvent(id.String(), tagged.String(), "tag")
+	}
+	return nil
 }
 
 // ExportImage exports a list of images to the given output stream. The
 // exported images are archived into a tar when written to the output
 // stream. All images with the given tag and all versions containing
-// the same tag are exported. names is the set of tags to export, and
-// outStream is the writer which the images are written to.
-func (daemon *Daemon) ExportImage(names []string, outStream io.Writer) error {
+// the same tag are exported. names is the set of tags to export, options
+// controls how they are written, and outStream is the writer which the
+// images are written to.
+func (daemon *Daemon) ExportImage(names []string, options image.ExportOptions, outStream io.Writer) error {
 	imageExporter := tarexport.NewTarExporter(daemon.imageStore, daemon.layerStore, daemon.referenceStore)
-	return imageExporter.Save(names, outStream)
+	return imageExporter.Save(names, options, outStream)
 }
 
 // PushImage initiates a push operation on the repository named localName.
-func (daemon *Daemon) PushImage(ref reference.Named, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+// Layers that are not yet present on the registry are uploaded with the given
+// compression.
+func (daemon *Daemon) PushImage(ref reference.Named, metaHeaders map[string][]string, authConfig *types.AuthConfig, layerCompression archive.Compression, outStream io.Writer) error {
 	// Include a buffer so that slow client connections don't affect
 	// transfer performance.
 	progressChan := make(chan progress.Progress, 100)
@@ -1097,6 +1197,7 @@ func (daemon *Daemon) PushImage(ref reference.Named, metaHeaders map[string][]st
 		ReferenceStore:   daemon.referenceStore,
 		TrustKey:         daemon.trustKey,
 		UploadManager:    daemon.uploadManager,
+		LayerCompression: layerCompression,
 	}
 
 	err := distribution.Push(ctx, ref, imagePushConfig)
@@ -1394,11 +1495,6 @@ func (daemon *Daemon) setHostConfig(container *container.Container, hostConfig *
 	return container.ToDisk()
 }
 
-func (daemon *Daemon) setupInitLayer(initPath string) error {
-	rootUID, rootGID := daemon.GetRemappedUIDGID()
-	return setupInitLayer(initPath, rootUID, rootGID)
-}
-
 func setDefaultMtu(config *Config) {
 	// do nothing if the config does not have the default 0 value.
 	if config.Mtu != 0 {
@@ -1445,10 +1541,35 @@ func (daemon *Daemon) verifyContainerSettings(hostConfig *containertypes.HostCon
 		}
 	}
 
+	if err := verifyRestartPolicy(hostConfig.RestartPolicy); err != nil {
+		return nil, err
+	}
+
+	if err := daemon.verifySecrets(hostConfig.Secrets); err != nil {
+		return nil, err
+	}
+
 	// Now do platform-specific verification
 	return verifyPlatformContainerSettings(daemon, hostConfig, config)
 }
 
+// verifyRestartPolicy checks the exit codes and delays of a restart policy.
+func verifyRestartPolicy(policy containertypes.RestartPolicy) error {
+	if policy.IsOnExitCodes() && len(policy.ExitCodes) == 0 {
+		return fmt.Errorf("The on-exit-codes restart policy requires at least one exit code")
+	}
+	if !policy.IsOnExitCodes() && len(policy.ExitCodes) > 0 {
+		return fmt.Errorf("Exit codes are only valid with the on-exit-codes restart policy")
+	}
+	if policy.InitialDelay < 0 || policy.MaxDelay < 0 || policy.ResetWindow < 0 {
+		return fmt.Errorf("Restart delays cannot be negative")
+	}
+	if policy.MaxDelay != 0 && policy.MaxDelay < policy.InitialDelay {
+		return fmt.Errorf("The maximum restart delay cannot be less than the initial delay")
+	}
+	return nil
+}
+
 func configureVolumes(config *Config, rootUID, rootGID int) (*store.VolumeStore, error) {
 	volumesDriver, err := local.New(config.Root, rootUID, rootGID)
 	if err != nil {
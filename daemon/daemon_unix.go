@@ -4,6 +4,7 @@ package daemon
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
@@ -47,6 +49,9 @@ const (
 	// constants for remapped root settings
 	defaultIDSpecifier string = "default"
 	defaultRemappedID  string = "dockremap"
+	// policies for stopping and killing paused containers
+	pauseStopThaw = "thaw"
+	pauseStopKill = "kill"
 )
 
 func getBlkioWeightDevices(config *containertypes.HostConfig) ([]*blkiodev.WeightDevice, error) {
@@ -72,6 +77,12 @@ func parseSecurityOpt(container *container.Container, config *containertypes.Hos
 
 	for _, opt := range config.SecurityOpt {
 		con := strings.SplitN(opt, ":", 2)
+		if con[0] == noNewPrivileges {
+			if container.NoNewPrivileges, err = parseNoNewPrivileges(con); err != nil {
+				return err
+			}
+			continue
+		}
 		if len(con) == 1 {
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -354,9 +365,63 @@ func verifyContainerResources(resources *containertypes.Resources) ([]string, er
 		resources.BlkioDeviceWriteIOps = []*pblkiodev.ThrottleDevice{}
 	}
 
+	// pids subsystem checks and adjustments
+	if resources.PidsLimit != 0 && !sysInfo.PidsLimit {
+		warnings = append(warnings, "Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		logrus.Warnf("Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		resources.PidsLimit = 0
+	}
+	if resources.PidsLimit < -1 {
+		return warnings, fmt.Errorf("Invalid pids limit %d, the limit must be positive, or -1 for unlimited.", resources.PidsLimit)
+	}
+
+	for _, l := range resources.NetworkRateLimits {
+		if l.Interface == "" {
+			return warnings, fmt.Errorf("A network rate limit requires the name of an interface in the container.")
+		}
+		if l.EgressRate > math.MaxUint32 || l.IngressRate > math.MaxUint32 {
+			return warnings, fmt.Errorf("The maximum network rate limit is %d bytes per second.", uint64(math.MaxUint32))
+		}
+	}
+
 	return warnings, nil
 }
 
+// verifyNetworkRateLimits checks that rate limits are only set for containers
+// with their own network namespace.
+func verifyNetworkRateLimits(hostConfig *containertypes.HostConfig, limits []containertypes.NetworkRateLimit) error {
+	if len(limits) == 0 {
+		return nil
+	}
+	if hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer() || hostConfig.NetworkMode.IsNone() {
+		return fmt.Errorf("Network rate limits cannot be set with the %s network mode.", hostConfig.NetworkMode.NetworkName())
+	}
+	return nil
+}
+
+// verifySysctls checks that the sysctls are whitelisted, and that the
+// container has its own namespace for each of them, so that they do not
+// change the settings of the host or of another container.
+func verifySysctls(hostConfig *containertypes.HostConfig) error {
+	for key := range hostConfig.Sysctls {
+		ns, err := runconfigopts.SysctlNamespace(key)
+		if err != nil {
+			return err
+		}
+		switch ns {
+		case runconfigopts.SysctlNamespaceIPC:
+			if hostConfig.IpcMode.IsHost() || hostConfig.IpcMode.IsContainer() {
+				return fmt.Errorf("Sysctl %s cannot be set with the %s IPC mode.", key, hostConfig.IpcMode)
+			}
+		case runconfigopts.SysctlNamespaceNet:
+			if hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer() {
+				return fmt.Errorf("Sysctl %s cannot be set with the %s network mode.", key, hostConfig.NetworkMode.NetworkName())
+			}
+		}
+	}
+	return nil
+}
+
 // verifyPlatformContainerSettings performs platform-specific validation of the
 // hostconfig and config structures.
 func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config) ([]string, error) {
@@ -381,12 +446,32 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.
 	if hostConfig.OomScoreAdj < -1000 || hostConfig.OomScoreAdj > 1000 {
 		return warnings, fmt.Errorf("Invalid value %d, range for oom score adj is [-1000, 1000].", hostConfig.OomScoreAdj)
 	}
+
+	if _, err := getDeviceCgroupRules(hostConfig.DeviceCgroupRules); err != nil {
+		return warnings, err
+	}
+
+	if err := verifyNetworkRateLimits(hostConfig, hostConfig.NetworkRateLimits); err != nil {
+		return warnings, err
+	}
+
+	if err := verifySysctls(hostConfig); err != nil {
+		return warnings, err
+	}
+
+	if hostConfig.ReadonlyTmpfs && !hostConfig.ReadonlyRootfs {
+		return warnings, fmt.Errorf("The --read-only-tmpfs option requires a read-only root filesystem (--read-only).")
+	}
 	if sysInfo.IPv4ForwardingDisabled {
 		warnings = append(warnings, "IPv4 forwarding is disabled. Networking will not work.")
 		logrus.Warnf("IPv4 forwarding is disabled. Networking will not work")
 	}
-	// check for various conflicting options with user namespaces
-	if daemon.configStore.RemappedRoot != "" {
+	if hostConfig.UsernsMode.IsPrivate() && (daemon.idRanges == nil || daemon.configStore.UsernsPrivateRanges == 0) {
+		return warnings, fmt.Errorf("A private user namespace requires the daemon to be started with --userns-remap and --userns-private-ranges.")
+	}
+	// check for various conflicting options with user namespaces, unless
+	// the container opts out of them
+	if daemon.configStore.RemappedRoot != "" && !hostConfig.UsernsMode.IsHost() {
 		if hostConfig.Privileged {
 			return warnings, fmt.Errorf("Privileged mode is incompatible with user namespaces.")
 		}
@@ -418,9 +503,28 @@ func checkConfigOptions(config *Config) error {
 	if !config.Bridge.EnableIPTables && config.Bridge.EnableIPMasq {
 		config.Bridge.EnableIPMasq = false
 	}
+	if config.PauseStopPolicy != pauseStopThaw && config.PauseStopPolicy != pauseStopKill {
+		return fmt.Errorf("Invalid --pause-stop-policy %q, expected %s or %s", config.PauseStopPolicy, pauseStopThaw, pauseStopKill)
+	}
+	if config.PauseTimeout < 0 {
+		return fmt.Errorf("Invalid --pause-timeout %s, it cannot be negative", config.PauseTimeout)
+	}
 	return nil
 }
 
+// stopPausedByKill returns whether paused containers are stopped by killing
+// their frozen processes right away, rather than thawing them to handle the
+// stop signal.
+func (daemon *Daemon) stopPausedByKill() bool {
+	return daemon.configStore.PauseStopPolicy == pauseStopKill
+}
+
+// pauseTimeout returns the duration after which paused containers are
+// resumed, or 0 if they are not.
+func (daemon *Daemon) pauseTimeout() time.Duration {
+	return daemon.configStore.PauseTimeout
+}
+
 // checkSystem validates platform-specific requirements
 func checkSystem() error {
 	if os.Geteuid() != 0 {
@@ -799,37 +903,43 @@ func parseRemappedRoot(usergrp string) (string, string, error) {
 	return username, groupname, nil
 }
 
-func setupRemappedRoot(config *Config) ([]idtools.IDMap, []idtools.IDMap, error) {
+// setupRemappedRoot returns the allocator of the subordinate ID ranges of
+// the remapped root, whose default mapping is the one of the daemon, or nil
+// if user namespaces are not enabled. The ranges of the private user
+// namespaces are only set aside with --userns-private-ranges, so that the
+// daemon-wide mapping keeps all the subordinate IDs otherwise.
+func setupRemappedRoot(config *Config) (*idtools.RangeAllocator, error) {
 	if runtime.GOOS != "linux" && config.RemappedRoot != "" {
-		return nil, nil, fmt.Errorf("User namespaces are only supported on Linux")
+		return nil, fmt.Errorf("User namespaces are only supported on Linux")
 	}
 
 	// if the daemon was started with remapped root option, parse
 	// the config option to the int uid,gid values
-	var (
-		uidMaps, gidMaps []idtools.IDMap
-	)
-	if config.RemappedRoot != "" {
-		username, groupname, err := parseRemappedRoot(config.RemappedRoot)
-		if err != nil {
-			return nil, nil, err
-		}
-		if username == "root" {
-			// Cannot setup user namespaces with a 1-to-1 mapping; "--root=0:0" is a no-op
-			// effectively
-			logrus.Warnf("User namespaces: root cannot be remapped with itself; user namespaces are OFF")
-			return uidMaps, gidMaps, nil
+	if config.RemappedRoot == "" {
+		if config.UsernsPrivateRanges != 0 {
+			return nil, fmt.Errorf("--userns-private-ranges requires --userns-remap")
 		}
-		logrus.Infof("User namespaces: ID ranges will be mapped to subuid/subgid ranges of: %s:%s", username, groupname)
-		// update remapped root setting now that we have resolved them to actual names
-		config.RemappedRoot = fmt.Sprintf("%s:%s", username, groupname)
+		return nil, nil
+	}
+	username, groupname, err := parseRemappedRoot(config.RemappedRoot)
+	if err != nil {
+		return nil, err
+	}
+	if username == "root" {
+		// Cannot setup user namespaces with a 1-to-1 mapping; "--root=0:0" is a no-op
+		// effectively
+		logrus.Warnf("User namespaces: root cannot be remapped with itself; user namespaces are OFF")
+		return nil, nil
+	}
+	logrus.Infof("User namespaces: ID ranges will be mapped to subuid/subgid ranges of: %s:%s", username, groupname)
+	// update remapped root setting now that we have resolved them to actual names
+	config.RemappedRoot = fmt.Sprintf("%s:%s", username, groupname)
 
-		uidMaps, gidMaps, err = idtools.CreateIDMappings(username, groupname)
-		if err != nil {
-			return nil, nil, fmt.Errorf("Can't create ID mappings: %v", err)
-		}
+	idRanges, err := idtools.NewRangeAllocator(username, groupname, config.UsernsPrivateRanges)
+	if err != nil {
+		return nil, fmt.Errorf("Can't create ID mappings: %v", err)
 	}
-	return uidMaps, gidMaps, nil
+	return idRanges, nil
 }
 
 func setupDaemonRoot(config *Config, rootDir string, rootUID, rootGID int) error {
@@ -906,12 +1016,18 @@ func (daemon *Daemon) registerLinks(container *container.Container, hostConfig *
 // conditionalMountOnStart is a platform specific helper function during the
 // container start to call mount.
 func (daemon *Daemon) conditionalMountOnStart(container *container.Container) error {
-	return daemon.Mount(container)
+	if err := daemon.Mount(container); err != nil {
+		return err
+	}
+	return daemon.mountRootfs(container)
 }
 
 // conditionalUnmountOnCleanup is a platform specific helper function called
 // during the cleanup of a container to unmount.
 func (daemon *Daemon) conditionalUnmountOnCleanup(container *container.Container) {
+	if err := daemon.unmountRootfs(container); err != nil {
+		logrus.Errorf("Error unmounting the root filesystem of container %s: %v", container.ID, err)
+	}
 	daemon.Unmount(container)
 }
 
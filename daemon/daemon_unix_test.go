@@ -5,9 +5,13 @@ package daemon
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
+	containerpkg "github.com/docker/docker/container"
 	"github.com/docker/engine-api/types/container"
+	"github.com/docker/go-units"
 )
 
 func TestAdjustCPUShares(t *testing.T) {
@@ -85,3 +89,148 @@ func TestAdjustCPUSharesNoAdjustment(t *testing.T) {
 		t.Error("Expected CPUShares to be unchanged")
 	}
 }
+
+func TestCheckConfigOptionsPause(t *testing.T) {
+	for _, tc := range []struct {
+		policy  string
+		timeout time.Duration
+		valid   bool
+	}{
+		{pauseStopThaw, 0, true},
+		{pauseStopKill, time.Minute, true},
+		{"", 0, false},
+		{"stop", 0, false},
+		{pauseStopThaw, -time.Second, false},
+	} {
+		config := &Config{}
+		config.Bridge.EnableIPTables = true
+		config.PauseStopPolicy = tc.policy
+		config.PauseTimeout = tc.timeout
+
+		err := checkConfigOptions(config)
+		if tc.valid && err != nil {
+			t.Fatalf("Expected policy %q and timeout %s to be valid, got %v", tc.policy, tc.timeout, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("Expected policy %q and timeout %s to be invalid", tc.policy, tc.timeout)
+		}
+	}
+}
+
+func TestVerifySysctls(t *testing.T) {
+	valid := []*container.HostConfig{
+		{Sysctls: map[string]string{"net.ipv4.ip_forward": "1", "kernel.shmmax": "1"}},
+		{Sysctls: map[string]string{"net.ipv4.ip_forward": "1"}, IpcMode: "host"},
+		{Sysctls: map[string]string{"kernel.msgmax": "1"}, NetworkMode: "host"},
+	}
+	for _, hostConfig := range valid {
+		if err := verifySysctls(hostConfig); err != nil {
+			t.Fatalf("Expected the sysctls %v to be valid, got %v", hostConfig.Sysctls, err)
+		}
+	}
+
+	invalid := []*container.HostConfig{
+		{Sysctls: map[string]string{"kernel.hostname": "foo"}},
+		{Sysctls: map[string]string{"net.ipv4.ip_forward": "1"}, NetworkMode: "host"},
+		{Sysctls: map[string]string{"net.ipv4.ip_forward": "1"}, NetworkMode: "container:foo"},
+		{Sysctls: map[string]string{"fs.mqueue.msg_max": "1"}, IpcMode: "host"},
+		{Sysctls: map[string]string{"kernel.sem": "1"}, IpcMode: "container:foo"},
+	}
+	for _, hostConfig := range invalid {
+		if err := verifySysctls(hostConfig); err == nil {
+			t.Fatalf("Expected an error for the sysctls %v with ipc mode %q and network mode %q", hostConfig.Sysctls, hostConfig.IpcMode, hostConfig.NetworkMode)
+		}
+	}
+}
+
+func TestParseSecurityOptNoNewPrivileges(t *testing.T) {
+	for _, tc := range []struct {
+		opt   string
+		set   bool
+		valid bool
+	}{
+		{"no-new-privileges", true, true},
+		{"no-new-privileges:true", true, true},
+		{"no-new-privileges:false", false, true},
+		{"no-new-privileges:maybe", false, false},
+	} {
+		c := &containerpkg.Container{}
+		err := parseSecurityOpt(c, &container.HostConfig{SecurityOpt: []string{tc.opt}})
+		if tc.valid && err != nil {
+			t.Fatalf("Expected %q to be valid, got %v", tc.opt, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("Expected %q to be invalid", tc.opt)
+		}
+		if c.NoNewPrivileges != tc.set {
+			t.Fatalf("Expected %q to set no-new-privileges to %v", tc.opt, tc.set)
+		}
+	}
+}
+
+func TestMergeHostConfigDefaults(t *testing.T) {
+	config := &Config{
+		NoNewPrivileges:     true,
+		DefaultCapabilities: []string{"CHOWN", "KILL"},
+		DefaultPidsLimit:    100,
+		Ulimits: map[string]*units.Ulimit{
+			"nofile": {Name: "nofile", Soft: 1024, Hard: 2048},
+			"nproc":  {Name: "nproc", Soft: 512, Hard: 512},
+		},
+	}
+	daemon := &Daemon{configStore: config}
+
+	hostConfig := &container.HostConfig{}
+	daemon.mergeHostConfigDefaults(hostConfig)
+	if !reflect.DeepEqual(hostConfig.SecurityOpt, []string{"no-new-privileges"}) {
+		t.Fatalf("Expected no-new-privileges to be set, got %v", hostConfig.SecurityOpt)
+	}
+	if !reflect.DeepEqual(hostConfig.Capabilities, config.DefaultCapabilities) {
+		t.Fatalf("Expected the default capabilities, got %v", hostConfig.Capabilities)
+	}
+	if len(hostConfig.Ulimits) != 2 || hostConfig.Ulimits[0].Name != "nofile" || hostConfig.Ulimits[1].Name != "nproc" {
+		t.Fatalf("Expected the default ulimits, got %v", hostConfig.Ulimits)
+	}
+	if hostConfig.PidsLimit != 100 {
+		t.Fatalf("Expected the default pids limit, got %d", hostConfig.PidsLimit)
+	}
+
+	// the options a container sets opt out of the defaults
+	hostConfig = &container.HostConfig{
+		SecurityOpt: []string{"no-new-privileges:false"},
+		Privileged:  true,
+	}
+	hostConfig.Ulimits = []*units.Ulimit{{Name: "nofile", Soft: 10, Hard: 10}}
+	hostConfig.PidsLimit = -1
+	daemon.mergeHostConfigDefaults(hostConfig)
+	if !reflect.DeepEqual(hostConfig.SecurityOpt, []string{"no-new-privileges:false"}) {
+		t.Fatalf("Expected no-new-privileges to be unset, got %v", hostConfig.SecurityOpt)
+	}
+	if hostConfig.Capabilities != nil {
+		t.Fatalf("Expected no capabilities for a privileged container, got %v", hostConfig.Capabilities)
+	}
+	if len(hostConfig.Ulimits) != 2 || hostConfig.Ulimits[0].Soft != 10 || hostConfig.Ulimits[1].Name != "nproc" {
+		t.Fatalf("Expected the nofile ulimit to be kept, got %v", hostConfig.Ulimits)
+	}
+	if hostConfig.PidsLimit != -1 {
+		t.Fatalf("Expected the pids limit to be kept, got %d", hostConfig.PidsLimit)
+	}
+}
+
+func TestCheckDefaults(t *testing.T) {
+	config := &Config{DefaultCapabilities: []string{"chown", "net_raw", "chown"}}
+	if err := checkDefaults(config); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.DefaultCapabilities, []string{"CHOWN", "NET_RAW"}) {
+		t.Fatalf("Expected the capabilities to be normalized, got %v", config.DefaultCapabilities)
+	}
+	for _, config := range []*Config{
+		{DefaultCapabilities: []string{"FOO"}},
+		{DefaultPidsLimit: -2},
+	} {
+		if err := checkDefaults(config); err == nil {
+			t.Fatalf("Expected %v to be invalid", config)
+		}
+	}
+}
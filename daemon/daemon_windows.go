@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
@@ -85,6 +86,9 @@ func (daemon *Daemon) adaptContainerSettings(hostConfig *containertypes.HostConf
 // verifyPlatformContainerSettings performs platform-specific validation of the
 // hostconfig and config structures.
 func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config) ([]string, error) {
+	if hostConfig != nil && len(hostConfig.Secrets) > 0 {
+		return nil, fmt.Errorf("Secrets are not supported on Windows")
+	}
 	return nil, nil
 }
 
@@ -93,6 +97,16 @@ func checkConfigOptions(config *Config) error {
 	return nil
 }
 
+// stopPausedByKill returns false, containers cannot be paused on Windows.
+func (daemon *Daemon) stopPausedByKill() bool {
+	return false
+}
+
+// pauseTimeout returns 0, containers cannot be paused on Windows.
+func (daemon *Daemon) pauseTimeout() time.Duration {
+	return 0
+}
+
 // checkSystem validates platform-specific requirements
 func checkSystem() error {
 	// Validate the OS version. Note that docker.exe must be manifested for this
@@ -137,8 +151,8 @@ func (daemon *Daemon) cleanupMounts() error {
 	return nil
 }
 
-func setupRemappedRoot(config *Config) ([]idtools.IDMap, []idtools.IDMap, error) {
-	return nil, nil, nil
+func setupRemappedRoot(config *Config) (*idtools.RangeAllocator, error) {
+	return nil, nil
 }
 
 func setupDaemonRoot(config *Config, rootDir string, rootUID, rootGID int) error {
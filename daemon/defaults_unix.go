@@ -0,0 +1,118 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// noNewPrivileges is the security option which keeps the processes of a
+// container from gaining privileges, as in --security-opt no-new-privileges.
+const noNewPrivileges = "no-new-privileges"
+
+// parseNoNewPrivileges parses a no-new-privileges security option split on
+// ":". The option is set when given without a value.
+func parseNoNewPrivileges(con []string) (bool, error) {
+	if len(con) == 1 {
+		return true, nil
+	}
+	set, err := strconv.ParseBool(con[1])
+	if err != nil {
+		return false, fmt.Errorf("Invalid --security-opt: %q", strings.Join(con, ":"))
+	}
+	return set, nil
+}
+
+// checkDefaults validates the defaults of the daemon for the containers, and
+// normalizes the default capability set.
+func checkDefaults(config *Config) error {
+	if config.DefaultPidsLimit < -1 {
+		return fmt.Errorf("Invalid --default-pids-limit %d, the limit must be positive, or -1 for unlimited", config.DefaultPidsLimit)
+	}
+	if len(config.DefaultCapabilities) > 0 {
+		caps, err := execdriver.TweakCapabilities(nil, config.DefaultCapabilities, nil)
+		if err != nil {
+			return fmt.Errorf("Invalid --default-capability: %v", err)
+		}
+		config.DefaultCapabilities = caps
+	}
+	return nil
+}
+
+// checkDefaultsSupport discards the defaults of the daemon the kernel has no
+// support for.
+func checkDefaultsSupport(config *Config, sysInfo *sysinfo.SysInfo) {
+	if config.DefaultPidsLimit != 0 && !sysInfo.PidsLimit {
+		logrus.Warnf("Your kernel does not support pids limit capabilities. Default pids limit discarded.")
+		config.DefaultPidsLimit = 0
+	}
+}
+
+// mergeHostConfigDefaults merges the defaults of the daemon into the host
+// config of a container being created, so that they are recorded with the
+// container and changing them only affects the containers created afterwards.
+// A container opts out of a default by setting the option itself.
+func (daemon *Daemon) mergeHostConfigDefaults(hostConfig *containertypes.HostConfig) {
+	config := daemon.configStore
+	if config.NoNewPrivileges && !hasSecurityOpt(hostConfig.SecurityOpt, noNewPrivileges) {
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, noNewPrivileges)
+	}
+	if len(hostConfig.Capabilities) == 0 && !hostConfig.Privileged && len(config.DefaultCapabilities) > 0 {
+		hostConfig.Capabilities = append([]string{}, config.DefaultCapabilities...)
+	}
+
+	set := make(map[string]bool)
+	for _, ul := range hostConfig.Ulimits {
+		set[ul.Name] = true
+	}
+	var names []string
+	for name := range config.Ulimits {
+		if !set[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ul := *config.Ulimits[name]
+		hostConfig.Ulimits = append(hostConfig.Ulimits, &ul)
+	}
+
+	if hostConfig.PidsLimit == 0 {
+		hostConfig.PidsLimit = config.DefaultPidsLimit
+	}
+}
+
+// hasSecurityOpt returns whether the security options hold the option name,
+// whatever its value.
+func hasSecurityOpt(securityOpt []string, name string) bool {
+	for _, opt := range securityOpt {
+		if strings.SplitN(opt, ":", 2)[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fillDefaultsInfo adds the defaults of the daemon for the containers to the
+// system information.
+func (daemon *Daemon) fillDefaultsInfo(v *types.Info) {
+	config := daemon.configStore
+	if config.NoNewPrivileges {
+		v.SecurityOptions = append(v.SecurityOptions, "name="+noNewPrivileges)
+	}
+	v.DefaultCapabilities = config.DefaultCapabilities
+	for _, ul := range config.Ulimits {
+		v.DefaultUlimits = append(v.DefaultUlimits, ul.String())
+	}
+	sort.Strings(v.DefaultUlimits)
+	v.DefaultPidsLimit = config.DefaultPidsLimit
+}
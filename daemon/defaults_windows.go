@@ -0,0 +1,28 @@
+package daemon
+
+import (
+	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// checkDefaults does nothing, there are no defaults for the containers on
+// Windows.
+func checkDefaults(config *Config) error {
+	return nil
+}
+
+// checkDefaultsSupport does nothing, there are no defaults for the
+// containers on Windows.
+func checkDefaultsSupport(config *Config, sysInfo *sysinfo.SysInfo) {
+}
+
+// mergeHostConfigDefaults does nothing, there are no defaults for the
+// containers on Windows.
+func (daemon *Daemon) mergeHostConfigDefaults(hostConfig *containertypes.HostConfig) {
+}
+
+// fillDefaultsInfo does nothing, there are no defaults for the containers on
+// Windows.
+func (daemon *Daemon) fillDefaultsInfo(v *types.Info) {
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
@@ -19,6 +20,8 @@ import (
 // fails. If the remove succeeds, the container name is released, and
 // network links are removed.
 func (daemon *Daemon) ContainerRm(name string, config *types.ContainerRmConfig) error {
+	defer containerActions.ObserveSince(time.Now(), "delete")
+
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
@@ -132,6 +135,7 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 	if err != nil && err != layer.ErrMountDoesNotExist {
 		return derr.ErrorCodeRmDriverFS.WithArgs(daemon.GraphDriverName(), container.ID, err)
 	}
+	daemon.releaseIDMaps(container)
 
 	if err = daemon.execDriver.Clean(container.ID); err != nil {
 		return derr.ErrorCodeRmExecDriver.WithArgs(container.ID, err)
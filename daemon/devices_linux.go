@@ -0,0 +1,256 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/container"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/reexec"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// deviceNodesHelper is the name of the helper creating and removing the
+// device nodes of running containers on re-exec.
+const deviceNodesHelper = "docker-device-nodes"
+
+// ContainerDevicesUpdate adds the devices and device cgroup rules in config
+// to the running container name, or removes them if config.Remove is set.
+// The host config of the container is updated as well, so the changes are
+// kept when the container is restarted.
+func (daemon *Daemon) ContainerDevicesUpdate(name string, config *containertypes.DevicesUpdateConfig) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := getDeviceCgroupRules(config.DeviceCgroupRules); err != nil {
+		return err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	if !container.Running || container.Restarting {
+		return derr.ErrorCodeNotRunning.WithArgs(container.ID)
+	}
+
+	resources := container.HostConfig.Resources
+	if config.Remove {
+		resources.Devices = removeDeviceMappings(resources.Devices, config.Devices)
+		resources.DeviceCgroupRules = removeDeviceCgroupRules(resources.DeviceCgroupRules, config.DeviceCgroupRules)
+	} else {
+		resources.Devices = append(resources.Devices, config.Devices...)
+		resources.DeviceCgroupRules = append(resources.DeviceCgroupRules, config.DeviceCgroupRules...)
+	}
+
+	allowed, autoCreated, err := getDevices(&resources)
+	if err != nil {
+		return err
+	}
+
+	var nodes []*configs.Device
+	for _, deviceMapping := range config.Devices {
+		devs, err := getDevicesFromPath(deviceMapping)
+		if err != nil {
+			if config.Remove {
+				// The device may be gone from the host already, the
+				// node in the container is removed by path.
+				nodes = append(nodes, &configs.Device{Path: deviceMapping.PathInContainer})
+				continue
+			}
+			return err
+		}
+		nodes = append(nodes, devs...)
+	}
+
+	// Nodes are created only once the cgroup allows access to them, and
+	// access is only revoked once the nodes are removed.
+	if config.Remove {
+		if err := removeDeviceNodes(container, nodes); err != nil {
+			return err
+		}
+	}
+
+	prevAllowed, prevAutoCreated := container.Command.AllowedDevices, container.Command.AutoCreatedDevices
+	container.Command.AllowedDevices, container.Command.AutoCreatedDevices = allowed, autoCreated
+	if err := daemon.execDriver.UpdateDevices(container.Command); err != nil {
+		container.Command.AllowedDevices, container.Command.AutoCreatedDevices = prevAllowed, prevAutoCreated
+		return err
+	}
+
+	if !config.Remove {
+		if err := createDeviceNodes(container, nodes); err != nil {
+			return err
+		}
+	}
+
+	container.HostConfig.Resources = resources
+	if err := container.ToDisk(); err != nil {
+		return err
+	}
+
+	daemon.LogContainerEvent(container, "update")
+	return nil
+}
+
+// removeDeviceMappings returns devices without the ones that map to the
+// same path inside the container as one of remove.
+func removeDeviceMappings(devices, remove []containertypes.DeviceMapping) []containertypes.DeviceMapping {
+	var kept []containertypes.DeviceMapping
+next:
+	for _, d := range devices {
+		for _, r := range remove {
+			if filepath.Clean(d.PathInContainer) == filepath.Clean(r.PathInContainer) {
+				continue next
+			}
+		}
+		kept = append(kept, d)
+	}
+	return kept
+}
+
+// removeDeviceCgroupRules returns rules without the ones in remove.
+func removeDeviceCgroupRules(rules, remove []string) []string {
+	var kept []string
+next:
+	for _, rule := range rules {
+		for _, r := range remove {
+			if rule == r {
+				continue next
+			}
+		}
+		kept = append(kept, rule)
+	}
+	return kept
+}
+
+// deviceNodesRequest holds the device nodes the device nodes helper creates
+// or removes in the root filesystem of a container.
+type deviceNodesRequest struct {
+	Remove bool
+	UID    int
+	GID    int
+	Nodes  []*configs.Device
+}
+
+func init() {
+	reexec.Register(deviceNodesHelper, deviceNodesMain)
+}
+
+// createDeviceNodes creates the device nodes in the running container c,
+// owned by its remapped root.
+func createDeviceNodes(c *container.Container, nodes []*configs.Device) error {
+	return runDeviceNodesHelper(c, &deviceNodesRequest{
+		UID:   c.Command.RemappedRoot.UID,
+		GID:   c.Command.RemappedRoot.GID,
+		Nodes: nodes,
+	})
+}
+
+// removeDeviceNodes removes the device nodes from the running container c.
+func removeDeviceNodes(c *container.Container, nodes []*configs.Device) error {
+	return runDeviceNodesHelper(c, &deviceNodesRequest{
+		Remove: true,
+		Nodes:  nodes,
+	})
+}
+
+// runDeviceNodesHelper runs the device nodes helper chrooted in the root
+// filesystem of running container c, as seen from its mount namespace, so
+// that the paths of the nodes, and any symlink the container swaps in, are
+// resolved inside the container.
+func runDeviceNodesHelper(c *container.Container, req *deviceNodesRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	cmd := reexec.Command(deviceNodesHelper, fmt.Sprintf("/proc/%d/root", c.Pid))
+	cmd.Stdin = bytes.NewReader(data)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return fmt.Errorf("Cannot update the device nodes of container %s: %v", c.ID, err)
+	}
+	return nil
+}
+
+// deviceNodesMain is the entry point of the device nodes helper. It chroots
+// into the directory given in its arguments, and creates or removes the
+// device nodes of the request read from its stdin.
+func deviceNodesMain() {
+	runtime.LockOSThread()
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s ROOT\n", deviceNodesHelper)
+		os.Exit(1)
+	}
+	var req deviceNodesRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := syscall.Chroot(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := syscall.Chdir("/"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, node := range req.Nodes {
+		var err error
+		if req.Remove {
+			err = removeDeviceNode(node)
+		} else {
+			err = createDeviceNode(node, req.UID, req.GID)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// createDeviceNode creates node in the root directory of the process,
+// owned by uid and gid.
+func createDeviceNode(node *configs.Device, uid, gid int) error {
+	dest := filepath.Join("/", node.Path)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	fileMode := node.FileMode
+	switch node.Type {
+	case 'c':
+		fileMode |= syscall.S_IFCHR
+	case 'b':
+		fileMode |= syscall.S_IFBLK
+	default:
+		return fmt.Errorf("%c is not a valid device type for device %s", node.Type, node.Path)
+	}
+	if err := syscall.Mknod(dest, uint32(fileMode), node.Mkdev()); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("Cannot create device node %s: %v", node.Path, err)
+	}
+	// the node may have been replaced by a symlink meanwhile, do not
+	// follow it
+	return os.Lchown(dest, uid, gid)
+}
+
+// removeDeviceNode removes node from the root directory of the process.
+func removeDeviceNode(node *configs.Device) error {
+	if err := os.Remove(filepath.Join("/", node.Path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Cannot remove device node %s: %v", node.Path, err)
+	}
+	return nil
+}
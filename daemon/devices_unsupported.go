@@ -0,0 +1,14 @@
+// +build !linux
+
+package daemon
+
+import (
+	"fmt"
+
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// ContainerDevicesUpdate is not supported on this platform.
+func (daemon *Daemon) ContainerDevicesUpdate(name string, config *containertypes.DevicesUpdateConfig) error {
+	return fmt.Errorf("Updating the devices of a running container is not supported on this platform")
+}
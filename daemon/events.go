@@ -8,9 +8,16 @@ import (
 	"github.com/docker/libnetwork"
 )
 
-// LogContainerEvent generates an event related to a container.
+// LogContainerEvent generates an event related to a container with only the default attributes.
 func (daemon *Daemon) LogContainerEvent(container *container.Container, action string) {
-	attributes := copyAttributes(container.Config.Labels)
+	daemon.LogContainerEventWithAttributes(container, action, map[string]string{})
+}
+
+// LogContainerEventWithAttributes generates an event related to a container with specific given attributes.
+func (daemon *Daemon) LogContainerEventWithAttributes(container *container.Container, action string, attributes map[string]string) {
+	for k, v := range container.Config.Labels {
+		attributes[k] = v
+	}
 	if container.Config.Image != "" {
 		attributes["image"] = container.Config.Image
 	}
@@ -68,6 +75,15 @@ func (daemon *Daemon) LogNetworkEventWithAttributes(nw libnetwork.Network, actio
 	daemon.EventsService.Log(action, events.NetworkEventType, actor)
 }
 
+// LogDaemonEvent generates an event related to the daemon itself.
+func (daemon *Daemon) LogDaemonEvent(action string, attributes map[string]string) {
+	actor := events.Actor{
+		ID:         daemon.ID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, events.DaemonEventType, actor)
+}
+
 // copyAttributes guarantees that labels are not mutated by event triggers.
 func copyAttributes(labels map[string]string) map[string]string {
 	attributes := map[string]string{}
@@ -118,6 +118,7 @@ func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
 	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
+	eventsCounter.Inc(eventType)
 }
 
 // SubscribersCount returns number of event listeners
@@ -0,0 +1,17 @@
+package events
+
+import (
+	"github.com/docker/docker/pkg/metrics"
+)
+
+// eventsCounter counts the events logged by type. The actions are left out,
+// as some of them include the command of the process they relate to.
+var eventsCounter = metrics.NewCounter(
+	"engine_daemon_events_total",
+	"The number of events logged by the daemon",
+	"type",
+)
+
+func init() {
+	metrics.MustRegister(eventsCounter)
+}
@@ -94,10 +94,60 @@ type Driver interface {
 	// Update updates resource configs for a container
 	Update(c *Command) error
 
+	// UpdateDevices replaces the devices a running container is allowed
+	// to access
+	UpdateDevices(c *Command) error
+
+	// Checkpoint saves the state of a running container to disk.
+	Checkpoint(c *Command, opts *CheckpointOptions) error
+
+	// Restore restores a container from a checkpoint, blocks until the
+	// restored process exits and returns the exit code.
+	Restore(c *Command, pipes *Pipes, hooks Hooks, opts *RestoreOptions) (ExitStatus, error)
+
 	// SupportsHooks refers to the driver capability to exploit pre/post hook functionality
 	SupportsHooks() bool
 }
 
+// SecurityProfile is a default security profile a driver confines the
+// containers with.
+type SecurityProfile struct {
+	// Name is the name of the security feature, such as seccomp.
+	Name string
+	// Profile is the file the profile is loaded from, or default for the
+	// profile built into the driver.
+	Profile string
+}
+
+// SecurityProfiler is implemented by the drivers whose default security
+// profiles can be reloaded without restarting the daemon.
+type SecurityProfiler interface {
+	// SecurityProfiles returns the default security profiles in use.
+	SecurityProfiles() []SecurityProfile
+
+	// ReloadSecurityProfiles loads the default security profiles again,
+	// for the containers started afterwards.
+	ReloadSecurityProfiles() error
+}
+
+// CheckpointOptions contains the options used to checkpoint a container.
+type CheckpointOptions struct {
+	// ImagesDirectory is the directory the checkpoint images are written to.
+	ImagesDirectory string
+	// WorkDirectory is the directory logs of the checkpoint are written to.
+	WorkDirectory string
+	// LeaveRunning leaves the container running after it is checkpointed.
+	LeaveRunning bool
+}
+
+// RestoreOptions contains the options used to restore a container.
+type RestoreOptions struct {
+	// ImagesDirectory is the directory the checkpoint images are read from.
+	ImagesDirectory string
+	// WorkDirectory is the directory logs of the restore are written to.
+	WorkDirectory string
+}
+
 // CommonResources contains the resource configs for a driver that are
 // common across platforms.
 type CommonResources struct {
@@ -113,6 +163,7 @@ type ResourceStats struct {
 	Read        time.Time `json:"read"`
 	MemoryLimit int64     `json:"memory_limit"`
 	SystemUsage uint64    `json:"system_usage"`
+	Paused      bool      `json:"paused"`
 }
 
 // CommonProcessConfig is the common platform agnostic part of the ProcessConfig
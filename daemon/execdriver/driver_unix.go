@@ -51,6 +51,17 @@ type Resources struct {
 	Rlimits                      []*units.Rlimit            `json:"rlimits"`
 	OomKillDisable               bool                       `json:"oom_kill_disable"`
 	MemorySwappiness             int64                      `json:"memory_swappiness"`
+	PidsLimit                    int64                      `json:"pids_limit"`
+	NetworkRateLimits            []NetworkRateLimit         `json:"network_rate_limits"`
+}
+
+// NetworkRateLimit contains the egress and ingress rate limits, in bytes per
+// second, of a network interface inside the container. A rate of 0 means
+// unlimited.
+type NetworkRateLimit struct {
+	Interface   string `json:"interface"`
+	EgressRate  uint64 `json:"egress_rate"`
+	IngressRate uint64 `json:"ingress_rate"`
 }
 
 // ProcessConfig is the platform specific structure that describes a process
@@ -112,16 +123,20 @@ type Command struct {
 	AppArmorProfile    string            `json:"apparmor_profile"`
 	AutoCreatedDevices []*configs.Device `json:"autocreated_devices"`
 	CapAdd             []string          `json:"cap_add"`
+	Capabilities       []string          `json:"capabilities"` // base capability set CapAdd and CapDrop apply to, the driver default if empty
 	CapDrop            []string          `json:"cap_drop"`
 	CgroupParent       string            `json:"cgroup_parent"` // The parent cgroup for this command.
 	GIDMapping         []idtools.IDMap   `json:"gidmapping"`
 	GroupAdd           []string          `json:"group_add"`
+	InitBinary         string            `json:"init_binary"` // host path of the init run as PID 1 at InitPath, if any
 	Ipc                *Ipc              `json:"ipc"`
+	NoNewPrivileges    bool              `json:"no_new_privileges"`
 	OomScoreAdj        int               `json:"oom_score_adj"`
 	Pid                *Pid              `json:"pid"`
 	ReadonlyRootfs     bool              `json:"readonly_rootfs"`
 	RemappedRoot       *User             `json:"remap_root"`
 	SeccompProfile     string            `json:"seccomp_profile"`
+	Sysctls            map[string]string `json:"sysctls"`
 	UIDMapping         []idtools.IDMap   `json:"uidmapping"`
 	UTS                *UTS              `json:"uts"`
 }
@@ -143,6 +158,8 @@ func InitContainer(c *Command) *configs.Config {
 	container.Devices = c.AutoCreatedDevices
 	container.Rootfs = c.Rootfs
 	container.Readonlyfs = c.ReadonlyRootfs
+	container.Sysctl = c.Sysctls
+	container.NoNewPrivileges = c.NoNewPrivileges
 	// This can be overridden later by driver during mount setup based
 	// on volume options
 	SetRootPropagation(container, mount.RPRIVATE)
@@ -184,6 +201,7 @@ func SetupCgroups(container *configs.Config, c *Command) error {
 		container.Cgroups.Resources.BlkioThrottleWriteIOPSDevice = c.Resources.BlkioThrottleWriteIOpsDevice
 		container.Cgroups.Resources.OomKillDisable = c.Resources.OomKillDisable
 		container.Cgroups.Resources.MemorySwappiness = c.Resources.MemorySwappiness
+		container.Cgroups.Resources.PidsLimit = c.Resources.PidsLimit
 	}
 
 	return nil
@@ -299,4 +317,8 @@ type ExitStatus struct {
 
 	// Whether the container encountered an OOM.
 	OOMKilled bool
+
+	// The resource usage of the container right before it was destroyed,
+	// if it could be read.
+	Stats *ResourceStats
 }
@@ -12,5 +12,5 @@ import (
 
 // NewDriver returns a new execdriver.Driver from the given name configured with the provided options.
 func NewDriver(options []string, root, libPath string, sysInfo *sysinfo.SysInfo) (execdriver.Driver, error) {
-	return native.NewDriver(path.Join(root, "execdriver", "native"), options)
+	return native.NewDriver(path.Join(root, "execdriver", "native"), libPath, options)
 }
@@ -4,8 +4,10 @@ package native
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
@@ -75,11 +77,8 @@ profile {{.Name}} flags=(attach_disconnected,mediate_deleted) {
 }
 `
 
-func generateProfile(out io.Writer) error {
-	compiled, err := template.New("apparmor_profile").Parse(baseTemplate)
-	if err != nil {
-		return err
-	}
+func generateProfile(out io.Writer, compiled *template.Template) error {
+	var err error
 	data := &data{
 		Name: "docker-default",
 	}
@@ -117,7 +116,9 @@ func abstractionsExists() bool {
 	return err == nil
 }
 
-func installAppArmorProfile() error {
+// installAppArmorProfile generates the docker-default profile from the
+// template, and loads it in place of the loaded one.
+func installAppArmorProfile(compiled *template.Template) error {
 	if !apparmor.IsEnabled() {
 		return nil
 	}
@@ -127,15 +128,15 @@ func installAppArmorProfile() error {
 		return err
 	}
 
-	f, err := os.OpenFile(apparmorProfilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
+	// the profile is generated first, so that a template which fails to
+	// execute does not leave a truncated profile behind
+	var profile bytes.Buffer
+	if err := generateProfile(&profile, compiled); err != nil {
 		return err
 	}
-	if err := generateProfile(f); err != nil {
-		f.Close()
+	if err := ioutil.WriteFile(apparmorProfilePath, profile.Bytes(), 0644); err != nil {
 		return err
 	}
-	f.Close()
 
 	cmd := exec.Command("/sbin/apparmor_parser", "-r", "-W", "docker")
 	// to use the parser directly we have to make sure we are in the correct
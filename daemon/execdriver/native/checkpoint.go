@@ -0,0 +1,174 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/utils"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+func criuOpts(imagesDirectory, workDirectory string, leaveRunning bool) *libcontainer.CriuOpts {
+	return &libcontainer.CriuOpts{
+		ImagesDirectory:         imagesDirectory,
+		WorkDirectory:           workDirectory,
+		LeaveRunning:            leaveRunning,
+		ExternalUnixConnections: true,
+		FileLocks:               true,
+	}
+}
+
+// Checkpoint implements the exec driver Driver interface,
+// it calls libcontainer APIs to checkpoint a container with CRIU.
+func (d *Driver) Checkpoint(c *execdriver.Command, opts *execdriver.CheckpointOptions) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+	if active == nil {
+		return fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+	return active.Checkpoint(criuOpts(opts.ImagesDirectory, opts.WorkDirectory, opts.LeaveRunning))
+}
+
+// Restore implements the exec driver Driver interface,
+// it calls libcontainer APIs to restore a container from a checkpoint
+// with CRIU.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, opts *execdriver.RestoreOptions) (execdriver.ExitStatus, error) {
+	destroyed := false
+	var err error
+	c.TmpDir, err = ioutil.TempDir("", c.ID)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	defer os.RemoveAll(c.TmpDir)
+
+	container, err := d.createContainer(c, hooks)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	// The arguments and environment of the process are part of the
+	// checkpoint, only its stdio is set up again.
+	p := &libcontainer.Process{}
+	if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	cont, err := d.factory.Create(c.ID, container)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	d.Lock()
+	d.activeContainers[c.ID] = cont
+	d.Unlock()
+	defer func() {
+		if !destroyed {
+			cont.Destroy()
+		}
+		d.cleanContainer(c.ID)
+	}()
+
+	if err := cont.Restore(p, criuOpts(opts.ImagesDirectory, opts.WorkDirectory, false)); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	if err := restoreNetwork(cont, container, p); err != nil {
+		p.Signal(os.Kill)
+		p.Wait()
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	ps, oomKilled, err := waitForProcess(c, cont, p, hooks)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	stats := d.exitStats(c.ID)
+	cont.Destroy()
+	destroyed = true
+	_, oomKill := <-oomKilled
+	return execdriver.ExitStatus{ExitCode: utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), OOMKilled: oomKill, Stats: stats}, nil
+}
+
+// restoreNetwork connects the restored process p to the networks of the
+// container again. CRIU restores the network namespace of the container with
+// links whose host ends are no longer connected to anything, so they are
+// replaced by the links the daemon sets up in the prestart hooks.
+func restoreNetwork(cont libcontainer.Container, container *configs.Config, p *libcontainer.Process) error {
+	if container.Hooks == nil {
+		// the container shares the network namespace of the host or of
+		// another container
+		return nil
+	}
+	pid, err := p.Pid()
+	if err != nil {
+		return err
+	}
+	if err := removeVethLinks(pid); err != nil {
+		return err
+	}
+	return runPrestartHooks(cont.ID(), container, pid)
+}
+
+// runPrestartHooks runs the prestart hooks of the container config for the
+// process pid, with the state libcontainer passes them when it starts a
+// process. libcontainer does not run them when it restores a process.
+func runPrestartHooks(id string, container *configs.Config, pid int) error {
+	s := configs.HookState{
+		Version: container.Version,
+		ID:      id,
+		Pid:     pid,
+		Root:    container.Rootfs,
+	}
+	for _, hook := range container.Hooks.Prestart {
+		if err := hook.Run(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeVethLinks removes the veth links in the network namespace of pid.
+func removeVethLinks(pid int) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origns, err := netns.Get()
+	if err != nil {
+		return err
+	}
+	defer origns.Close()
+
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	if err := netns.Set(ns); err != nil {
+		return err
+	}
+	defer netns.Set(origns)
+
+	links, err := netlink.LinkList()
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if link.Type() != "veth" {
+			continue
+		}
+		if err := netlink.LinkDel(link); err != nil {
+			return fmt.Errorf("failed to remove restored link %s: %v", link.Attrs().Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestRunPrestartHooks(t *testing.T) {
+	var states []configs.HookState
+	container := &configs.Config{
+		Version: "0.2.0",
+		Rootfs:  "/var/lib/docker/rootfs",
+		Hooks: &configs.Hooks{
+			Prestart: []configs.Hook{
+				configs.NewFunctionHook(func(s configs.HookState) error {
+					states = append(states, s)
+					return nil
+				}),
+			},
+		},
+	}
+
+	if err := runPrestartHooks("test", container, 42); err != nil {
+		t.Fatal(err)
+	}
+	expected := configs.HookState{Version: "0.2.0", ID: "test", Pid: 42, Root: "/var/lib/docker/rootfs"}
+	if len(states) != 1 || states[0] != expected {
+		t.Fatalf("Expected the hook to run with %+v, got %+v", expected, states)
+	}
+}
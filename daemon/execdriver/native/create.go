@@ -71,7 +71,7 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		}
 
 		if c.SeccompProfile == "" {
-			container.Seccomp = getDefaultSeccompProfile()
+			container.Seccomp = d.defaultSeccompProfile()
 		}
 	}
 	// add CAP_ prefix to all caps for new libcontainer update to match
@@ -87,7 +87,15 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		container.AppArmorProfile = c.AppArmorProfile
 	}
 
-	if c.SeccompProfile != "" && c.SeccompProfile != "unconfined" {
+	if name, ok := seccompRecordName(c.SeccompProfile); ok {
+		path, err := seccompRecordPath(d.seccompRecordDir, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := setupSeccompRecording(container, path); err != nil {
+			return nil, err
+		}
+	} else if c.SeccompProfile != "" && c.SeccompProfile != "unconfined" {
 		container.Seccomp, err = loadSeccompProfile(c.SeccompProfile)
 		if err != nil {
 			return nil, err
@@ -117,6 +125,10 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		return nil, err
 	}
 
+	if c.InitBinary != "" {
+		d.setupInit(container, c)
+	}
+
 	d.setupLabels(container, c)
 	d.setupRlimits(container, c)
 	return container, nil
@@ -266,6 +278,9 @@ func (d *Driver) setPrivileged(container *configs.Config) (err error) {
 }
 
 func (d *Driver) setCapabilities(container *configs.Config, c *execdriver.Command) (err error) {
+	if len(c.Capabilities) > 0 {
+		container.Capabilities = c.Capabilities
+	}
 	container.Capabilities, err = execdriver.TweakCapabilities(container.Capabilities, c.CapAdd, c.CapDrop)
 	return err
 }
@@ -506,6 +521,17 @@ func (d *Driver) setupMounts(container *configs.Config, c *execdriver.Command) e
 	return nil
 }
 
+// setupInit bind mounts the init binary of the container at its init path,
+// where it is run as PID 1, see initProcessArgs.
+func (d *Driver) setupInit(container *configs.Config, c *execdriver.Command) {
+	container.Mounts = append(container.Mounts, &configs.Mount{
+		Source:      c.InitBinary,
+		Destination: c.InitPath,
+		Device:      "bind",
+		Flags:       syscall.MS_BIND | syscall.MS_RDONLY,
+	})
+}
+
 func (d *Driver) setupLabels(container *configs.Config, c *execdriver.Command) {
 	container.ProcessLabel = c.ProcessLabel
 	container.MountLabel = c.MountLabel
@@ -22,7 +22,6 @@ import (
 	sysinfo "github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/term"
 	"github.com/opencontainers/runc/libcontainer"
-	"github.com/opencontainers/runc/libcontainer/apparmor"
 	"github.com/opencontainers/runc/libcontainer/cgroups/systemd"
 	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/libcontainer/system"
@@ -39,14 +38,18 @@ const (
 // it implements execdriver.Driver.
 type Driver struct {
 	root             string
+	seccompRecordDir string
 	activeContainers map[string]libcontainer.Container
 	machineMemory    int64
 	factory          libcontainer.Factory
+	profiles         securityProfiles
 	sync.Mutex
 }
 
 // NewDriver returns a new native driver, called from NewDriver of execdriver.
-func NewDriver(root string, options []string) (*Driver, error) {
+// The seccomp profiles recorded for the containers are written in libPath,
+// the root directory of the daemon.
+func NewDriver(root, libPath string, options []string) (*Driver, error) {
 	meminfo, err := sysinfo.ReadMemInfo()
 	if err != nil {
 		return nil, err
@@ -56,25 +59,13 @@ func NewDriver(root string, options []string) (*Driver, error) {
 		return nil, err
 	}
 
-	if apparmor.IsEnabled() {
-		if err := installAppArmorProfile(); err != nil {
-			apparmorProfiles := []string{"docker-default"}
-
-			// Allow daemon to run if loading failed, but are active
-			// (possibly through another run, manually, or via system startup)
-			for _, policy := range apparmorProfiles {
-				if err := hasAppArmorProfileLoaded(policy); err != nil {
-					return nil, fmt.Errorf("AppArmor enabled on system but the %s profile could not be loaded.", policy)
-				}
-			}
-		}
-	}
-
 	// choose cgroup manager
 	// this makes sure there are no breaking changes to people
 	// who upgrade from versions without native.cgroupdriver opt
 	cgm := libcontainer.Cgroupfs
 
+	var seccompProfile, apparmorTemplate string
+
 	// parse the options
 	for _, option := range options {
 		key, val, err := parsers.ParseKeyValueOpt(option)
@@ -98,6 +89,10 @@ func NewDriver(root string, options []string) (*Driver, error) {
 			default:
 				return nil, fmt.Errorf("Unknown native.cgroupdriver given %q. try cgroupfs or systemd", val)
 			}
+		case "native.seccompprofile":
+			seccompProfile = val
+		case "native.apparmortemplate":
+			apparmorTemplate = val
 		default:
 			return nil, fmt.Errorf("Unknown option %s\n", key)
 		}
@@ -112,12 +107,19 @@ func NewDriver(root string, options []string) (*Driver, error) {
 		return nil, err
 	}
 
-	return &Driver{
+	d := &Driver{
 		root:             root,
+		seccompRecordDir: filepath.Join(libPath, "seccomp"),
 		activeContainers: make(map[string]libcontainer.Container),
 		machineMemory:    meminfo.MemTotal,
 		factory:          f,
-	}, nil
+	}
+	d.profiles.seccompPath = seccompProfile
+	d.profiles.apparmorPath = apparmorTemplate
+	if err := d.loadSecurityProfiles(true); err != nil {
+		return nil, err
+	}
+	return d, nil
 }
 
 type execOutput struct {
@@ -143,7 +145,7 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 	}
 
 	p := &libcontainer.Process{
-		Args: append([]string{c.ProcessConfig.Entrypoint}, c.ProcessConfig.Arguments...),
+		Args: initProcessArgs(c),
 		Env:  c.ProcessConfig.Env,
 		Cwd:  c.WorkingDir,
 		User: c.ProcessConfig.User,
@@ -171,16 +173,67 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
+	ps, oomKilled, err := waitForProcess(c, cont, p, hooks)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	stats := d.exitStats(c.ID)
+	cont.Destroy()
+	destroyed = true
+	// oomKilled will have an oom event if any process within the container was
+	// OOM killed at any time, not only if the init process OOMed.
+	//
+	// Perhaps we only want the OOMKilled flag to be set if the OOM
+	// resulted in a container death, but there isn't a good way to do this
+	// because the kernel's cgroup oom notification does not provide information
+	// such as the PID. This could be heuristically done by checking that the OOM
+	// happened within some very small time slice for the container dying (and
+	// optionally exit-code 137), but I don't think the cgroup oom notification
+	// can be used to reliably determine this
+	//
+	// Even if there were multiple OOMs, it's sufficient to read one value
+	// because libcontainer's oom notify will discard the channel after the
+	// cgroup is destroyed
+	_, oomKill := <-oomKilled
+	return execdriver.ExitStatus{ExitCode: utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), OOMKilled: oomKill, Stats: stats}, nil
+}
+
+// initProcessArgs returns the arguments of the init process of the
+// container. If the container has an init binary, it runs the entrypoint of
+// the container as its child.
+func initProcessArgs(c *execdriver.Command) []string {
+	args := append([]string{c.ProcessConfig.Entrypoint}, c.ProcessConfig.Arguments...)
+	if c.InitBinary != "" {
+		args = append([]string{c.InitPath}, args...)
+	}
+	return args
+}
+
+// waitForProcess sets the network rate limits of the started process p of
+// cont, calls the start hook for it, and waits for it to exit. The returned
+// channel signals if any process of the container was OOM killed.
+func waitForProcess(c *execdriver.Command, cont libcontainer.Container, p *libcontainer.Process, hooks execdriver.Hooks) (*os.ProcessState, <-chan struct{}, error) {
 	// 'oom' is used to emit 'oom' events to the eventstream, 'oomKilled' is used
 	// to set the 'OOMKilled' flag in state
 	oom := notifyOnOOM(cont)
 	oomKilled := notifyOnOOM(cont)
+	if c.Resources != nil && len(c.Resources.NetworkRateLimits) > 0 {
+		pid, err := p.Pid()
+		if err == nil {
+			err = setNetworkRateLimits(pid, c.Resources.NetworkRateLimits)
+		}
+		if err != nil {
+			p.Signal(os.Kill)
+			p.Wait()
+			return nil, nil, err
+		}
+	}
 	if hooks.Start != nil {
 		pid, err := p.Pid()
 		if err != nil {
 			p.Signal(os.Kill)
 			p.Wait()
-			return execdriver.ExitStatus{ExitCode: -1}, err
+			return nil, nil, err
 		}
 		hooks.Start(&c.ProcessConfig, pid, oom)
 	}
@@ -195,28 +248,11 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 	if err != nil {
 		execErr, ok := err.(*exec.ExitError)
 		if !ok {
-			return execdriver.ExitStatus{ExitCode: -1}, err
+			return nil, nil, err
 		}
 		ps = execErr.ProcessState
 	}
-	cont.Destroy()
-	destroyed = true
-	// oomKilled will have an oom event if any process within the container was
-	// OOM killed at any time, not only if the init process OOMed.
-	//
-	// Perhaps we only want the OOMKilled flag to be set if the OOM
-	// resulted in a container death, but there isn't a good way to do this
-	// because the kernel's cgroup oom notification does not provide information
-	// such as the PID. This could be heuristically done by checking that the OOM
-	// happened within some very small time slice for the container dying (and
-	// optionally exit-code 137), but I don't think the cgroup oom notification
-	// can be used to reliably determine this
-	//
-	// Even if there were multiple OOMs, it's sufficient to read one value
-	// because libcontainer's oom notify will discard the channel after the
-	// cgroup is destroyed
-	_, oomKill := <-oomKilled
-	return execdriver.ExitStatus{ExitCode: utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), OOMKilled: oomKill}, nil
+	return ps, oomKilled, nil
 }
 
 // notifyOnOOM returns a channel that signals if the container received an OOM notification
@@ -413,6 +449,17 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 	}, nil
 }
 
+// exitStats returns the resource usage of the container id whose process
+// exited, before its cgroups are destroyed, or nil if it cannot be read.
+func (d *Driver) exitStats(id string) *execdriver.ResourceStats {
+	stats, err := d.Stats(id)
+	if err != nil {
+		logrus.Debugf("Cannot read the resource usage of exited container %s: %v", id, err)
+		return nil
+	}
+	return stats
+}
+
 // Update updates configs for a container
 func (d *Driver) Update(c *execdriver.Command) error {
 	d.Lock()
@@ -426,13 +473,45 @@ func (d *Driver) Update(c *execdriver.Command) error {
 		return err
 	}
 
+	prevRlimits := config.Rlimits
+	config.Rlimits = nil
+	d.setupRlimits(&config, c)
+	if err := updateRlimits(cont, prevRlimits, config.Rlimits); err != nil {
+		return err
+	}
+
 	if err := cont.Set(config); err != nil {
 		return err
 	}
 
+	if c.Resources != nil {
+		state, err := cont.State()
+		if err != nil {
+			return err
+		}
+		if err := setNetworkRateLimits(state.InitProcessPid, c.Resources.NetworkRateLimits); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// UpdateDevices implements the exec driver Driver interface, it replaces the
+// devices the running container is allowed to access. The other resources
+// of the container are left as they are.
+func (d *Driver) UpdateDevices(c *execdriver.Command) error {
+	d.Lock()
+	cont := d.activeContainers[c.ID]
+	d.Unlock()
+	if cont == nil {
+		return execdriver.ErrNotRunning
+	}
+	config := cont.Config()
+	config.Cgroups.Resources.AllowedDevices = c.AllowedDevices
+	return cont.Set(config)
+}
+
 // TtyConsole implements the exec driver Terminal interface.
 type TtyConsole struct {
 	console libcontainer.Console
@@ -9,6 +9,6 @@ import (
 )
 
 // NewDriver returns a new native driver, called from NewDriver of execdriver.
-func NewDriver(root string, options []string) (execdriver.Driver, error) {
+func NewDriver(root, libPath string, options []string) (execdriver.Driver, error) {
 	return nil, fmt.Errorf("native driver not supported on non-linux")
 }
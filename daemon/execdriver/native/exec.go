@@ -25,6 +25,11 @@ func (d *Driver) Exec(c *execdriver.Command, processConfig *execdriver.ProcessCo
 	if active == nil {
 		return -1, fmt.Errorf("No active container exists with ID %s", c.ID)
 	}
+	// the syscalls of the processes started in the container would fail, as
+	// the recorder does not trace them
+	if _, ok := seccompRecordName(c.SeccompProfile); ok {
+		return -1, fmt.Errorf("Cannot exec in container %s while its seccomp profile is being recorded", c.ID)
+	}
 
 	user := processConfig.User
 	if c.RemappedRoot.UID != 0 && user == "" {
@@ -0,0 +1,125 @@
+// +build linux
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"text/template"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer/apparmor"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// builtinProfile is the source of the profiles built into the daemon.
+const builtinProfile = "default"
+
+// securityProfiles holds the default security profiles of the containers.
+type securityProfiles struct {
+	sync.Mutex
+	// seccompPath and apparmorPath are the files the seccomp profile and
+	// the AppArmor template are loaded from, if they are not the built-in
+	// ones.
+	seccompPath  string
+	apparmorPath string
+	// seccomp is the loaded seccomp profile.
+	seccomp *configs.Seccomp
+}
+
+// loadSecurityProfiles loads the default seccomp profile and AppArmor
+// template from the files set in the driver options, or the built-in ones,
+// and installs the docker-default AppArmor profile. They are validated
+// before any of them is used, so that an invalid file leaves the profiles
+// loaded before in place.
+func (d *Driver) loadSecurityProfiles(startup bool) error {
+	d.profiles.Lock()
+	defer d.profiles.Unlock()
+
+	seccomp := getDefaultSeccompProfile()
+	if path := d.profiles.seccompPath; path != "" {
+		if seccomp == nil {
+			return fmt.Errorf("Cannot load the seccomp profile %s: seccomp is not supported by this build", path)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if seccomp, err = loadSeccompProfile(string(b)); err != nil {
+			return fmt.Errorf("Error loading the seccomp profile %s: %v", path, err)
+		}
+	}
+
+	tmpl := baseTemplate
+	if path := d.profiles.apparmorPath; path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tmpl = string(b)
+		if startup && !apparmor.IsEnabled() {
+			logrus.Warnf("AppArmor is not enabled on the system, the template %s is not used", path)
+		}
+	}
+	compiled, err := template.New("apparmor_profile").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("Error parsing the AppArmor template %s: %v", profileSource(d.profiles.apparmorPath), err)
+	}
+	if err := installAppArmorProfile(compiled); err != nil {
+		// Allow daemon to run if loading the built-in profile failed, but
+		// it is active (possibly through another run, manually, or via
+		// system startup)
+		if !startup || d.profiles.apparmorPath != "" || hasAppArmorProfileLoaded("docker-default") != nil {
+			return fmt.Errorf("AppArmor enabled on system but the docker-default profile could not be loaded: %v", err)
+		}
+	}
+
+	d.profiles.seccomp = seccomp
+	return nil
+}
+
+// defaultSeccompProfile returns the seccomp profile of the containers which
+// do not set one.
+func (d *Driver) defaultSeccompProfile() *configs.Seccomp {
+	d.profiles.Lock()
+	defer d.profiles.Unlock()
+	return d.profiles.seccomp
+}
+
+// SecurityProfiles implements the execdriver SecurityProfiler interface.
+func (d *Driver) SecurityProfiles() []execdriver.SecurityProfile {
+	d.profiles.Lock()
+	defer d.profiles.Unlock()
+
+	var profiles []execdriver.SecurityProfile
+	if apparmor.IsEnabled() {
+		profiles = append(profiles, execdriver.SecurityProfile{
+			Name:    "apparmor",
+			Profile: profileSource(d.profiles.apparmorPath),
+		})
+	}
+	if d.profiles.seccomp != nil {
+		profiles = append(profiles, execdriver.SecurityProfile{
+			Name:    "seccomp",
+			Profile: profileSource(d.profiles.seccompPath),
+		})
+	}
+	return profiles
+}
+
+// ReloadSecurityProfiles implements the execdriver SecurityProfiler
+// interface.
+func (d *Driver) ReloadSecurityProfiles() error {
+	return d.loadSecurityProfiles(false)
+}
+
+// profileSource returns the file a profile is loaded from, or default for
+// the built-in profile.
+func profileSource(path string) string {
+	if path == "" {
+		return builtinProfile
+	}
+	return path
+}
@@ -0,0 +1,141 @@
+// +build linux
+
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+const (
+	allowProfile = `{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"name": "chmod", "action": "SCMP_ACT_ERRNO"}]}`
+	denyProfile  = `{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "read", "action": "SCMP_ACT_ALLOW"}]}`
+)
+
+// newProfilesDriver returns a driver loading its profiles from files in a
+// temporary directory. Loading a seccomp profile only converts it, so
+// builds without seccomp support are given a built-in profile to load one.
+func newProfilesDriver(t *testing.T) (*Driver, func()) {
+	dir, err := ioutil.TempDir("", "security-profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builtin := defaultSeccompProfile
+	if defaultSeccompProfile == nil {
+		defaultSeccompProfile = &configs.Seccomp{}
+	}
+	d := &Driver{}
+	d.profiles.seccompPath = filepath.Join(dir, "seccomp.json")
+	d.profiles.apparmorPath = filepath.Join(dir, "apparmor.tmpl")
+	writeProfile(t, d.profiles.seccompPath, allowProfile)
+	writeProfile(t, d.profiles.apparmorPath, baseTemplate)
+	return d, func() {
+		defaultSeccompProfile = builtin
+		os.RemoveAll(dir)
+	}
+}
+
+func writeProfile(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkDefaultAction(t *testing.T, d *Driver, action configs.Action) {
+	seccomp := d.defaultSeccompProfile()
+	if seccomp == nil {
+		t.Fatal("Expected a seccomp profile to be loaded")
+	}
+	if seccomp.DefaultAction != action {
+		t.Fatalf("Expected the default action of the seccomp profile to be %v, got %v", action, seccomp.DefaultAction)
+	}
+}
+
+func TestReloadSecurityProfiles(t *testing.T) {
+	d, cleanup := newProfilesDriver(t)
+	defer cleanup()
+
+	if err := d.loadSecurityProfiles(true); err != nil {
+		t.Fatal(err)
+	}
+	checkDefaultAction(t, d, configs.Allow)
+
+	writeProfile(t, d.profiles.seccompPath, denyProfile)
+	if err := d.ReloadSecurityProfiles(); err != nil {
+		t.Fatal(err)
+	}
+	checkDefaultAction(t, d, configs.Errno)
+
+	var seccompProfile string
+	for _, p := range d.SecurityProfiles() {
+		if p.Name == "seccomp" {
+			seccompProfile = p.Profile
+		}
+	}
+	if seccompProfile != d.profiles.seccompPath {
+		t.Fatalf("Expected the seccomp profile to be loaded from %s, got %q", d.profiles.seccompPath, seccompProfile)
+	}
+}
+
+func TestReloadSecurityProfilesInvalidSeccomp(t *testing.T) {
+	d, cleanup := newProfilesDriver(t)
+	defer cleanup()
+
+	if err := d.loadSecurityProfiles(true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profile := range []string{
+		`{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [`,
+		`{"defaultAction": "SCMP_ACT_UNKNOWN"}`,
+	} {
+		writeProfile(t, d.profiles.seccompPath, profile)
+		if err := d.ReloadSecurityProfiles(); err == nil {
+			t.Fatalf("Expected an error reloading the seccomp profile %s", profile)
+		}
+		checkDefaultAction(t, d, configs.Allow)
+	}
+
+	os.Remove(d.profiles.seccompPath)
+	if err := d.ReloadSecurityProfiles(); err == nil {
+		t.Fatal("Expected an error reloading a missing seccomp profile")
+	}
+	checkDefaultAction(t, d, configs.Allow)
+}
+
+func TestReloadSecurityProfilesInvalidAppArmor(t *testing.T) {
+	d, cleanup := newProfilesDriver(t)
+	defer cleanup()
+
+	if err := d.loadSecurityProfiles(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// the valid seccomp profile is not used either
+	writeProfile(t, d.profiles.seccompPath, denyProfile)
+	writeProfile(t, d.profiles.apparmorPath, "profile {{.Name}")
+	if err := d.ReloadSecurityProfiles(); err == nil {
+		t.Fatal("Expected an error reloading an invalid AppArmor template")
+	}
+	checkDefaultAction(t, d, configs.Allow)
+}
+
+func TestSecurityProfilesBuiltin(t *testing.T) {
+	d, cleanup := newProfilesDriver(t)
+	defer cleanup()
+
+	d.profiles.seccompPath = ""
+	d.profiles.apparmorPath = ""
+	if err := d.loadSecurityProfiles(true); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range d.SecurityProfiles() {
+		if p.Profile != builtinProfile {
+			t.Fatalf("Expected the built-in %s profile, got %s", p.Name, p.Profile)
+		}
+	}
+}
@@ -33,6 +33,12 @@ func arches() []string {
 	}
 }
 
+// syscallName returns the name of the syscall number nr of the native
+// architecture.
+func syscallName(nr int) (string, error) {
+	return libseccomp.ScmpSyscall(nr).GetName()
+}
+
 var defaultSeccompProfile = &configs.Seccomp{
 	DefaultAction: configs.Errno,
 	Architectures: arches(),
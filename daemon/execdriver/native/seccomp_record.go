@@ -0,0 +1,320 @@
+// +build linux
+
+package native
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+const (
+	// seccompRecordPrefix prefixes the name of the profile to write in a
+	// seccomp security option, as in --security-opt seccomp:record:<name>.
+	seccompRecordPrefix = "record:"
+
+	// seccompRecorder is the name the recorder is run under.
+	seccompRecorder = "docker-seccomp-record"
+
+	// maxRecordedArgValues is the number of distinct values of a recorded
+	// argument above which the syscall is allowed whatever the argument.
+	maxRecordedArgValues = 8
+
+	// syscallArgs is the number of arguments of a syscall.
+	syscallArgs = 6
+)
+
+// Ptrace requests, options and events missing from the syscall package.
+const (
+	ptraceSeize         = 0x4206
+	ptraceOTraceSeccomp = 0x80
+	ptraceEventSeccomp  = 7
+	ptraceEventStop     = 128
+)
+
+// ptraceRecordOptions trace the processes the traced processes start, and
+// stop them at the syscalls the seccomp profile traces.
+const ptraceRecordOptions = syscall.PTRACE_O_TRACEFORK | syscall.PTRACE_O_TRACEVFORK | syscall.PTRACE_O_TRACECLONE | ptraceOTraceSeccomp
+
+// recordedArgs are the arguments whose values are recorded along with the
+// syscall, by syscall name. These are the arguments that select what the
+// syscall does rather than what it operates on.
+var recordedArgs = map[string]uint{
+	"personality": 0,
+	"socket":      0,
+}
+
+func init() {
+	reexec.Register(seccompRecorder, seccompRecorderMain)
+}
+
+var validSeccompRecordName = regexp.MustCompile(`^` + utils.RestrictedNameChars + `+$`)
+
+// seccompRecordName returns the name of the profile to write if the seccomp
+// profile of a container asks for its syscalls to be recorded.
+func seccompRecordName(profile string) (string, bool) {
+	if !strings.HasPrefix(profile, seccompRecordPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(profile, seccompRecordPrefix), true
+}
+
+// seccompRecordPath returns the path of the recorded profile name in dir, the
+// directory of the recorded profiles.
+func seccompRecordPath(dir, name string) (string, error) {
+	if !validSeccompRecordName.MatchString(name) {
+		return "", fmt.Errorf("Invalid name of the recorded seccomp profile %q, only %s are allowed", name, utils.RestrictedNameChars)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// setupSeccompRecording runs the container with a profile which traces all
+// the syscalls, and starts a recorder tracing the container before its init
+// loads the profile. Once the container exits, the recorder writes a profile
+// allowing the syscalls the container made to path.
+func setupSeccompRecording(container *configs.Config, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	container.Seccomp = &configs.Seccomp{
+		DefaultAction: configs.Trace,
+		Syscalls:      []*configs.Syscall{},
+	}
+	if container.Hooks == nil {
+		container.Hooks = &configs.Hooks{}
+	}
+	container.Hooks.Prestart = append(container.Hooks.Prestart, configs.NewFunctionHook(func(s configs.HookState) error {
+		return startSeccompRecorder(s.Pid, path)
+	}))
+	return nil
+}
+
+// startSeccompRecorder starts the recorder for the container init pid, and
+// waits for it to trace the process.
+func startSeccompRecorder(pid int, path string) error {
+	cmd := reexec.Command(seccompRecorder, strconv.Itoa(pid), path)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if _, err := bufio.NewReader(stdout).ReadString('\n'); err != nil {
+		cmd.Wait()
+		return fmt.Errorf("Recording the syscalls of process %d failed", pid)
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			logrus.Errorf("Error recording the seccomp profile %s: %v", path, err)
+		}
+	}()
+	return nil
+}
+
+// seccompRecorderMain traces the process given in its arguments and its
+// descendants, records the syscalls they make, and writes a profile
+// allowing them to the path given in its arguments once they have exited.
+// It writes a line to its stdout once the process is traced.
+func seccompRecorderMain() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: docker-seccomp-record PID PATH")
+		os.Exit(1)
+	}
+	pid, err := strconv.Atoi(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// all the ptrace requests must come from the thread which traces
+	runtime.LockOSThread()
+	if _, _, errno := syscall.Syscall6(syscall.SYS_PTRACE, ptraceSeize, uintptr(pid), 0, ptraceRecordOptions, 0, 0); errno != 0 {
+		fmt.Fprintf(os.Stderr, "Tracing process %d failed: %v\n", pid, errno)
+		os.Exit(1)
+	}
+	fmt.Println("tracing")
+
+	r := newSyscallRecorder()
+	if err := r.trace(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := r.writeProfile(os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// syscallRecorder records the syscalls made by the traced processes, and
+// the values of their recorded arguments, by syscall name.
+type syscallRecorder struct {
+	names    map[int]string
+	syscalls map[string]map[uint64]bool
+}
+
+func newSyscallRecorder() *syscallRecorder {
+	return &syscallRecorder{
+		names:    make(map[int]string),
+		syscalls: make(map[string]map[uint64]bool),
+	}
+}
+
+// trace resumes the traced processes until they have all exited, recording
+// the syscalls they stop at. The processes they start are traced as well.
+func (r *syscallRecorder) trace() error {
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WALL, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err == syscall.ECHILD {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !status.Stopped() {
+			continue
+		}
+
+		var sig int
+		switch uint32(status) >> 16 {
+		case ptraceEventSeccomp:
+			if err := r.record(pid); err != nil {
+				return err
+			}
+		case syscall.PTRACE_EVENT_FORK, syscall.PTRACE_EVENT_VFORK, syscall.PTRACE_EVENT_CLONE:
+		case ptraceEventStop:
+			// the processes started by a traced process begin with this
+			// stop, and so do group-stops, which are not kept
+		default:
+			sig = int(status.StopSignal())
+		}
+		// the process may have been killed while stopped
+		syscall.PtraceCont(pid, sig)
+	}
+}
+
+// record records the syscall the process pid is stopped at.
+func (r *syscallRecorder) record(pid int) error {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/syscall", pid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	nr, args, err := parseProcSyscall(string(b))
+	if err != nil {
+		return err
+	}
+	name, ok := r.names[nr]
+	if !ok {
+		if name, err = syscallName(nr); err != nil {
+			return err
+		}
+		r.names[nr] = name
+		r.syscalls[name] = make(map[uint64]bool)
+	}
+	values := r.syscalls[name]
+	if index, ok := recordedArgs[name]; ok && len(values) <= maxRecordedArgValues {
+		values[args[index]] = true
+	}
+	return nil
+}
+
+// parseProcSyscall parses the content of /proc/<pid>/syscall for a process
+// stopped at a syscall: the syscall number followed by its arguments.
+func parseProcSyscall(s string) (int, []uint64, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 1+syscallArgs {
+		return 0, nil, fmt.Errorf("Process is not stopped at a syscall: %q", s)
+	}
+	nr, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	args := make([]uint64, syscallArgs)
+	for i := range args {
+		if args[i], err = strconv.ParseUint(strings.TrimPrefix(fields[1+i], "0x"), 16, 64); err != nil {
+			return 0, nil, err
+		}
+	}
+	return nr, args, nil
+}
+
+// profile returns a profile which allows the recorded syscalls, with the
+// recorded argument values if there are few of them, and denies the others.
+func (r *syscallRecorder) profile() *types.Seccomp {
+	profile := &types.Seccomp{
+		DefaultAction: types.ActErrno,
+		Syscalls:      []*types.Syscall{},
+	}
+	for name, values := range r.syscalls {
+		index, ok := recordedArgs[name]
+		if !ok || len(values) > maxRecordedArgValues {
+			profile.Syscalls = append(profile.Syscalls, &types.Syscall{
+				Name:   name,
+				Action: types.ActAllow,
+				Args:   []*types.Arg{},
+			})
+			continue
+		}
+		for value := range values {
+			profile.Syscalls = append(profile.Syscalls, &types.Syscall{
+				Name:   name,
+				Action: types.ActAllow,
+				Args: []*types.Arg{
+					{
+						Index: index,
+						Value: value,
+						Op:    types.OpEqualTo,
+					},
+				},
+			})
+		}
+	}
+	sort.Sort(syscallsByName(profile.Syscalls))
+	return profile
+}
+
+// writeProfile writes the profile of the recorded syscalls to path.
+func (r *syscallRecorder) writeProfile(path string) error {
+	if len(r.syscalls) == 0 {
+		return fmt.Errorf("No syscall was recorded, not writing the seccomp profile %s", path)
+	}
+	b, err := json.MarshalIndent(r.profile(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+type syscallsByName []*types.Syscall
+
+func (s syscallsByName) Len() int      { return len(s) }
+func (s syscallsByName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s syscallsByName) Less(i, j int) bool {
+	if s[i].Name != s[j].Name {
+		return s[i].Name < s[j].Name
+	}
+	return len(s[i].Args) > 0 && len(s[j].Args) > 0 && s[i].Args[0].Value < s[j].Args[0].Value
+}
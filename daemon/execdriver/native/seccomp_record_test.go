@@ -0,0 +1,91 @@
+// +build linux
+
+package native
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestSeccompRecordName(t *testing.T) {
+	if _, ok := seccompRecordName("default"); ok {
+		t.Fatal("Expected default not to be recorded")
+	}
+	name, ok := seccompRecordName("record:web")
+	if !ok || name != "web" {
+		t.Fatalf("Expected web to be recorded, got %q, %v", name, ok)
+	}
+}
+
+func TestSeccompRecordPath(t *testing.T) {
+	path, err := seccompRecordPath("/var/lib/docker/seccomp", "web_1.0-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/var/lib/docker/seccomp/web_1.0-x.json" {
+		t.Fatalf("Unexpected path %q", path)
+	}
+	for _, name := range []string{"", "/etc/passwd", "../web", "web/profile", ".web", "-web"} {
+		if path, err := seccompRecordPath("/var/lib/docker/seccomp", name); err == nil {
+			t.Fatalf("Expected an error for %q, got %q", name, path)
+		}
+	}
+}
+
+func TestParseProcSyscall(t *testing.T) {
+	nr, args, err := parseProcSyscall("41 0x2 0x80002 0x0 0x7ffd2c0e8f40 0x0 0x7f1c 0x7ffd2c0e8e88 0x7f1c2f9b4a37\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nr != 41 {
+		t.Fatalf("Expected syscall 41, got %d", nr)
+	}
+	expected := []uint64{0x2, 0x80002, 0x0, 0x7ffd2c0e8f40, 0x0, 0x7f1c}
+	if fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Fatalf("Expected arguments %v, got %v", expected, args)
+	}
+
+	for _, s := range []string{"running", "-1 0x7ffd2c0e8e88 0x7f1c2f9b4a37", "41 0x2 0x80002 0x0 0x0 0x0 zz 0x0 0x0"} {
+		if _, _, err := parseProcSyscall(s); err == nil {
+			t.Fatalf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestSyscallRecorderProfile(t *testing.T) {
+	r := newSyscallRecorder()
+	r.syscalls["read"] = map[uint64]bool{}
+	r.syscalls["personality"] = map[uint64]bool{0x8: true, 0x0: true}
+	r.syscalls["socket"] = map[uint64]bool{}
+	for i := 0; i <= maxRecordedArgValues; i++ {
+		r.syscalls["socket"][uint64(i)] = true
+	}
+
+	profile := r.profile()
+	if profile.DefaultAction != types.ActErrno {
+		t.Fatalf("Expected the default action to be %s, got %s", types.ActErrno, profile.DefaultAction)
+	}
+	var syscalls []string
+	for _, s := range profile.Syscalls {
+		if s.Action != types.ActAllow {
+			t.Fatalf("Expected %s to be allowed, got %s", s.Name, s.Action)
+		}
+		args := ""
+		for _, arg := range s.Args {
+			args += fmt.Sprintf("(%d %s %d)", arg.Index, arg.Op, arg.Value)
+		}
+		syscalls = append(syscalls, s.Name+args)
+	}
+	// personality keeps its values, socket had too many and is folded
+	expected := []string{
+		"personality(0 SCMP_CMP_EQ 0)",
+		"personality(0 SCMP_CMP_EQ 8)",
+		"read",
+		"socket",
+	}
+	if fmt.Sprint(syscalls) != fmt.Sprint(expected) {
+		t.Fatalf("Expected syscalls %v, got %v", expected, syscalls)
+	}
+}
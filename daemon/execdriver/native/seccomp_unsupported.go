@@ -2,8 +2,18 @@
 
 package native
 
-import "github.com/opencontainers/runc/libcontainer/configs"
+import (
+	"fmt"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
 
 var (
 	defaultSeccompProfile *configs.Seccomp
 )
+
+// syscallName returns the name of the syscall number nr of the native
+// architecture.
+func syscallName(nr int) (string, error) {
+	return "", fmt.Errorf("Resolving syscall %d requires seccomp support", nr)
+}
@@ -0,0 +1,132 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/system"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// minRateLimitBurst is the smallest burst of a rate limit, in bytes. It has
+// to be larger than the MTU of the interface for any packet to pass.
+const minRateLimitBurst = 16 * 1024
+
+// updateRlimits sets the rlimits in config that differ from prev on all the
+// processes of the running container cont.
+func updateRlimits(cont libcontainer.Container, prev, rlimits []configs.Rlimit) error {
+	pids, err := cont.Processes()
+	if err != nil {
+		return err
+	}
+next:
+	for _, rl := range rlimits {
+		for _, p := range prev {
+			if p == rl {
+				continue next
+			}
+		}
+		limit := syscall.Rlimit{Cur: rl.Soft, Max: rl.Hard}
+		for _, pid := range pids {
+			// The process may have exited in the meantime.
+			if err := system.Prlimit(pid, rl.Type, &limit, nil); err != nil && err != syscall.ESRCH {
+				return fmt.Errorf("Cannot set rlimit %d of process %d: %v", rl.Type, pid, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setNetworkRateLimits sets the rate limits of the network interfaces of the
+// container with the init process pid. Egress traffic is shaped by a token
+// bucket filter on the interface in the container, ingress traffic on the
+// host end of its veth pair. A rate of 0 removes the limit.
+func setNetworkRateLimits(pid int, limits []execdriver.NetworkRateLimit) error {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origns, err := netns.Get()
+	if err != nil {
+		return err
+	}
+	defer origns.Close()
+
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	if err := netns.Set(ns); err != nil {
+		return err
+	}
+	defer netns.Set(origns)
+
+	peers := make([]int, len(limits))
+	for i, l := range limits {
+		link, err := netlink.LinkByName(l.Interface)
+		if err != nil {
+			return fmt.Errorf("Cannot find network interface %s in the container: %v", l.Interface, err)
+		}
+		if err := setRateLimit(link, l.EgressRate); err != nil {
+			return fmt.Errorf("Cannot set the egress rate limit of %s: %v", l.Interface, err)
+		}
+		peers[i] = link.Attrs().ParentIndex
+	}
+
+	if err := netns.Set(origns); err != nil {
+		return err
+	}
+	for i, l := range limits {
+		peer, err := netlink.LinkByIndex(peers[i])
+		if err != nil || peer.Type() != "veth" {
+			if l.IngressRate == 0 {
+				continue
+			}
+			return fmt.Errorf("Cannot set the ingress rate limit of %s, it is not connected to the host by a veth pair", l.Interface)
+		}
+		if err := setRateLimit(peer, l.IngressRate); err != nil {
+			return fmt.Errorf("Cannot set the ingress rate limit of %s: %v", l.Interface, err)
+		}
+	}
+	return nil
+}
+
+// setRateLimit replaces the root qdisc of link with a token bucket filter
+// limiting its egress to rate bytes per second, or removes it if rate is 0.
+func setRateLimit(link netlink.Link, rate uint64) error {
+	qdisc := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+	}
+	if rate == 0 {
+		// There is nothing to remove if the interface was not limited.
+		netlink.QdiscDel(qdisc)
+		return nil
+	}
+
+	// Allow bursts of 10ms at the full rate, and queue up to 50ms of
+	// traffic on top of that.
+	burst := rate / 100
+	if burst < minRateLimitBurst {
+		burst = minRateLimitBurst
+	}
+	qdisc.Rate = rate
+	qdisc.Buffer = uint32(netlink.Xmittime(rate, uint32(burst)))
+	qdisc.Limit = uint32(rate/20 + burst)
+	return netlink.QdiscReplace(qdisc)
+}
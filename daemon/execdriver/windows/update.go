@@ -12,3 +12,8 @@ import (
 func (d *Driver) Update(c *execdriver.Command) error {
 	return fmt.Errorf("Windows: Update not implemented")
 }
+
+// UpdateDevices replaces the devices a container is allowed to access.
+func (d *Driver) UpdateDevices(c *execdriver.Command) error {
+	return fmt.Errorf("Windows: UpdateDevices not implemented")
+}
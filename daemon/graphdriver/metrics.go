@@ -0,0 +1,86 @@
+package graphdriver
+
+import (
+	"time"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/metrics"
+)
+
+// driverCalls records the latency of the calls to the graph driver.
+var driverCalls = metrics.NewHistogram(
+	"engine_daemon_graphdriver_seconds",
+	"The number of seconds it takes to process each graph driver call",
+	metrics.DefBuckets,
+	"driver", "method",
+)
+
+func init() {
+	metrics.MustRegister(driverCalls)
+}
+
+// meteredDriver is a Driver that records the latency of the calls to the
+// driver it wraps.
+type meteredDriver struct {
+	Driver
+}
+
+// NewMeteredDriver returns a Driver that records the latency of the calls to
+// driver in the daemon metrics. Use Unwrap to get driver back, for instance to
+// check for optional interfaces it implements.
+func NewMeteredDriver(driver Driver) Driver {
+	return &meteredDriver{driver}
+}
+
+// Unwrap returns the driver a metered driver wraps, or driver itself if it is
+// not metered.
+func Unwrap(driver Driver) Driver {
+	if d, ok := driver.(*meteredDriver); ok {
+		return d.Driver
+	}
+	return driver
+}
+
+func (d *meteredDriver) observe(start time.Time, method string) {
+	driverCalls.ObserveSince(start, d.Driver.String(), method)
+}
+
+func (d *meteredDriver) Create(id, parent, mountLabel string) error {
+	defer d.observe(time.Now(), "create")
+	return d.Driver.Create(id, parent, mountLabel)
+}
+
+func (d *meteredDriver) Remove(id string) error {
+	defer d.observe(time.Now(), "remove")
+	return d.Driver.Remove(id)
+}
+
+func (d *meteredDriver) Get(id, mountLabel string) (string, error) {
+	defer d.observe(time.Now(), "get")
+	return d.Driver.Get(id, mountLabel)
+}
+
+func (d *meteredDriver) Put(id string) error {
+	defer d.observe(time.Now(), "put")
+	return d.Driver.Put(id)
+}
+
+func (d *meteredDriver) Diff(id, parent string) (archive.Archive, error) {
+	defer d.observe(time.Now(), "diff")
+	return d.Driver.Diff(id, parent)
+}
+
+func (d *meteredDriver) Changes(id, parent string) ([]archive.Change, error) {
+	defer d.observe(time.Now(), "changes")
+	return d.Driver.Changes(id, parent)
+}
+
+func (d *meteredDriver) ApplyDiff(id, parent string, diff archive.Reader) (int64, error) {
+	defer d.observe(time.Now(), "apply_diff")
+	return d.Driver.ApplyDiff(id, parent, diff)
+}
+
+func (d *meteredDriver) DiffSize(id, parent string) (int64, error) {
+	defer d.observe(time.Now(), "diff_size")
+	return d.Driver.DiffSize(id, parent)
+}
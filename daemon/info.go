@@ -84,6 +84,7 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		NGoroutines:        runtime.NumGoroutine(),
 		SystemTime:         time.Now().Format(time.RFC3339Nano),
 		ExecutionDriver:    daemon.ExecutionDriver().Name(),
+		SecurityOptions:    daemon.securityOptions(),
 		LoggingDriver:      daemon.defaultLogConfig.Type,
 		NEventsListener:    daemon.EventsService.SubscribersCount(),
 		KernelVersion:      kernelVersion,
@@ -121,6 +122,8 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		v.CPUSet = sysInfo.Cpuset
 	}
 
+	daemon.fillDefaultsInfo(v)
+
 	if hostname, err := os.Hostname(); err == nil {
 		v.Name = hostname
 	}
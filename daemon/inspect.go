@@ -130,6 +130,11 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 		Error:      container.State.Error,
 		StartedAt:  container.State.StartedAt.Format(time.RFC3339Nano),
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
+		Usage:      convertUsageToAPIType(container.State.Usage),
+	}
+	if container.State.Restarting && !container.State.NextRestartAt.IsZero() {
+		containerState.RestartBackoff = container.State.RestartBackoff.String()
+		containerState.NextRestart = container.State.NextRestartAt.Format(time.RFC3339Nano)
 	}
 
 	contJSONBase := &types.ContainerJSONBase{
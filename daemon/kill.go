@@ -30,21 +30,22 @@ func (daemon *Daemon) ContainerKill(name string, sig uint64) error {
 	if sig == 0 || syscall.Signal(sig) == syscall.SIGKILL {
 		return daemon.Kill(container)
 	}
-	return daemon.killWithSignal(container, int(sig))
+	return daemon.killWithSignal(container, int(sig), false)
 }
 
 // killWithSignal sends the container the given signal. This wrapper for the
 // host specific kill command prepares the container before attempting
-// to send the signal. An error is returned if the container is paused
-// or not running, or if there is a problem returned from the
-// underlying kill command.
-func (daemon *Daemon) killWithSignal(container *container.Container, sig int) error {
+// to send the signal. A paused container is thawed after SIGKILL is sent, as
+// its processes only die once they are thawed. Other signals are only sent
+// to a paused container, and thawed likewise, if thaw is set, as when it is
+// stopped. An error is returned if the container is paused or not running,
+// or if there is a problem returned from the underlying kill command.
+func (daemon *Daemon) killWithSignal(container *container.Container, sig int, thaw bool) error {
 	logrus.Debugf("Sending %d to %s", sig, container.ID)
 	container.Lock()
 	defer container.Unlock()
 
-	// We could unpause the container for them rather than returning this error
-	if container.Paused {
+	if container.Paused && sig != int(syscall.SIGKILL) && !thaw {
 		return derr.ErrorCodeUnpauseContainer.WithArgs(container.ID)
 	}
 
@@ -65,6 +66,12 @@ func (daemon *Daemon) killWithSignal(container *container.Container, sig int) er
 		return err
 	}
 
+	if container.Paused {
+		if err := daemon.unpause(container); err != nil {
+			return err
+		}
+	}
+
 	daemon.LogContainerEvent(container, "kill")
 	return nil
 }
@@ -76,7 +83,7 @@ func (daemon *Daemon) Kill(container *container.Container) error {
 	}
 
 	// 1. Send SIGKILL
-	if err := daemon.killPossiblyDeadProcess(container, int(syscall.SIGKILL)); err != nil {
+	if err := daemon.killPossiblyDeadProcess(container, int(syscall.SIGKILL), false); err != nil {
 		// While normally we might "return err" here we're not going to
 		// because if we can't stop the container by this point then
 		// its probably because its already stopped. Meaning, between
@@ -106,8 +113,8 @@ func (daemon *Daemon) Kill(container *container.Container) error {
 }
 
 // killPossibleDeadProcess is a wrapper around killSig() suppressing "no such process" error.
-func (daemon *Daemon) killPossiblyDeadProcess(container *container.Container, sig int) error {
-	err := daemon.killWithSignal(container, sig)
+func (daemon *Daemon) killPossiblyDeadProcess(container *container.Container, sig int, thaw bool) error {
+	err := daemon.killWithSignal(container, sig, thaw)
 	if err == syscall.ESRCH {
 		logrus.Debugf("Cannot kill process (pid=%d) with signal %d: no such process.", container.GetPID(), sig)
 		return nil
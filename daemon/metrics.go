@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"github.com/docker/docker/pkg/metrics"
+)
+
+// containerActions records the latency of the container actions of the
+// daemon, whether they succeed or not.
+var containerActions = metrics.NewHistogram(
+	"engine_daemon_container_actions_seconds",
+	"The number of seconds it takes to process each container action",
+	metrics.DefBuckets,
+	"action",
+)
+
+func init() {
+	metrics.MustRegister(containerActions)
+}
+
+// registerMetrics registers the metrics that are computed from the state of
+// the daemon when they are collected.
+func (daemon *Daemon) registerMetrics() error {
+	return metrics.Register(metrics.NewGaugeFunc(
+		"engine_daemon_containers",
+		"The number of containers by state",
+		"state",
+		daemon.containerStateCounts,
+	))
+}
+
+// containerStateCounts returns the number of containers in each state.
+func (daemon *Daemon) containerStateCounts() map[string]float64 {
+	counts := map[string]float64{
+		"created":    0,
+		"running":    0,
+		"paused":     0,
+		"restarting": 0,
+		"exited":     0,
+		"dead":       0,
+	}
+	for _, c := range daemon.List() {
+		c.Lock()
+		counts[c.State.StateString()]++
+		c.Unlock()
+	}
+	return counts
+}
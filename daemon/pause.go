@@ -1,6 +1,9 @@
 package daemon
 
 import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	derr "github.com/docker/docker/errors"
 )
@@ -16,6 +19,9 @@ func (daemon *Daemon) ContainerPause(name string) error {
 		return derr.ErrorCodePauseError.WithArgs(name, err)
 	}
 
+	if timeout := daemon.pauseTimeout(); timeout > 0 {
+		daemon.resumeAfter(container, timeout)
+	}
 	return nil
 }
 
@@ -39,6 +45,28 @@ func (daemon *Daemon) containerPause(container *container.Container) error {
 		return err
 	}
 	container.Paused = true
+	container.PausedAt = time.Now().UTC()
 	daemon.LogContainerEvent(container, "pause")
 	return nil
 }
+
+// resumeAfter unpauses the container once it has been paused for timeout,
+// unless it was unpaused, and possibly paused again, in the meantime.
+func (daemon *Daemon) resumeAfter(container *container.Container, timeout time.Duration) {
+	container.Lock()
+	pausedAt := container.PausedAt
+	container.Unlock()
+
+	time.AfterFunc(timeout, func() {
+		container.Lock()
+		defer container.Unlock()
+
+		if !container.Running || !container.Paused || !container.PausedAt.Equal(pausedAt) {
+			return
+		}
+		logrus.Infof("Resuming container %s, it was paused for more than %s", container.ID, timeout)
+		if err := daemon.unpause(container); err != nil {
+			logrus.Errorf("Failed to resume container %s: %v", container.ID, err)
+		}
+	})
+}
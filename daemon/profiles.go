@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+)
+
+// ReloadSecurityProfiles loads the default security profiles of the
+// execution driver again from their files, for the containers started
+// afterwards.
+func (daemon *Daemon) ReloadSecurityProfiles() error {
+	p, ok := daemon.execDriver.(execdriver.SecurityProfiler)
+	if !ok {
+		return derr.ErrorCodeNoSecurityProfiles.WithArgs(daemon.execDriver.Name())
+	}
+	if err := p.ReloadSecurityProfiles(); err != nil {
+		return err
+	}
+	logrus.Info("Reloaded the default security profiles")
+	return nil
+}
+
+// securityOptions returns the default security profiles the containers are
+// confined with, in the form name=<name>,profile=<profile>.
+func (daemon *Daemon) securityOptions() []string {
+	p, ok := daemon.execDriver.(execdriver.SecurityProfiler)
+	if !ok {
+		return nil
+	}
+	var options []string
+	for _, profile := range p.SecurityProfiles() {
+		if profile.Name == "seccomp" && !daemon.seccompEnabled {
+			continue
+		}
+		options = append(options, fmt.Sprintf("name=%s,profile=%s", profile.Name, profile.Profile))
+	}
+	return options
+}
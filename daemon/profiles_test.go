@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/daemon/admission"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/authorization"
+)
+
+// profilerDriver is an execdriver.Driver whose default security profiles
+// can be reloaded.
+type profilerDriver struct {
+	execdriver.Driver
+	profiles []execdriver.SecurityProfile
+	reloaded int
+	err      error
+}
+
+func (d *profilerDriver) SecurityProfiles() []execdriver.SecurityProfile {
+	return d.profiles
+}
+
+func (d *profilerDriver) ReloadSecurityProfiles() error {
+	d.reloaded++
+	return d.err
+}
+
+// namedDriver is an execdriver.Driver which only implements Name.
+type namedDriver struct {
+	execdriver.Driver
+}
+
+func (d *namedDriver) Name() string {
+	return "test"
+}
+
+func TestSecurityOptions(t *testing.T) {
+	driver := &profilerDriver{
+		profiles: []execdriver.SecurityProfile{
+			{Name: "apparmor", Profile: "default"},
+			{Name: "seccomp", Profile: "/etc/docker/seccomp.json"},
+		},
+	}
+	daemon := &Daemon{execDriver: driver, seccompEnabled: true}
+
+	expected := []string{
+		"name=apparmor,profile=default",
+		"name=seccomp,profile=/etc/docker/seccomp.json",
+	}
+	if options := daemon.securityOptions(); !reflect.DeepEqual(options, expected) {
+		t.Fatalf("Expected security options %v, got %v", expected, options)
+	}
+
+	// the seccomp profile is not used without seccomp support
+	daemon.seccompEnabled = false
+	expected = []string{"name=apparmor,profile=default"}
+	if options := daemon.securityOptions(); !reflect.DeepEqual(options, expected) {
+		t.Fatalf("Expected security options %v, got %v", expected, options)
+	}
+
+	daemon.execDriver = &namedDriver{}
+	if options := daemon.securityOptions(); options != nil {
+		t.Fatalf("Expected no security options, got %v", options)
+	}
+}
+
+func TestReloadSecurityProfiles(t *testing.T) {
+	driver := &profilerDriver{}
+	daemon := &Daemon{execDriver: driver}
+
+	if err := daemon.ReloadSecurityProfiles(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.reloaded != 1 {
+		t.Fatalf("Expected the profiles to be reloaded once, got %d", driver.reloaded)
+	}
+
+	driver.err = errors.New("invalid profile")
+	if err := daemon.ReloadSecurityProfiles(); err != driver.err {
+		t.Fatalf("Expected error %v, got %v", driver.err, err)
+	}
+
+	daemon.execDriver = &namedDriver{}
+	if err := daemon.ReloadSecurityProfiles(); err == nil {
+		t.Fatal("Expected an error reloading the profiles of a driver without any")
+	}
+}
+
+// reloadPolicy is an authorization.PolicyPlugin which only implements Reload.
+type reloadPolicy struct {
+	authorization.PolicyPlugin
+	reloaded int
+}
+
+func (p *reloadPolicy) Reload() error {
+	p.reloaded++
+	return nil
+}
+
+func TestReloadAuthZPolicy(t *testing.T) {
+	daemon := &Daemon{execDriver: &namedDriver{}}
+	if err := daemon.ReloadAuthZPolicy(); err == nil {
+		t.Fatal("Expected an error reloading the policy of a daemon without any")
+	}
+
+	// the policy is reloaded without the profiles of the driver
+	policy := &reloadPolicy{}
+	daemon.authzPolicy = policy
+	if err := daemon.ReloadAuthZPolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if policy.reloaded != 1 {
+		t.Fatalf("Expected the policy to be reloaded once, got %d", policy.reloaded)
+	}
+	if err := daemon.ReloadSecurityProfiles(); err == nil {
+		t.Fatal("Expected an error reloading the profiles of a driver without any")
+	}
+	if policy.reloaded != 1 {
+		t.Fatalf("Expected the policy not to be reloaded with the profiles, got %d reloads", policy.reloaded)
+	}
+}
+
+func TestReloadAdmissionPolicy(t *testing.T) {
+	daemon := &Daemon{execDriver: &namedDriver{}}
+	if err := daemon.ReloadAdmissionPolicy(); err == nil {
+		t.Fatal("Expected an error reloading the policy of a daemon without any")
+	}
+
+	tmp, err := ioutil.TempDir("", "docker-daemon-admission-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, "admission.json")
+	if err := ioutil.WriteFile(path, []byte(`{"maxAge": "240h"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if daemon.admission, err = admission.NewController(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"maxAge": "1y"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.ReloadAdmissionPolicy(); err == nil {
+		t.Fatal("Expected an error reloading an invalid policy")
+	}
+	// the profiles are reloaded without the policy
+	if err := daemon.ReloadSecurityProfiles(); err == nil {
+		t.Fatal("Expected an error reloading the profiles of a driver without any")
+	}
+}
@@ -9,14 +9,15 @@ import (
 // gracefully stop the container within the given timeout, forcefully
 // stopping it if the timeout is exceeded. If given a negative
 // timeout, ContainerRestart will wait forever until a graceful
-// stop. Returns an error if the container cannot be found, or if
-// there is an underlying error at any stage of the restart.
-func (daemon *Daemon) ContainerRestart(name string, seconds int) error {
+// stop. If seconds is nil, the stop timeout of the container is used.
+// Returns an error if the container cannot be found, or if there is an
+// underlying error at any stage of the restart.
+func (daemon *Daemon) ContainerRestart(name string, seconds *int) error {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
 	}
-	if err := daemon.containerRestart(container, seconds); err != nil {
+	if err := daemon.containerRestart(container, stopTimeout(container, seconds)); err != nil {
 		return derr.ErrorCodeCantRestart.WithArgs(name, err)
 	}
 	return nil
@@ -38,7 +39,7 @@ func (daemon *Daemon) containerRestart(container *container.Container, seconds i
 		return err
 	}
 
-	if err := daemon.containerStart(container); err != nil {
+	if err := daemon.containerStart(container, ""); err != nil {
 		return err
 	}
 
@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/daemon/secrets"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+const (
+	// defaultSecretsDir is the directory of the secrets mounted in a
+	// container without a target
+	defaultSecretsDir = "/run/secrets"
+	// defaultSecretMode is the mode of the secrets mounted in a container
+	// without a mode
+	defaultSecretMode os.FileMode = 0400
+)
+
+var validSecretName = regexp.MustCompile(`^` + utils.RestrictedNameChars + `+$`)
+
+// SecretCreate adds a secret to the store of the daemon, and returns its ID.
+func (daemon *Daemon) SecretCreate(req types.SecretCreateRequest) (string, error) {
+	if !validSecretName.MatchString(req.Name) {
+		return "", derr.ErrorCodeSecretName.WithArgs(req.Name, utils.RestrictedNameChars)
+	}
+	secret, err := daemon.secrets.Create(req.Name, req.Data, req.Labels)
+	if err != nil {
+		return "", secretError(err, req.Name)
+	}
+	return secret.ID, nil
+}
+
+// SecretList returns the secrets of the daemon, without their values.
+func (daemon *Daemon) SecretList() []types.Secret {
+	list := []types.Secret{}
+	for _, secret := range daemon.secrets.List() {
+		list = append(list, apiSecret(secret))
+	}
+	return list
+}
+
+// SecretInspect returns a secret of the daemon, without its value.
+func (daemon *Daemon) SecretInspect(name string) (*types.Secret, error) {
+	secret, err := daemon.secrets.Get(name)
+	if err != nil {
+		return nil, secretError(err, name)
+	}
+	s := apiSecret(secret)
+	return &s, nil
+}
+
+// SecretRemove removes a secret from the store of the daemon. The secrets
+// of containers can not be removed.
+func (daemon *Daemon) SecretRemove(name string) error {
+	secret, err := daemon.secrets.Get(name)
+	if err != nil {
+		return secretError(err, name)
+	}
+	for _, c := range daemon.List() {
+		for _, ref := range c.HostConfig.Secrets {
+			if s, err := daemon.secrets.Get(ref.Name); err == nil && s.ID == secret.ID {
+				return derr.ErrorCodeSecretInUse.WithArgs(name, strings.TrimPrefix(c.Name, "/"))
+			}
+		}
+	}
+	if err := daemon.secrets.Remove(secret.ID); err != nil {
+		return secretError(err, name)
+	}
+	return nil
+}
+
+// secretError returns the API error for an error of the secret store
+func secretError(err error, name string) error {
+	switch err {
+	case secrets.ErrNoSuchSecret:
+		return derr.ErrorCodeNoSuchSecret.WithArgs(name)
+	case secrets.ErrNameConflict:
+		return derr.ErrorCodeSecretExists.WithArgs(name)
+	}
+	return err
+}
+
+func apiSecret(secret secrets.Secret) types.Secret {
+	return types.Secret{
+		ID:      secret.ID,
+		Name:    secret.Name,
+		Labels:  secret.Labels,
+		Created: secret.Created.Unix(),
+	}
+}
+
+// secretTarget returns the path of a secret in a container
+func secretTarget(ref containertypes.SecretReference) string {
+	if ref.Target == "" {
+		return path.Join(defaultSecretsDir, ref.Name)
+	}
+	return path.Clean(ref.Target)
+}
+
+// secretMode returns the mode of a secret in a container
+func secretMode(ref containertypes.SecretReference) os.FileMode {
+	if ref.Mode == 0 {
+		return defaultSecretMode
+	}
+	return ref.Mode
+}
+
+// verifySecrets checks that the secrets of a container exist, and that they
+// are mounted at distinct absolute paths.
+func (daemon *Daemon) verifySecrets(refs []containertypes.SecretReference) error {
+	targets := make(map[string]bool)
+	for _, ref := range refs {
+		if _, err := daemon.secrets.Get(ref.Name); err != nil {
+			return secretError(err, ref.Name)
+		}
+		target := secretTarget(ref)
+		switch {
+		case !path.IsAbs(target):
+			return derr.ErrorCodeSecretTarget.WithArgs(target, ref.Name, "the path must be absolute")
+		case target == "/":
+			return derr.ErrorCodeSecretTarget.WithArgs(target, ref.Name, "the path can not be the root directory")
+		case targets[target]:
+			return derr.ErrorCodeSecretTarget.WithArgs(target, ref.Name, "the path is used by another secret")
+		}
+		targets[target] = true
+		if ref.Mode&^os.ModePerm != 0 {
+			return fmt.Errorf("Invalid mode %o of secret %s, only permission bits are allowed", ref.Mode, ref.Name)
+		}
+		if ref.UID < 0 || ref.GID < 0 {
+			return fmt.Errorf("Invalid owner %d:%d of secret %s", ref.UID, ref.GID, ref.Name)
+		}
+	}
+	return nil
+}
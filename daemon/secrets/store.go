@@ -0,0 +1,298 @@
+// Package secrets stores the secrets of the daemon, with their values
+// encrypted on disk.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/stringid"
+)
+
+var (
+	// ErrNoSuchSecret is returned when a secret can not be found.
+	ErrNoSuchSecret = errors.New("no such secret")
+	// ErrNameConflict is returned when a secret is created with the name of
+	// an existing secret.
+	ErrNameConflict = errors.New("secret name is already taken")
+)
+
+const (
+	// keySize is the size of the AES-256 key
+	keySize = 32
+	// secretFileExt is the extension of the files of the secrets, named
+	// after their ID
+	secretFileExt = ".json"
+)
+
+// Secret is a secret of the store, without its value.
+type Secret struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Created time.Time         `json:"created"`
+}
+
+// secretFile is the content of the file of a secret
+type secretFile struct {
+	Secret
+	// Data is the value of the secret, sealed with AES-GCM, with the nonce
+	// first and the ID of the secret as additional data
+	Data []byte `json:"data"`
+}
+
+// Store holds the secrets of the daemon in a directory, one file per
+// secret, with the values encrypted by a key of the store.
+type Store struct {
+	root string
+	aead cipher.AEAD
+
+	mu      sync.RWMutex
+	secrets map[string]Secret
+}
+
+// New returns the store of the secrets in the directory root, encrypted with
+// the key of the file keyPath. The key is generated the first time. It should
+// be kept out of root, so that a copy of the secrets does not come with the
+// key to decrypt them.
+func New(root, keyPath string) (*Store, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return nil, err
+	}
+	key, err := loadKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		root:    root,
+		aead:    aead,
+		secrets: make(map[string]Secret),
+	}
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != secretFileExt {
+			continue
+		}
+		sf, err := s.readSecretFile(strings.TrimSuffix(f.Name(), secretFileExt))
+		if err != nil {
+			return nil, err
+		}
+		s.secrets[sf.ID] = sf.Secret
+	}
+	return s, nil
+}
+
+// loadKey reads the key of the store, or generates it if it does not exist
+func loadKey(path string) ([]byte, error) {
+	key, err := ioutil.ReadFile(path)
+	if err == nil {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("invalid secrets key %s: expected %d bytes, got %d", path, keySize, len(key))
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// writeFile atomically writes a file only readable by the daemon
+func writeFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (s *Store) secretPath(id string) string {
+	return filepath.Join(s.root, id+secretFileExt)
+}
+
+func (s *Store) readSecretFile(id string) (*secretFile, error) {
+	b, err := ioutil.ReadFile(s.secretPath(id))
+	if err != nil {
+		return nil, err
+	}
+	var sf secretFile
+	if err := json.Unmarshal(b, &sf); err != nil {
+		return nil, fmt.Errorf("invalid secret file %s: %v", s.secretPath(id), err)
+	}
+	if sf.ID != id {
+		return nil, fmt.Errorf("invalid secret file %s: unexpected ID %s", s.secretPath(id), sf.ID)
+	}
+	return &sf, nil
+}
+
+// Create adds a secret with a value to the store
+func (s *Store) Create(name string, data []byte, labels map[string]string) (Secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, secret := range s.secrets {
+		if secret.Name == name {
+			return Secret{}, ErrNameConflict
+		}
+	}
+
+	sf := &secretFile{
+		Secret: Secret{
+			ID:      stringid.GenerateRandomID(),
+			Name:    name,
+			Labels:  labels,
+			Created: time.Now().UTC(),
+		},
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return Secret{}, err
+	}
+	sf.Data = s.aead.Seal(nonce, nonce, data, []byte(sf.ID))
+
+	b, err := json.Marshal(sf)
+	if err != nil {
+		return Secret{}, err
+	}
+	if err := writeFile(s.secretPath(sf.ID), b); err != nil {
+		return Secret{}, err
+	}
+	s.secrets[sf.ID] = sf.Secret
+	return sf.Secret, nil
+}
+
+// Get returns a secret by name, ID or unique prefix of its ID
+func (s *Store) Get(ref string) (Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.get(ref)
+}
+
+func (s *Store) get(ref string) (Secret, error) {
+	if secret, ok := s.secrets[ref]; ok {
+		return secret, nil
+	}
+	var matches []Secret
+	for _, secret := range s.secrets {
+		if secret.Name == ref {
+			return secret, nil
+		}
+		if ref != "" && strings.HasPrefix(secret.ID, ref) {
+			matches = append(matches, secret)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Secret{}, ErrNoSuchSecret
+	case 1:
+		return matches[0], nil
+	default:
+		return Secret{}, fmt.Errorf("multiple secrets match %s", ref)
+	}
+}
+
+// List returns the secrets of the store, sorted by name
+func (s *Store) List() []Secret {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := make([]Secret, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Sort(byName(secrets))
+	return secrets
+}
+
+type byName []Secret
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Data returns the value of a secret
+func (s *Store) Data(ref string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secret, err := s.get(ref)
+	if err != nil {
+		return nil, err
+	}
+	sf, err := s.readSecretFile(secret.ID)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(sf.Data) < nonceSize {
+		return nil, fmt.Errorf("invalid value of secret %s", secret.Name)
+	}
+	data, err := s.aead.Open(nil, sf.Data[:nonceSize], sf.Data[nonceSize:], []byte(sf.ID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt the value of secret %s: %v", secret.Name, err)
+	}
+	return data, nil
+}
+
+// Remove removes a secret from the store
+func (s *Store) Remove(ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, err := s.get(ref)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(s.secretPath(secret.ID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.secrets, secret.ID)
+	return nil
+}
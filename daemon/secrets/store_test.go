@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestStore returns a store in a temporary directory, with the secrets
+// in its secrets directory and the key in its secrets.key file.
+func newTestStore(t *testing.T) (*Store, string) {
+	dir, err := ioutil.TempDir("", "secrets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(filepath.Join(dir, "secrets"), filepath.Join(dir, "secrets.key"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+func TestStoreCreate(t *testing.T) {
+	s, dir := newTestStore(t)
+	defer os.RemoveAll(dir)
+
+	secret, err := s.Create("db-password", []byte("s3cr3t"), map[string]string{"app": "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create("db-password", []byte("other"), nil); err != ErrNameConflict {
+		t.Fatalf("Expected a name conflict, got %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "secrets", secret.ID+secretFileExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("s3cr3t")) {
+		t.Fatalf("Expected the value of the secret to be encrypted on disk, got %s", b)
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, "secrets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected only the file of the secret in the root of the store, got %d files", len(files))
+	}
+	if key, err := ioutil.ReadFile(filepath.Join(dir, "secrets.key")); err != nil || len(key) != keySize {
+		t.Fatalf("Expected the key in its own file, got %d bytes, %v", len(key), err)
+	}
+
+	for _, ref := range []string{"db-password", secret.ID, secret.ID[:12]} {
+		got, err := s.Get(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != secret.ID || got.Labels["app"] != "web" {
+			t.Fatalf("Expected secret %+v for %s, got %+v", secret, ref, got)
+		}
+		data, err := s.Data(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "s3cr3t" {
+			t.Fatalf("Expected the value of the secret, got %q", data)
+		}
+	}
+	if _, err := s.Get("unknown"); err != ErrNoSuchSecret {
+		t.Fatalf("Expected no such secret, got %v", err)
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	s, dir := newTestStore(t)
+	defer os.RemoveAll(dir)
+
+	if _, err := s.Create("b", []byte("value b"), nil); err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.Create("a", []byte("value a"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = New(filepath.Join(dir, "secrets"), filepath.Join(dir, "secrets.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := s.List()
+	if len(secrets) != 2 || secrets[0].Name != "a" || secrets[1].Name != "b" {
+		t.Fatalf("Expected the secrets a and b, got %+v", secrets)
+	}
+	if data, err := s.Data("a"); err != nil || string(data) != "value a" {
+		t.Fatalf("Expected the value of secret a, got %q, %v", data, err)
+	}
+
+	if err := s.Remove(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("a"); err != ErrNoSuchSecret {
+		t.Fatalf("Expected secret a to be removed, got %v", err)
+	}
+	if err := s.Remove("a"); err != ErrNoSuchSecret {
+		t.Fatalf("Expected no such secret, got %v", err)
+	}
+}
+
+func TestStoreWrongKey(t *testing.T) {
+	s, dir := newTestStore(t)
+	defer os.RemoveAll(dir)
+
+	if _, err := s.Create("a", []byte("value a"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "secrets.key"), bytes.Repeat([]byte{1}, keySize), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(filepath.Join(dir, "secrets"), filepath.Join(dir, "secrets.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Data("a"); err == nil {
+		t.Fatal("Expected an error decrypting a secret with another key")
+	}
+}
@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/daemon/secrets"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestVerifySecrets(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-daemon-secrets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	store, err := secrets.New(filepath.Join(root, "secrets"), filepath.Join(root, "secrets.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"db-password", "tls-key"} {
+		if _, err := store.Create(name, []byte("value"), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	daemon := &Daemon{secrets: store}
+
+	valid := []containertypes.SecretReference{
+		{Name: "db-password"},
+		{Name: "tls-key", Target: "/etc/ssl/key.pem", UID: 33, GID: 33, Mode: 0440},
+	}
+	if err := daemon.verifySecrets(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, refs := range [][]containertypes.SecretReference{
+		{{Name: "unknown"}},
+		{{Name: "db-password", Target: "run/secrets/db"}},
+		{{Name: "db-password", Target: "/"}},
+		{{Name: "db-password"}, {Name: "tls-key", Target: "/run/secrets/db-password"}},
+		{{Name: "db-password", Mode: os.ModeSetuid | 0400}},
+		{{Name: "db-password", UID: -1}},
+	} {
+		if err := daemon.verifySecrets(refs); err == nil {
+			t.Fatalf("Expected an error for the secrets %+v", refs)
+		}
+	}
+}
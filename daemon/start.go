@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"runtime"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
@@ -10,8 +11,11 @@ import (
 	containertypes "github.com/docker/engine-api/types/container"
 )
 
-// ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig) error {
+// ContainerStart starts a container. If checkpoint is not empty, the
+// process of the container is restored from the checkpoint of this name.
+func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig, checkpoint string) error {
+	defer containerActions.ObserveSince(time.Now(), "start")
+
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
@@ -56,19 +60,20 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 		return err
 	}
 
-	return daemon.containerStart(container)
+	return daemon.containerStart(container, checkpoint)
 }
 
 // Start starts a container
 func (daemon *Daemon) Start(container *container.Container) error {
-	return daemon.containerStart(container)
+	return daemon.containerStart(container, "")
 }
 
 // containerStart prepares the container to run by setting up everything the
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
-// begin running.
-func (daemon *Daemon) containerStart(container *container.Container) (err error) {
+// begin running. If checkpoint is not empty, the process of the container
+// is restored from the checkpoint of this name.
+func (daemon *Daemon) containerStart(container *container.Container, checkpoint string) (err error) {
 	container.Lock()
 	defer container.Unlock()
 
@@ -80,6 +85,13 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 		return derr.ErrorCodeContainerBeingRemoved
 	}
 
+	var checkpointDir string
+	if checkpoint != "" {
+		if checkpointDir, err = existingCheckpointDir(container, checkpoint); err != nil {
+			return err
+		}
+	}
+
 	// if we encounter an error during start we need to ensure that any other
 	// setup has been cleaned up properly
 	defer func() {
@@ -130,13 +142,20 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 	}
 	mounts = append(mounts, container.IpcMounts()...)
 	mounts = append(mounts, container.TmpfsMounts()...)
+	mounts = append(mounts, daemon.readonlyTmpfsMounts(container, mounts)...)
+
+	secretMounts, err := daemon.setupSecrets(container)
+	if err != nil {
+		return err
+	}
+	mounts = append(mounts, secretMounts...)
 
 	container.Command.Mounts = mounts
 	container.Unlock()
 
 	// don't lock waitForStart because it has potential risk of blocking
 	// which will lead to dead lock, forever.
-	if err := daemon.waitForStart(container); err != nil {
+	if err := daemon.waitForStart(container, checkpointDir); err != nil {
 		container.Lock()
 		return err
 	}
@@ -145,8 +164,8 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 	return nil
 }
 
-func (daemon *Daemon) waitForStart(container *container.Container) error {
-	return container.StartMonitor(daemon, container.HostConfig.RestartPolicy)
+func (daemon *Daemon) waitForStart(container *container.Container, checkpointDir string) error {
+	return container.StartMonitor(daemon, container.HostConfig.RestartPolicy, checkpointDir)
 }
 
 // Cleanup releases any network resources allocated to the container along with any rules
@@ -155,6 +174,7 @@ func (daemon *Daemon) Cleanup(container *container.Container) {
 	daemon.releaseNetwork(container)
 
 	container.UnmountIpcMounts(detachMounted)
+	container.UnmountSecrets(detachMounted)
 
 	daemon.conditionalUnmountOnCleanup(container)
 
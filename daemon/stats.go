@@ -47,6 +47,12 @@ func (daemon *Daemon) ContainerStats(prefixOrName string, config *ContainerStats
 		ss.MemoryStats.Limit = uint64(update.MemoryLimit)
 		ss.Read = update.Read
 		ss.CPUStats.SystemUsage = update.SystemUsage
+		if update.Paused {
+			// A frozen container uses no CPU, report it as such rather than
+			// as a share of the system usage since the previous sample.
+			ss.Paused = true
+			ss.PreCPUStats = ss.CPUStats
+		}
 		preCPUStats = ss.CPUStats
 		return ss
 	}
@@ -118,14 +118,16 @@ func (s *statsCollector) run() {
 		}
 
 		for _, pair := range pairs {
+			paused := pair.container.IsPaused()
 			stats, err := s.supervisor.GetContainerStats(pair.container)
 			if err != nil {
-				if err != execdriver.ErrNotRunning {
+				if err != execdriver.ErrNotRunning && !paused {
 					logrus.Errorf("collecting stats for %s: %v", pair.container.ID, err)
 				}
 				continue
 			}
 			stats.SystemUsage = systemUsage
+			stats.Paused = paused
 
 			pair.publisher.Publish(stats)
 		}
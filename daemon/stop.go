@@ -11,10 +11,13 @@ import (
 // ContainerStop looks for the given container and terminates it,
 // waiting the given number of seconds before forcefully killing the
 // container. If a negative number of seconds is given, ContainerStop
-// will wait for a graceful termination. An error is returned if the
+// will wait for a graceful termination. If seconds is nil, the stop
+// timeout of the container is used. An error is returned if the
 // container is not found, is already stopped, or if there is a
 // problem stopping the container.
-func (daemon *Daemon) ContainerStop(name string, seconds int) error {
+func (daemon *Daemon) ContainerStop(name string, seconds *int) error {
+	defer containerActions.ObserveSince(time.Now(), "stop")
+
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
@@ -22,12 +25,21 @@ func (daemon *Daemon) ContainerStop(name string, seconds int) error {
 	if !container.IsRunning() {
 		return derr.ErrorCodeStopped
 	}
-	if err := daemon.containerStop(container, seconds); err != nil {
+	if err := daemon.containerStop(container, stopTimeout(container, seconds)); err != nil {
 		return derr.ErrorCodeCantStop.WithArgs(name, err)
 	}
 	return nil
 }
 
+// stopTimeout returns seconds if it is set, or the stop timeout of the
+// container.
+func stopTimeout(container *container.Container, seconds *int) int {
+	if seconds != nil {
+		return *seconds
+	}
+	return container.StopTimeout()
+}
+
 // containerStop halts a container by sending a stop signal, waiting for the given
 // duration in seconds, and then calling SIGKILL and waiting for the
 // process to exit. If a negative duration is given, Stop will wait
@@ -38,10 +50,20 @@ func (daemon *Daemon) containerStop(container *container.Container, seconds int)
 		return nil
 	}
 
+	// A paused container is either killed right away, or thawed by the stop
+	// signal and stopped as usual.
+	if container.IsPaused() && daemon.stopPausedByKill() {
+		if err := daemon.Kill(container); err != nil {
+			return err
+		}
+		daemon.LogContainerEvent(container, "stop")
+		return nil
+	}
+
 	// 1. Send a SIGTERM
-	if err := daemon.killPossiblyDeadProcess(container, container.StopSignal()); err != nil {
+	if err := daemon.killPossiblyDeadProcess(container, container.StopSignal(), true); err != nil {
 		logrus.Infof("Failed to send SIGTERM to the process, force killing")
-		if err := daemon.killPossiblyDeadProcess(container, 9); err != nil {
+		if err := daemon.killPossiblyDeadProcess(container, 9, true); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"path/filepath"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/daemon/trust"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+)
+
+// newTrustVerifier loads the trust policy at policyPath, and sets up the
+// signature store it refers to.
+func newTrustVerifier(policyPath, trustDir string, registryService *registry.Service) (*trust.Verifier, error) {
+	policy, err := trust.LoadPolicy(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	var store trust.Store
+	if policy.SignatureStore != "" {
+		logrus.Infof("Verifying image signatures with the signature store %s", policy.SignatureStore)
+		store = trust.NewFileStore(policy.SignatureStore)
+	} else {
+		store = trust.NewNotaryStore(filepath.Join(trustDir, "notary"), policy.Server, registryService)
+	}
+	return trust.NewVerifier(policy, store), nil
+}
+
+// trustedReference checks ref against the trust policy, and returns the
+// signed reference to use in its place, or nil if ref is acceptable as it
+// is.
+func (daemon *Daemon) trustedReference(ref reference.Named, authConfig *types.AuthConfig) (reference.Canonical, error) {
+	if daemon.trustVerifier == nil {
+		return nil, nil
+	}
+	trustedRef, err := daemon.trustVerifier.Resolve(ref, authConfig)
+	if err != nil {
+		return nil, trustPolicyError(err)
+	}
+	return trustedRef, nil
+}
+
+// verifyImageTrust checks that img, which refOrID resolved to, may be used
+// to create containers under the trust policy. Images referred to by a
+// reference must be the image signed for it. Images referred to by ID must
+// have at least one reference which is acceptable, unless the policy accepts
+// unnamed images.
+func (daemon *Daemon) verifyImageTrust(refOrID string, img *image.Image) error {
+	if daemon.trustVerifier == nil {
+		return nil
+	}
+
+	if _, err := digest.ParseDigest(refOrID); err != nil {
+		if ref, err := reference.ParseNamed(refOrID); err == nil {
+			ref = reference.WithDefaultTag(ref)
+			if id, err := daemon.referenceStore.Get(ref); err == nil && id == img.ID() {
+				return daemon.verifyImageReference(ref, img.ID())
+			}
+		}
+	}
+
+	refs := daemon.referenceStore.References(img.ID())
+	if len(refs) == 0 {
+		if daemon.trustVerifier.AcceptsUnnamed() {
+			return nil
+		}
+		return trustPolicyError(trust.PolicyError{Ref: refOrID, Reason: "the image has no trusted reference"})
+	}
+	var err error
+	for _, ref := range refs {
+		if err = daemon.verifyImageReference(ref, img.ID()); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// verifyImageReference checks that the image signed for ref is imgID.
+func (daemon *Daemon) verifyImageReference(ref reference.Named, imgID image.ID) error {
+	trustedRef, err := daemon.trustedReference(ref, nil)
+	if err != nil || trustedRef == nil {
+		return err
+	}
+	if id, err := daemon.referenceStore.Get(trustedRef); err != nil || id != imgID {
+		return trustPolicyError(trust.PolicyError{Ref: ref.String(), Reason: "the local image is not the signed image " + trustedRef.String() + ", pull it again"})
+	}
+	return nil
+}
+
+func trustPolicyError(err error) error {
+	if _, ok := err.(trust.PolicyError); ok {
+		return derr.ErrorCodeTrustPolicy.WithArgs(err)
+	}
+	return err
+}
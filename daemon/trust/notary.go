@@ -0,0 +1,296 @@
+package trust
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	registrytypes "github.com/docker/engine-api/types/registry"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/docker/notary/client"
+	"github.com/docker/notary/passphrase"
+	"github.com/docker/notary/tuf/data"
+	"github.com/docker/notary/tuf/signed"
+	canonicaljson "github.com/jfrazelle/go/canonical/json"
+)
+
+var releasesRole = path.Join(data.CanonicalTargetsRole, "releases")
+
+type notaryStore struct {
+	trustDir        string
+	server          string
+	registryService *registry.Service
+}
+
+// NewNotaryStore returns a Store which looks up targets on Notary trust
+// servers, caching trust data in trustDir. Notary verifies the signatures of
+// the trust data against the root keys pinned on first use, and the signers
+// of a target are the keys of the role holding it with a valid signature of
+// its metadata. If server is empty, the trust server of each registry is
+// used.
+func NewNotaryStore(trustDir, server string, registryService *registry.Service) Store {
+	return &notaryStore{
+		trustDir:        trustDir,
+		server:          server,
+		registryService: registryService,
+	}
+}
+
+type simpleCredentialStore struct {
+	auth types.AuthConfig
+}
+
+func (scs simpleCredentialStore) Basic(u *url.URL) (string, string) {
+	return scs.auth.Username, scs.auth.Password
+}
+
+func (s *notaryStore) trustServer(index *registrytypes.IndexInfo) string {
+	if s.server != "" {
+		return s.server
+	}
+	if index.Official {
+		return registry.NotaryServer
+	}
+	return "https://" + index.Name
+}
+
+func (s *notaryStore) repository(repo reference.Named, authConfig *types.AuthConfig) (*client.NotaryRepository, error) {
+	repoInfo, err := s.registryService.ResolveRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+	server := s.trustServer(repoInfo.Index)
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tlsconfig.ClientDefault.MinVersion,
+		CipherSuites:       tlsconfig.ClientDefault.CipherSuites,
+		InsecureSkipVerify: !repoInfo.Index.Secure,
+	}
+	if err := registry.ReadCertsDirectory(cfg, filepath.Join(registry.CertsDir, u.Host)); err != nil {
+		return nil, err
+	}
+
+	base := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: true,
+		}).Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     cfg,
+		DisableKeepAlives:   true,
+	}
+
+	modifiers := registry.DockerHeaders(http.Header{})
+	authTransport := transport.NewTransport(base, modifiers...)
+	pingClient := &http.Client{
+		Transport: authTransport,
+		Timeout:   5 * time.Second,
+	}
+	endpointStr := server + "/v2/"
+	req, err := http.NewRequest("GET", endpointStr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeManager := auth.NewSimpleChallengeManager()
+	resp, err := pingClient.Do(req)
+	if err != nil {
+		// Ignore error on ping to operate from the cached trust data
+		logrus.Debugf("Error pinging notary server %q: %s", endpointStr, err)
+	} else {
+		defer resp.Body.Close()
+		if err := challengeManager.AddResponse(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	var creds simpleCredentialStore
+	if authConfig != nil {
+		creds.auth = *authConfig
+	}
+	tokenHandler := auth.NewTokenHandler(authTransport, creds, repoInfo.FullName(), "pull")
+	basicHandler := auth.NewBasicHandler(creds)
+	modifiers = append(modifiers, transport.RequestModifier(auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler)))
+	tr := transport.NewTransport(base, modifiers...)
+
+	// Only public trust data is read, so no passphrase is ever needed.
+	return client.NewNotaryRepository(s.trustDir, repoInfo.FullName(), server, tr, passphrase.ConstantRetriever(""))
+}
+
+func (s *notaryStore) Target(repo reference.Named, tag string, authConfig *types.AuthConfig) (*Target, error) {
+	notaryRepo, err := s.repository(repo, authConfig)
+	if err != nil {
+		return nil, err
+	}
+	t, err := notaryRepo.GetTargetByName(tag, releasesRole, data.CanonicalTargetsRole)
+	if err != nil {
+		return nil, err
+	}
+	return s.convertTarget(repo, t)
+}
+
+func (s *notaryStore) Targets(repo reference.Named, authConfig *types.AuthConfig) ([]*Target, error) {
+	notaryRepo, err := s.repository(repo, authConfig)
+	if err != nil {
+		return nil, err
+	}
+	notaryTargets, err := notaryRepo.ListTargets(releasesRole, data.CanonicalTargetsRole)
+	if err != nil {
+		return nil, err
+	}
+	var targets []*Target
+	for _, nt := range notaryTargets {
+		t, err := s.convertTarget(repo, nt)
+		if err != nil {
+			logrus.Warnf("Skipping target %s of %s: %v", nt.Name, repo.FullName(), err)
+			continue
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func (s *notaryStore) convertTarget(repo reference.Named, t *client.TargetWithRole) (*Target, error) {
+	h, ok := t.Hashes["sha256"]
+	if !ok {
+		return nil, errors.New("no valid hash, expecting sha256")
+	}
+	signers, err := s.roleSigners(repo, t.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &Target{
+		Tag:     t.Name,
+		Digest:  digest.NewDigestFromHex("sha256", hex.EncodeToString(h)),
+		Size:    t.Length,
+		Signers: signers,
+	}, nil
+}
+
+// roleSigners returns the IDs of the keys of role with a valid signature of
+// the cached metadata of role.
+func (s *notaryStore) roleSigners(repo reference.Named, role string) ([]string, error) {
+	metadataDir := filepath.Join(s.trustDir, "tuf", filepath.FromSlash(repo.FullName()), "metadata")
+	keys, roleData, err := roleKeys(metadataDir, role)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := readMetadata(metadataDir, role)
+	if err != nil {
+		return nil, err
+	}
+	return verifySigners(metadata, keys, roleData)
+}
+
+func readMetadata(metadataDir, role string) (*data.Signed, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(metadataDir, filepath.FromSlash(role)+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var metadata data.Signed
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// roleKeys returns the public keys and the definition of role from the cached
+// metadata of the role delegating to it: the root role for the targets role,
+// and the parent targets role for delegations. The Notary client verified
+// that metadata against the pinned root keys when it was updated.
+func roleKeys(metadataDir, role string) (data.Keys, *data.Role, error) {
+	if !data.IsDelegation(role) {
+		metadata, err := readMetadata(metadataDir, data.CanonicalRootRole)
+		if err != nil {
+			return nil, nil, err
+		}
+		root, err := data.RootFromSigned(metadata)
+		if err != nil {
+			return nil, nil, err
+		}
+		rootRole, ok := root.Signed.Roles[role]
+		if !ok {
+			return nil, nil, fmt.Errorf("role %s is not defined by the root role", role)
+		}
+		return root.Signed.Keys, &data.Role{RootRole: *rootRole, Name: role}, nil
+	}
+
+	parent := path.Dir(role)
+	metadata, err := readMetadata(metadataDir, parent)
+	if err != nil {
+		return nil, nil, err
+	}
+	targets, err := data.TargetsFromSigned(metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, delegation := range targets.Signed.Delegations.Roles {
+		if delegation.Name == role {
+			return targets.Signed.Delegations.Keys, delegation, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("role %s is not delegated by %s", role, parent)
+}
+
+// verifySigners checks the signatures of metadata against the keys of role,
+// and returns the IDs of the keys with a valid signature. Signature entries
+// naming keys of the role without a valid signature are ignored, and an
+// error is returned if fewer keys than the threshold of the role signed.
+func verifySigners(metadata *data.Signed, keys data.Keys, role *data.Role) ([]string, error) {
+	var decoded map[string]interface{}
+	if err := canonicaljson.Unmarshal(metadata.Signed, &decoded); err != nil {
+		return nil, err
+	}
+	msg, err := canonicaljson.MarshalCanonical(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var signers []string
+	valid := make(map[string]bool)
+	for _, sig := range metadata.Signatures {
+		if valid[sig.KeyID] || !role.ValidKey(sig.KeyID) {
+			continue
+		}
+		key, ok := keys[sig.KeyID]
+		if !ok {
+			continue
+		}
+		verifier, ok := signed.Verifiers[sig.Method]
+		if !ok {
+			continue
+		}
+		if err := verifier.Verify(key, sig.Signature, msg); err != nil {
+			logrus.Warnf("Ignoring invalid signature of %s by key %s: %v", role.Name, sig.KeyID, err)
+			continue
+		}
+		valid[sig.KeyID] = true
+		signers = append(signers, sig.KeyID)
+	}
+	if len(signers) < role.Threshold || len(signers) == 0 {
+		return nil, fmt.Errorf("%s has %d valid signatures, fewer than its threshold of %d", role.Name, len(signers), role.Threshold)
+	}
+	return signers, nil
+}
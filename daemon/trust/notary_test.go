@@ -0,0 +1,145 @@
+package trust
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/reference"
+	"github.com/docker/notary/tuf/data"
+	"github.com/docker/notary/tuf/signed"
+	canonicaljson "github.com/jfrazelle/go/canonical/json"
+)
+
+func writeMetadata(t *testing.T, metadataDir, role string, metadata *data.Signed) {
+	path := filepath.Join(metadataDir, filepath.FromSlash(role)+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := canonicaljson.MarshalCanonical(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// toSigned serializes the signed part of metadata. The keys are encoded with
+// encoding/json, as the canonical encoder skips the fields they embed.
+func toSigned(t *testing.T, signedPart interface{}) *data.Signed {
+	raw, err := json.Marshal(signedPart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded interface{}
+	if err := canonicaljson.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicaljson.MarshalCanonical(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &data.Signed{Signed: canonicaljson.RawMessage(canonical)}
+}
+
+func createKey(t *testing.T, cs *signed.Ed25519, role string) data.PublicKey {
+	key, err := cs.Create(role, data.ED25519Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestNotaryRoleSigners(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "trust-notary-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	repo, err := reference.ParseNamed("busybox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataDir := filepath.Join(tmpDir, "tuf", filepath.FromSlash(repo.FullName()), "metadata")
+
+	cs := signed.NewEd25519()
+	rootKey := createKey(t, cs, data.CanonicalRootRole)
+	targetsKey := createKey(t, cs, data.CanonicalTargetsRole)
+	releasesKey := createKey(t, cs, releasesRole)
+	trustedKey := createKey(t, cs, releasesRole)
+
+	root, err := data.NewRoot(data.Keys{rootKey.ID(): rootKey, targetsKey.ID(): targetsKey}, map[string]*data.RootRole{
+		data.CanonicalRootRole:    {KeyIDs: []string{rootKey.ID()}, Threshold: 1},
+		data.CanonicalTargetsRole: {KeyIDs: []string{targetsKey.ID()}, Threshold: 1},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootSigned := toSigned(t, root.Signed)
+	if err := signed.Sign(cs, rootSigned, rootKey); err != nil {
+		t.Fatal(err)
+	}
+	writeMetadata(t, metadataDir, data.CanonicalRootRole, rootSigned)
+
+	// The releases role can be signed by either key.
+	targets := data.NewTargets()
+	targets.Signed.Delegations.Keys = data.Keys{releasesKey.ID(): releasesKey, trustedKey.ID(): trustedKey}
+	targets.Signed.Delegations.Roles = []*data.Role{{
+		RootRole: data.RootRole{KeyIDs: []string{releasesKey.ID(), trustedKey.ID()}, Threshold: 1},
+		Name:     releasesRole,
+		Paths:    []string{""},
+	}}
+	targetsSigned := toSigned(t, targets.Signed)
+	if err := signed.Sign(cs, targetsSigned, targetsKey); err != nil {
+		t.Fatal(err)
+	}
+	writeMetadata(t, metadataDir, data.CanonicalTargetsRole, targetsSigned)
+
+	s := &notaryStore{trustDir: tmpDir}
+	signers, err := s.roleSigners(repo, data.CanonicalTargetsRole)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 1 || signers[0] != targetsKey.ID() {
+		t.Fatalf("Expected the targets key to sign the targets role, got %v", signers)
+	}
+
+	// Releases signed by the releases key, with a forged signature entry
+	// naming the trusted key.
+	releasesSigned, err := data.NewTargets().ToSigned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signed.Sign(cs, releasesSigned, releasesKey); err != nil {
+		t.Fatal(err)
+	}
+	forged := data.Signature{KeyID: trustedKey.ID(), Method: data.EDDSASignature, Signature: releasesSigned.Signatures[0].Signature}
+	releasesSigned.Signatures = append(releasesSigned.Signatures, forged)
+	writeMetadata(t, metadataDir, releasesRole, releasesSigned)
+
+	signers, err = s.roleSigners(repo, releasesRole)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 1 || signers[0] != releasesKey.ID() {
+		t.Fatalf("Expected only the releases key to sign the releases role, got %v", signers)
+	}
+
+	// Releases with only the forged signature entry.
+	releasesSigned.Signatures = []data.Signature{forged}
+	writeMetadata(t, metadataDir, releasesRole, releasesSigned)
+	if signers, err := s.roleSigners(repo, releasesRole); err == nil {
+		t.Fatalf("Expected an error for releases without valid signatures, got signers %v", signers)
+	}
+
+	// Releases without any signature.
+	releasesSigned.Signatures = nil
+	writeMetadata(t, metadataDir, releasesRole, releasesSigned)
+	if signers, err := s.roleSigners(repo, releasesRole); err == nil {
+		t.Fatalf("Expected an error for unsigned releases, got signers %v", signers)
+	}
+}
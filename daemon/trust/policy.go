@@ -0,0 +1,129 @@
+// Package trust enforces image signing policies in the daemon. A policy maps
+// repositories to rules which accept them, reject them, or require their tags
+// to be signed by given keys, as recorded by a Store.
+package trust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/reference"
+)
+
+// Rule types.
+const (
+	// RuleAccept accepts any image of a repository, signed or not.
+	RuleAccept = "accept"
+	// RuleReject rejects all images of a repository.
+	RuleReject = "reject"
+	// RuleSigned only accepts images whose tag is signed by one of the
+	// rule's keys.
+	RuleSigned = "signed"
+)
+
+// Rule is the trust requirement for a set of repositories.
+type Rule struct {
+	// Type is RuleAccept, RuleReject or RuleSigned.
+	Type string `json:"type"`
+	// Keys holds the IDs of the keys accepted for signatures, for
+	// RuleSigned rules.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// Policy maps repositories to the rule that applies to them.
+type Policy struct {
+	// Default is the rule for repositories which match none of the scopes
+	// in Repositories.
+	Default Rule `json:"default"`
+	// Repositories maps scopes to rules. A scope is a registry hostname,
+	// like "docker.io", or a full repository name or prefix, like
+	// "docker.io/library" or "docker.io/library/ubuntu". The most specific
+	// scope matching a repository applies.
+	Repositories map[string]Rule `json:"repositories,omitempty"`
+	// Server is the trust server used to look up signatures. It defaults
+	// to the Notary server of each registry.
+	Server string `json:"server,omitempty"`
+	// SignatureStore is a directory of signatures used instead of a trust
+	// server, see NewFileStore.
+	SignatureStore string `json:"signatureStore,omitempty"`
+}
+
+// LoadPolicy reads and validates a JSON policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p Policy
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid trust policy %s: %v", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid trust policy %s: %v", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks the rules of the policy.
+func (p *Policy) Validate() error {
+	if p.Default.Type == "" {
+		p.Default.Type = RuleAccept
+	}
+	if err := p.Default.validate(); err != nil {
+		return fmt.Errorf("default rule: %v", err)
+	}
+	for scope, rule := range p.Repositories {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule for %s: %v", scope, err)
+		}
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	switch r.Type {
+	case RuleAccept, RuleReject:
+		if len(r.Keys) > 0 {
+			return fmt.Errorf("keys are only valid for %s rules", RuleSigned)
+		}
+	case RuleSigned:
+		if len(r.Keys) == 0 {
+			return fmt.Errorf("%s rule without keys", RuleSigned)
+		}
+	default:
+		return fmt.Errorf("unknown rule type %q", r.Type)
+	}
+	return nil
+}
+
+// RuleFor returns the rule which applies to the repository of ref, and the
+// scope it was configured for, which is empty for the default rule.
+func (p *Policy) RuleFor(ref reference.Named) (Rule, string) {
+	name := ref.FullName()
+	for {
+		if rule, ok := p.Repositories[name]; ok {
+			return rule, name
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return p.Default, ""
+		}
+		name = name[:i]
+	}
+}
+
+// signedBy returns whether one of signers is among the keys of the rule.
+func (r Rule) signedBy(signers []string) bool {
+	for _, key := range r.Keys {
+		for _, signer := range signers {
+			if key == signer {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package trust
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/reference"
+)
+
+func TestPolicyRuleFor(t *testing.T) {
+	p := &Policy{
+		Default: Rule{Type: RuleReject},
+		Repositories: map[string]Rule{
+			"docker.io":                Rule{Type: RuleAccept},
+			"docker.io/library":        Rule{Type: RuleSigned, Keys: []string{"library"}},
+			"docker.io/library/ubuntu": Rule{Type: RuleSigned, Keys: []string{"ubuntu"}},
+		},
+	}
+	cases := []struct {
+		ref           string
+		expectedScope string
+		expectedType  string
+	}{
+		{"ubuntu:14.04", "docker.io/library/ubuntu", RuleSigned},
+		{"busybox", "docker.io/library", RuleSigned},
+		{"someone/app@sha256:0123456789012345678901234567890123456789012345678901234567890123", "docker.io", RuleAccept},
+		{"registry.example.com/app", "", RuleReject},
+		{"docker.io/library/ubuntu-extra", "docker.io/library", RuleSigned},
+	}
+	for _, c := range cases {
+		ref, err := reference.ParseNamed(c.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rule, scope := p.RuleFor(ref)
+		if scope != c.expectedScope || rule.Type != c.expectedType {
+			t.Errorf("%s: expected rule %s for %q, got %s for %q", c.ref, c.expectedType, c.expectedScope, rule.Type, scope)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "trust-policy-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	policyPath := filepath.Join(tmpDir, "policy.json")
+	if err := ioutil.WriteFile(policyPath, []byte(`{"repositories": {"docker.io/library": {"type": "signed", "keys": ["abc"]}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPolicy(policyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Default.Type != RuleAccept {
+		t.Fatalf("expected the default rule to accept, got %s", p.Default.Type)
+	}
+
+	invalid := []string{
+		`{"default": {"type": "maybe"}}`,
+		`{"repositories": {"docker.io": {"type": "signed"}}}`,
+		`{"repositories": {"docker.io": {"type": "accept", "keys": ["abc"]}}}`,
+	}
+	for _, policy := range invalid {
+		if err := ioutil.WriteFile(policyPath, []byte(policy), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(policyPath); err == nil {
+			t.Errorf("expected an error loading %s", policy)
+		}
+	}
+}
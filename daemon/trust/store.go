@@ -0,0 +1,99 @@
+package trust
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+// Target is a signed tag of a repository.
+type Target struct {
+	// Tag is the name of the tag.
+	Tag string `json:"-"`
+	// Digest is the digest of the image manifest the tag refers to.
+	Digest digest.Digest `json:"digest"`
+	// Size is the size of the image manifest.
+	Size int64 `json:"size,omitempty"`
+	// Signers holds the IDs of the keys which signed the target.
+	Signers []string `json:"signers"`
+}
+
+// Store looks up the signed targets of repositories.
+type Store interface {
+	// Target returns the signed target for a tag of a repository.
+	Target(repo reference.Named, tag string, authConfig *types.AuthConfig) (*Target, error)
+	// Targets returns all the signed targets of a repository.
+	Targets(repo reference.Named, authConfig *types.AuthConfig) ([]*Target, error)
+}
+
+const targetFileExt = ".json"
+
+type fileStore struct {
+	root string
+}
+
+// NewFileStore returns a Store which reads targets from the directory root,
+// with one JSON file per tag at <root>/<repository full name>/<tag>.json.
+// The signers recorded in the files are trusted as they are, so a file store
+// stands in for a trust server in tests and air-gapped setups where the
+// directory is provisioned by trusted tooling.
+func NewFileStore(root string) Store {
+	return &fileStore{root: root}
+}
+
+func (s *fileStore) repoDir(repo reference.Named) string {
+	return filepath.Join(s.root, filepath.FromSlash(repo.FullName()))
+}
+
+func (s *fileStore) Target(repo reference.Named, tag string, authConfig *types.AuthConfig) (*Target, error) {
+	return s.readTarget(filepath.Join(s.repoDir(repo), tag+targetFileExt), tag)
+}
+
+func (s *fileStore) Targets(repo reference.Named, authConfig *types.AuthConfig) ([]*Target, error) {
+	files, err := ioutil.ReadDir(s.repoDir(repo))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var targets []*Target
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), targetFileExt) {
+			continue
+		}
+		tag := strings.TrimSuffix(f.Name(), targetFileExt)
+		t, err := s.readTarget(filepath.Join(s.repoDir(repo), f.Name()), tag)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func (s *fileStore) readTarget(path, tag string) (*Target, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no trust data for %s", tag)
+		}
+		return nil, err
+	}
+	t := &Target{Tag: tag}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("invalid trust data for %s: %v", tag, err)
+	}
+	if err := t.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid trust data for %s: %v", tag, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,91 @@
+package trust
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+// PolicyError is returned when an image is not acceptable under the trust
+// policy.
+type PolicyError struct {
+	// Ref is the image reference which was checked.
+	Ref string
+	// Reason explains why the image is not acceptable.
+	Reason string
+}
+
+func (e PolicyError) Error() string {
+	return fmt.Sprintf("trust policy violation for %s: %s", e.Ref, e.Reason)
+}
+
+// Verifier checks image references against a policy.
+type Verifier struct {
+	policy *Policy
+	store  Store
+}
+
+// NewVerifier returns a Verifier for policy, looking up signatures in store.
+func NewVerifier(policy *Policy, store Store) *Verifier {
+	return &Verifier{
+		policy: policy,
+		store:  store,
+	}
+}
+
+// AcceptsUnnamed returns whether images which have no reference in any
+// repository, such as locally built images used by ID, are acceptable.
+func (v *Verifier) AcceptsUnnamed() bool {
+	return v.policy.Default.Type == RuleAccept
+}
+
+// Resolve checks ref against the policy. If the rule for its repository
+// requires signatures, Resolve returns the reference of the signed image,
+// which is what must be used in place of ref. Tags are resolved to the digest
+// they are signed for, and digests must be the signed digest of one of the
+// tags. Resolve returns a nil reference if the policy accepts ref as it is.
+func (v *Verifier) Resolve(ref reference.Named, authConfig *types.AuthConfig) (reference.Canonical, error) {
+	rule, scope := v.policy.RuleFor(ref)
+	switch rule.Type {
+	case RuleAccept:
+		return nil, nil
+	case RuleReject:
+		return nil, PolicyError{Ref: ref.String(), Reason: fmt.Sprintf("images of %s are rejected", scopeName(scope))}
+	}
+
+	switch r := ref.(type) {
+	case reference.Canonical:
+		targets, err := v.store.Targets(r, authConfig)
+		if err != nil {
+			return nil, PolicyError{Ref: ref.String(), Reason: fmt.Sprintf("cannot look up signatures: %v", err)}
+		}
+		for _, t := range targets {
+			if t.Digest == r.Digest() && rule.signedBy(t.Signers) {
+				return r, nil
+			}
+		}
+		return nil, PolicyError{Ref: ref.String(), Reason: "digest is not signed by a trusted key"}
+	case reference.NamedTagged:
+		t, err := v.store.Target(r, r.Tag(), authConfig)
+		if err != nil {
+			return nil, PolicyError{Ref: ref.String(), Reason: fmt.Sprintf("no signature found: %v", err)}
+		}
+		if !rule.signedBy(t.Signers) {
+			return nil, PolicyError{Ref: ref.String(), Reason: "tag is not signed by a trusted key"}
+		}
+		name, err := reference.WithName(r.Name())
+		if err != nil {
+			return nil, err
+		}
+		return reference.WithDigest(name, t.Digest)
+	}
+	return nil, PolicyError{Ref: ref.String(), Reason: "a tag or digest is required for signed images"}
+}
+
+func scopeName(scope string) string {
+	if scope == "" {
+		return "repositories without a rule"
+	}
+	return scope
+}
@@ -0,0 +1,101 @@
+package trust
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/reference"
+)
+
+const (
+	signedDigest   = digest.Digest("sha256:1111111111111111111111111111111111111111111111111111111111111111")
+	unsignedDigest = digest.Digest("sha256:2222222222222222222222222222222222222222222222222222222222222222")
+)
+
+func writeTarget(t *testing.T, root, repo, tag string, target Target) {
+	dir := filepath.Join(root, filepath.FromSlash(repo))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, tag+targetFileExt), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifierResolve(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "trust-store-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeTarget(t, tmpDir, "docker.io/library/busybox", "latest", Target{Digest: signedDigest, Signers: []string{"trusted"}})
+	writeTarget(t, tmpDir, "docker.io/library/busybox", "other", Target{Digest: unsignedDigest, Signers: []string{"untrusted"}})
+
+	v := NewVerifier(&Policy{
+		Default: Rule{Type: RuleAccept},
+		Repositories: map[string]Rule{
+			"docker.io/library": Rule{Type: RuleSigned, Keys: []string{"trusted"}},
+			"evil.example.com":  Rule{Type: RuleReject},
+		},
+	}, NewFileStore(tmpDir))
+
+	cases := []struct {
+		ref      string
+		expected string
+		err      bool
+	}{
+		{"busybox", "docker.io/library/busybox@" + string(signedDigest), false},
+		{"busybox:latest", "docker.io/library/busybox@" + string(signedDigest), false},
+		{"busybox@" + string(signedDigest), "docker.io/library/busybox@" + string(signedDigest), false},
+		{"busybox:other", "", true},
+		{"busybox:missing", "", true},
+		{"busybox@" + string(unsignedDigest), "", true},
+		{"someone/app:latest", "", false},
+		{"evil.example.com/app:latest", "", true},
+	}
+	for _, c := range cases {
+		ref, err := reference.ParseNamed(c.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trustedRef, err := v.Resolve(reference.WithDefaultTag(ref), nil)
+		if c.err {
+			if _, ok := err.(PolicyError); !ok {
+				t.Errorf("%s: expected a policy error, got %v", c.ref, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.ref, err)
+			continue
+		}
+		var actual string
+		if trustedRef != nil {
+			actual = trustedRef.FullName() + "@" + string(trustedRef.Digest())
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.ref, c.expected, actual)
+		}
+	}
+
+	if _, err := v.Resolve(mustParse(t, "busybox"), nil); err == nil {
+		t.Error("expected an error resolving a repository without a tag")
+	}
+}
+
+func mustParse(t *testing.T, s string) reference.Named {
+	ref, err := reference.ParseNamed(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
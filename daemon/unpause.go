@@ -34,6 +34,12 @@ func (daemon *Daemon) containerUnpause(container *container.Container) error {
 		return derr.ErrorCodeNotPaused.WithArgs(container.ID)
 	}
 
+	return daemon.unpause(container)
+}
+
+// unpause resumes the execution of the paused container. The container must
+// be locked.
+func (daemon *Daemon) unpause(container *container.Container) error {
 	if err := daemon.execDriver.Unpause(container.Command); err != nil {
 		return err
 	}
@@ -2,11 +2,12 @@ package daemon
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/docker/engine-api/types/container"
 )
 
-// ContainerUpdate updates resources of the container
+// ContainerUpdate updates the resources and the restart policy of the container
 func (daemon *Daemon) ContainerUpdate(name string, hostConfig *container.HostConfig) ([]string, error) {
 	var warnings []string
 
@@ -40,7 +41,14 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 		return fmt.Errorf("Can not update kernel memory to a running container, please stop it first.")
 	}
 
-	if err := container.UpdateContainer(hostConfig); err != nil {
+	if container.IsRunning() {
+		if err := verifyRunningUpdate(container, hostConfig); err != nil {
+			return err
+		}
+	}
+
+	changed, err := container.UpdateContainer(hostConfig)
+	if err != nil {
 		return err
 	}
 
@@ -52,9 +60,21 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 		if err := daemon.execDriver.Update(container.Command); err != nil {
 			return err
 		}
+		// The allowed devices are only rewritten when the rules change, as
+		// access to all the devices is briefly revoked while they are.
+		for _, c := range changed {
+			if c == "DeviceCgroupRules" {
+				if err := daemon.updateAllowedDevices(container); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
-	daemon.LogContainerEvent(container, "update")
+	attributes := map[string]string{
+		"changed": strings.Join(changed, ","),
+	}
+	daemon.LogContainerEventWithAttributes(container, "update", attributes)
 
 	return nil
 }
@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/system"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// verifyRunningUpdate checks that the changes in hostConfig can be applied
+// to the running container c. Ulimits can only be raised, as processes may
+// already use more than a lower limit allows.
+func verifyRunningUpdate(c *container.Container, hostConfig *containertypes.HostConfig) error {
+	if err := verifyNetworkRateLimits(c.HostConfig, hostConfig.NetworkRateLimits); err != nil {
+		return err
+	}
+
+	for _, ul := range hostConfig.Ulimits {
+		rl, err := ul.GetRlimit()
+		if err != nil {
+			return err
+		}
+		var current syscall.Rlimit
+		if err := system.Prlimit(c.Pid, rl.Type, nil, &current); err != nil {
+			return fmt.Errorf("Cannot read the %s ulimit of container %s: %v", ul.Name, c.ID, err)
+		}
+		if rl.Soft < current.Cur || rl.Hard < current.Max {
+			return fmt.Errorf("The %s ulimit of a running container can only be raised, stop the container to lower it.", ul.Name)
+		}
+	}
+	return nil
+}
+
+// updateAllowedDevices replaces the devices the running container c is
+// allowed to access with the ones of its host config.
+func (daemon *Daemon) updateAllowedDevices(c *container.Container) error {
+	c.Lock()
+	defer c.Unlock()
+
+	allowed, autoCreated, err := getDevices(&c.HostConfig.Resources)
+	if err != nil {
+		return err
+	}
+	c.Command.AllowedDevices, c.Command.AutoCreatedDevices = allowed, autoCreated
+	return daemon.execDriver.UpdateDevices(c.Command)
+}
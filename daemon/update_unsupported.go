@@ -0,0 +1,18 @@
+// +build !linux
+
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// verifyRunningUpdate has nothing to check on this platform.
+func verifyRunningUpdate(c *container.Container, hostConfig *containertypes.HostConfig) error {
+	return nil
+}
+
+// updateAllowedDevices has nothing to update on this platform.
+func (daemon *Daemon) updateAllowedDevices(c *container.Container) error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/engine-api/types"
+)
+
+// usageInterval is the interval at which the resource usage of the running
+// containers is added to their usage history.
+const usageInterval = time.Minute
+
+// collectUsage adds a sample of the resource usage of each running container
+// to its usage history every interval, until stop is closed.
+func (daemon *Daemon) collectUsage(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		for _, c := range daemon.List() {
+			if !c.IsRunning() {
+				continue
+			}
+			stats, err := daemon.GetContainerStats(c)
+			if err != nil {
+				if err != execdriver.ErrNotRunning {
+					logrus.Debugf("collecting resource usage for %s: %v", c.ID, err)
+				}
+				continue
+			}
+			c.Lock()
+			c.AddUsageSample(usageFromStats(stats))
+			c.Unlock()
+		}
+	}
+}
+
+// ContainerStatsHistory returns the resource usage of the current or last run
+// of the container, and its per-minute history.
+func (daemon *Daemon) ContainerStatsHistory(name string) (*types.ContainerStatsHistory, error) {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	history := &types.ContainerStatsHistory{
+		Usage:   convertUsageToAPIType(container.Usage),
+		History: []types.ResourceUsage{},
+	}
+	for i := range container.UsageHistory {
+		history.History = append(history.History, *convertUsageToAPIType(&container.UsageHistory[i]))
+	}
+	return history, nil
+}
+
+// convertUsageToAPIType converts the resource usage of a container to the
+// api type, or returns nil if there is no usage.
+func convertUsageToAPIType(u *container.ResourceUsage) *types.ResourceUsage {
+	if u == nil {
+		return nil
+	}
+	return &types.ResourceUsage{
+		Read:            u.Read,
+		CPUTime:         u.CPUTime,
+		MemoryUsage:     u.MemoryUsage,
+		MemoryMaxUsage:  u.MemoryMaxUsage,
+		OOMCount:        u.OOMCount,
+		BlkioReadBytes:  u.BlkioReadBytes,
+		BlkioWriteBytes: u.BlkioWriteBytes,
+		NetworkRxBytes:  u.NetworkRxBytes,
+		NetworkTxBytes:  u.NetworkTxBytes,
+	}
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// usageFromStats returns the resource usage of a container from its stats.
+func usageFromStats(stats *execdriver.ResourceStats) container.ResourceUsage {
+	u := container.ResourceUsage{Read: stats.Read.UTC()}
+	for _, iface := range stats.Interfaces {
+		u.NetworkRxBytes += iface.RxBytes
+		u.NetworkTxBytes += iface.TxBytes
+	}
+
+	cs := stats.CgroupStats
+	if cs == nil {
+		return u
+	}
+	u.CPUTime = cs.CpuStats.CpuUsage.TotalUsage
+	u.MemoryUsage = cs.MemoryStats.Usage.Usage
+	u.MemoryMaxUsage = cs.MemoryStats.Usage.MaxUsage
+	for _, e := range cs.BlkioStats.IoServiceBytesRecursive {
+		switch e.Op {
+		case "Read":
+			u.BlkioReadBytes += e.Value
+		case "Write":
+			u.BlkioWriteBytes += e.Value
+		}
+	}
+	return u
+}
+
+// recordExitUsage adds the resource usage the container c had when its
+// process exited to its usage history. The network namespace of the container
+// outlives its process, so the network usage is read separately.
+func (daemon *Daemon) recordExitUsage(c *container.Container, exitStatus execdriver.ExitStatus) {
+	stats := exitStatus.Stats
+	if stats == nil {
+		return
+	}
+	nwStats, err := daemon.getNetworkStats(c)
+	if err != nil {
+		logrus.Debugf("collecting network usage for exited container %s: %v", c.ID, err)
+	}
+	stats.Interfaces = nwStats
+
+	c.Lock()
+	c.AddUsageSample(usageFromStats(stats))
+	c.Unlock()
+}
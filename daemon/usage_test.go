@@ -0,0 +1,25 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+)
+
+func TestCollectUsageStop(t *testing.T) {
+	daemon := &Daemon{containers: &contStore{s: make(map[string]*container.Container)}}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		daemon.collectUsage(time.Millisecond, stop)
+		close(done)
+	}()
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the usage collection to stop")
+	}
+}
@@ -0,0 +1,24 @@
+// +build !linux
+
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// usageFromStats returns the network usage of a container from its stats,
+// the only usage there are stats for on this platform.
+func usageFromStats(stats *execdriver.ResourceStats) container.ResourceUsage {
+	u := container.ResourceUsage{Read: stats.Read.UTC()}
+	for _, iface := range stats.Interfaces {
+		u.NetworkRxBytes += iface.RxBytes
+		u.NetworkTxBytes += iface.TxBytes
+	}
+	return u
+}
+
+// recordExitUsage does nothing, the usage of exited containers is not
+// available on this platform.
+func (daemon *Daemon) recordExitUsage(c *container.Container, exitStatus execdriver.ExitStatus) {
+}
@@ -0,0 +1,129 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/mount"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// setIDMaps allocates a range of subordinate IDs to a container with a
+// private user namespace.
+func (daemon *Daemon) setIDMaps(container *container.Container, hostConfig *containertypes.HostConfig) error {
+	if !hostConfig.UsernsMode.IsPrivate() {
+		return nil
+	}
+	if daemon.idRanges == nil {
+		return fmt.Errorf("A private user namespace requires the daemon to be started with --userns-remap.")
+	}
+	uidMaps, gidMaps, err := daemon.idRanges.Allocate()
+	if err != nil {
+		return err
+	}
+	if err := daemon.allowRootfsTraversal(); err != nil {
+		daemon.idRanges.Release(uidMaps, gidMaps)
+		return err
+	}
+	container.UIDMaps = uidMaps
+	container.GIDMaps = gidMaps
+	return nil
+}
+
+// reserveIDMaps marks the range of subordinate IDs of a container loaded
+// from disk as allocated.
+func (daemon *Daemon) reserveIDMaps(container *container.Container) error {
+	if container.UIDMaps == nil {
+		return nil
+	}
+	if daemon.idRanges == nil {
+		return fmt.Errorf("Container %s has a private user namespace, which requires the daemon to be started with --userns-remap", container.ID)
+	}
+	if err := daemon.idRanges.Reserve(container.UIDMaps, container.GIDMaps); err != nil {
+		return err
+	}
+	return daemon.allowRootfsTraversal()
+}
+
+// allowRootfsTraversal makes the daemon root and its containers directory
+// traversable by all users (mode 0701), so that the root of a container with
+// a private user namespace, which is not the remapped root of the daemon, can
+// reach the bind mount of its root filesystem. They are only loosened once a
+// container gets a private range.
+func (daemon *Daemon) allowRootfsTraversal() error {
+	for _, dir := range []string{daemon.root, daemon.repository} {
+		if err := os.Chmod(dir, 0701); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseIDMaps frees the range of subordinate IDs of a container which is
+// removed.
+func (daemon *Daemon) releaseIDMaps(container *container.Container) {
+	if container.UIDMaps == nil || daemon.idRanges == nil {
+		return
+	}
+	daemon.idRanges.Release(container.UIDMaps, container.GIDMaps)
+	container.UIDMaps = nil
+	container.GIDMaps = nil
+}
+
+// idMaps returns the ID mappings of the user namespace a container runs in
+// with hostConfig, which are empty if it runs in the host user namespace.
+func (daemon *Daemon) idMaps(container *container.Container, hostConfig *containertypes.HostConfig) ([]idtools.IDMap, []idtools.IDMap) {
+	if hostConfig.UsernsMode.IsHost() {
+		return nil, nil
+	}
+	if container.UIDMaps != nil {
+		return container.UIDMaps, container.GIDMaps
+	}
+	return daemon.uidMaps, daemon.gidMaps
+}
+
+// containerIDMaps returns the ID mappings of the user namespace a container
+// runs in.
+func (daemon *Daemon) containerIDMaps(container *container.Container) ([]idtools.IDMap, []idtools.IDMap) {
+	return daemon.idMaps(container, container.HostConfig)
+}
+
+// containerRootUIDGID returns the host uid and gid of the root of a
+// container.
+func (daemon *Daemon) containerRootUIDGID(container *container.Container) (int, int) {
+	uidMaps, gidMaps := daemon.containerIDMaps(container)
+	uid, gid, _ := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	return uid, gid
+}
+
+// mountRootfs bind mounts the root filesystem of a container with a private
+// user namespace in the container directory, which its root can traverse
+// unlike the directories of the graph driver.
+func (daemon *Daemon) mountRootfs(container *container.Container) error {
+	rootfs, err := container.RootfsPath()
+	if err != nil || rootfs == container.BaseFS {
+		return err
+	}
+	rootUID, rootGID := daemon.containerRootUIDGID(container)
+	if err := idtools.MkdirAllAs(rootfs, 0755, rootUID, rootGID); err != nil {
+		return err
+	}
+	if mounted, err := mount.Mounted(rootfs); err != nil || mounted {
+		return err
+	}
+	return mount.Mount(container.BaseFS, rootfs, "bind", "bind")
+}
+
+// unmountRootfs unmounts the bind mount of the root filesystem of a
+// container with a private user namespace.
+func (daemon *Daemon) unmountRootfs(container *container.Container) error {
+	rootfs, err := container.RootfsPath()
+	if err != nil || rootfs == container.BaseFS {
+		return err
+	}
+	return mount.Unmount(rootfs)
+}
@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/idtools"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// setIDMaps does nothing, user namespaces are not supported on Windows.
+func (daemon *Daemon) setIDMaps(container *container.Container, hostConfig *containertypes.HostConfig) error {
+	return nil
+}
+
+// reserveIDMaps does nothing, user namespaces are not supported on Windows.
+func (daemon *Daemon) reserveIDMaps(container *container.Container) error {
+	return nil
+}
+
+// releaseIDMaps does nothing, user namespaces are not supported on Windows.
+func (daemon *Daemon) releaseIDMaps(container *container.Container) {
+}
+
+// idMaps returns the ID mappings of the daemon, user namespaces are not
+// supported on Windows.
+func (daemon *Daemon) idMaps(container *container.Container, hostConfig *containertypes.HostConfig) ([]idtools.IDMap, []idtools.IDMap) {
+	return daemon.uidMaps, daemon.gidMaps
+}
+
+// containerIDMaps returns the ID mappings of the daemon, user namespaces are
+// not supported on Windows.
+func (daemon *Daemon) containerIDMaps(container *container.Container) ([]idtools.IDMap, []idtools.IDMap) {
+	return daemon.uidMaps, daemon.gidMaps
+}
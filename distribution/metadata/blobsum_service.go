@@ -31,6 +31,10 @@ func (blobserv *BlobSumService) blobSumNamespace() string {
 	return "blobsum-lookup"
 }
 
+func (blobserv *BlobSumService) mediaTypeNamespace() string {
+	return "blobsum-mediatype"
+}
+
 func (blobserv *BlobSumService) diffIDKey(diffID layer.DiffID) string {
 	return string(digest.Digest(diffID).Algorithm()) + "/" + digest.Digest(diffID).Hex()
 }
@@ -98,3 +102,21 @@ func (blobserv *BlobSumService) Add(diffID layer.DiffID, blobsum digest.Digest)
 
 	return blobserv.store.Set(blobserv.blobSumNamespace(), blobserv.blobSumKey(blobsum), []byte(diffID))
 }
+
+// GetMediaType returns the media type recorded for a blobsum. Blobsums
+// recorded before media types were tracked return an error, and should be
+// assumed to be gzip compressed layers.
+func (blobserv *BlobSumService) GetMediaType(blobsum digest.Digest) (string, error) {
+	mediaType, err := blobserv.store.Get(blobserv.mediaTypeNamespace(), blobserv.blobSumKey(blobsum))
+	if err != nil {
+		return "", err
+	}
+	return string(mediaType), nil
+}
+
+// SetMediaType records the media type of the blob with the given blobsum, so
+// that pushes can tell whether an existing blob uses the requested layer
+// compression.
+func (blobserv *BlobSumService) SetMediaType(blobsum digest.Digest, mediaType string) error {
+	return blobserv.store.Set(blobserv.mediaTypeNamespace(), blobserv.blobSumKey(blobsum), []byte(mediaType))
+}
@@ -103,3 +103,34 @@ func TestBlobSumService(t *testing.T) {
 		t.Fatal("GetDiffID returned incorrect diffID")
 	}
 }
+
+func TestBlobSumServiceMediaType(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "blobsum-mediatype-service-test")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	metadataStore, err := NewFSMetadataStore(tmpDir)
+	if err != nil {
+		t.Fatalf("could not create metadata store: %v", err)
+	}
+	blobSumService := NewBlobSumService(metadataStore)
+
+	blobsum := digest.Digest("sha256:f0cd5ca10b07f35512fc2f1cbf9a6cefbdb5cba70ac6b0c9e5988f4497f71937")
+	if _, err := blobSumService.GetMediaType(blobsum); err == nil {
+		t.Fatal("expected error looking up nonexistent media type")
+	}
+
+	mediaType := "application/vnd.oci.image.layer.v1.tar+zstd"
+	if err := blobSumService.SetMediaType(blobsum, mediaType); err != nil {
+		t.Fatalf("error calling SetMediaType: %v", err)
+	}
+	got, err := blobSumService.GetMediaType(blobsum)
+	if err != nil {
+		t.Fatalf("error calling GetMediaType: %v", err)
+	}
+	if got != mediaType {
+		t.Fatalf("GetMediaType returned %q, expected %q", got, mediaType)
+	}
+}
@@ -111,6 +111,7 @@ func (p *v2Puller) pullV2Repository(ctx context.Context, ref reference.Named) (e
 
 type v2LayerDescriptor struct {
 	digest         digest.Digest
+	mediaType      string
 	repo           distribution.Repository
 	blobSumService *metadata.BlobSumService
 }
@@ -197,6 +198,9 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 func (ld *v2LayerDescriptor) Registered(diffID layer.DiffID) {
 	// Cache mapping from this layer's DiffID to the blobsum
 	ld.blobSumService.Add(diffID, ld.digest)
+	if ld.mediaType != "" {
+		ld.blobSumService.SetMediaType(ld.digest, ld.mediaType)
+	}
 }
 
 func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdated bool, err error) {
@@ -399,6 +403,7 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 	for _, d := range mfst.References() {
 		layerDescriptor := &v2LayerDescriptor{
 			digest:         d.Digest,
+			mediaType:      d.MediaType,
 			repo:           p.repo,
 			blobSumService: p.blobSumService,
 		}
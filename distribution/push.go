@@ -2,15 +2,17 @@ package distribution
 
 import (
 	"bufio"
-	"compress/gzip"
 	"fmt"
 	"io"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
@@ -49,6 +51,10 @@ type ImagePushConfig struct {
 	TrustKey libtrust.PrivateKey
 	// UploadManager dispatches uploads.
 	UploadManager *xfer.LayerUploadManager
+	// LayerCompression is the compression used for layers that are not
+	// already present on the registry. Only archive.Gzip and archive.Zstd
+	// are supported.
+	LayerCompression archive.Compression
 }
 
 // Pusher is an interface that abstracts pushing for different API versions.
@@ -62,6 +68,22 @@ type Pusher interface {
 
 const compressionBufSize = 32768
 
+// MediaTypeLayerZstd is the media type of zstd compressed layers, as defined
+// by the OCI image specification.
+const MediaTypeLayerZstd = "application/vnd.oci.image.layer.v1.tar+zstd"
+
+// layerMediaType returns the media type of layers pushed with the given
+// compression.
+func layerMediaType(compression archive.Compression) (string, error) {
+	switch compression {
+	case archive.Gzip:
+		return schema2.MediaTypeLayer, nil
+	case archive.Zstd:
+		return MediaTypeLayerZstd, nil
+	}
+	return "", fmt.Errorf("unsupported layer compression for push: %s", (&compression).Extension())
+}
+
 // NewPusher creates a new Pusher interface that will push to either a v1 or v2
 // registry. The endpoint argument contains a Version field that determines
 // whether a v1 or v2 pusher will be created. The other parameters are passed
@@ -95,6 +117,13 @@ func NewPusher(ref reference.Named, endpoint registry.APIEndpoint, repoInfo *reg
 func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushConfig) error {
 	// FIXME: Allow to interrupt current push when new push of same image is done.
 
+	if _, err := layerMediaType(imagePushConfig.LayerCompression); err != nil {
+		return err
+	}
+	if err := archive.CheckCompression(imagePushConfig.LayerCompression); err != nil {
+		return err
+	}
+
 	// Resolve the Repository name from fqn to RepositoryInfo
 	repoInfo, err := imagePushConfig.RegistryService.ResolveRepository(ref)
 	if err != nil {
@@ -163,24 +192,27 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 	return lastErr
 }
 
-// compress returns an io.ReadCloser which will supply a compressed version of
-// the provided Reader. The caller must close the ReadCloser after reading the
-// compressed data.
+// compress returns an io.ReadCloser which will supply a version of the
+// provided Reader compressed with the given algorithm. The caller must close
+// the ReadCloser after reading the compressed data.
 //
 // Note that this function returns a reader instead of taking a writer as an
 // argument so that it can be used with httpBlobWriter's ReadFrom method.
 // Using httpBlobWriter's Write method would send a PATCH request for every
 // Write call.
-func compress(in io.Reader) io.ReadCloser {
+func compress(in io.Reader, compression archive.Compression) (io.ReadCloser, error) {
 	pipeReader, pipeWriter := io.Pipe()
 	// Use a bufio.Writer to avoid excessive chunking in HTTP request.
 	bufWriter := bufio.NewWriterSize(pipeWriter, compressionBufSize)
-	compressor := gzip.NewWriter(bufWriter)
+	compressor, err := archive.CompressStream(ioutils.NopWriteCloser(bufWriter), compression)
+	if err != nil {
+		return nil, err
+	}
 
 	go func() {
 		_, err := io.Copy(compressor, in)
-		if err == nil {
-			err = compressor.Close()
+		if closeErr := compressor.Close(); err == nil {
+			err = closeErr
 		}
 		if err == nil {
 			err = bufWriter.Flush()
@@ -192,5 +224,5 @@ func compress(in io.Reader) io.ReadCloser {
 		}
 	}()
 
-	return pipeReader
+	return pipeReader, nil
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/stringid"
@@ -129,10 +130,17 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, ima
 
 	var descriptors []xfer.UploadDescriptor
 
+	mediaType, err := layerMediaType(p.config.LayerCompression)
+	if err != nil {
+		return err
+	}
+
 	descriptorTemplate := v2PushDescriptor{
 		blobSumService: p.blobSumService,
 		repo:           p.repo,
 		pushState:      &p.pushState,
+		compression:    p.config.LayerCompression,
+		mediaType:      mediaType,
 	}
 
 	// Loop bounds condition is to avoid pushing the base layer on Windows.
@@ -162,6 +170,11 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, ima
 
 	putOptions := []distribution.ManifestServiceOption{client.WithTag(ref.Tag())}
 	if _, err = manSvc.Put(ctx, manifest, putOptions...); err != nil {
+		if p.config.LayerCompression != archive.Gzip {
+			// schema1 manifests cannot reference layers that are
+			// not gzip compressed.
+			return fmt.Errorf("failed to upload schema2 manifest: %v", err)
+		}
 		logrus.Warnf("failed to upload schema2 manifest: %v - falling back to schema1", err)
 
 		builder = schema1.NewConfigManifestBuilder(p.repo.Blobs(ctx), p.config.TrustKey, p.repo.Name(), ref.Tag(), img.RawJSON())
@@ -213,6 +226,8 @@ type v2PushDescriptor struct {
 	blobSumService *metadata.BlobSumService
 	repo           distribution.Repository
 	pushState      *pushState
+	compression    archive.Compression
+	mediaType      string
 }
 
 func (pd *v2PushDescriptor) Key() string {
@@ -243,7 +258,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	// Do we have any blobsums associated with this layer's DiffID?
 	possibleBlobsums, err := pd.blobSumService.GetBlobSums(diffID)
 	if err == nil {
-		descriptor, exists, err := blobSumAlreadyExists(ctx, possibleBlobsums, pd.repo, pd.pushState)
+		descriptor, exists, err := blobSumAlreadyExists(ctx, pd.blobSumsWithMediaType(possibleBlobsums), pd.mediaType, pd.repo, pd.pushState)
 		if err != nil {
 			progress.Update(progressOutput, pd.ID(), "Image push failed")
 			return retryOnError(err)
@@ -280,7 +295,10 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 
 	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, arch), progressOutput, size, pd.ID(), "Pushing")
 	defer reader.Close()
-	compressedReader := compress(reader)
+	compressedReader, err := compress(reader, pd.compression)
+	if err != nil {
+		return xfer.DoNotRetry{Err: err}
+	}
 
 	digester := digest.Canonical.New()
 	tee := io.TeeReader(compressedReader, digester.Hash())
@@ -303,6 +321,9 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	if err := pd.blobSumService.Add(diffID, pushDigest); err != nil {
 		return xfer.DoNotRetry{Err: err}
 	}
+	if err := pd.blobSumService.SetMediaType(pushDigest, pd.mediaType); err != nil {
+		return xfer.DoNotRetry{Err: err}
+	}
 
 	pd.pushState.Lock()
 
@@ -312,7 +333,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 
 	pd.pushState.remoteLayers[diffID] = distribution.Descriptor{
 		Digest:    pushDigest,
-		MediaType: schema2.MediaTypeLayer,
+		MediaType: pd.mediaType,
 		Size:      nn,
 	}
 
@@ -329,15 +350,33 @@ func (pd *v2PushDescriptor) Descriptor() distribution.Descriptor {
 	return pd.pushState.remoteLayers[pd.DiffID()]
 }
 
+// blobSumsWithMediaType returns the blobsums that are known to have been
+// pushed or pulled with the descriptor's media type. Blobsums without a
+// recorded media type are gzip compressed layers.
+func (pd *v2PushDescriptor) blobSumsWithMediaType(blobsums []digest.Digest) []digest.Digest {
+	var matching []digest.Digest
+	for _, dgst := range blobsums {
+		mediaType, err := pd.blobSumService.GetMediaType(dgst)
+		if err != nil {
+			mediaType = schema2.MediaTypeLayer
+		}
+		if mediaType == pd.mediaType {
+			matching = append(matching, dgst)
+		}
+	}
+	return matching
+}
+
 // blobSumAlreadyExists checks if the registry already know about any of the
 // blobsums passed in the "blobsums" slice. If it finds one that the registry
-// knows about, it returns the known digest and "true".
-func blobSumAlreadyExists(ctx context.Context, blobsums []digest.Digest, repo distribution.Repository, pushState *pushState) (distribution.Descriptor, bool, error) {
+// knows about, it returns the known digest, with the given media type, and
+// "true".
+func blobSumAlreadyExists(ctx context.Context, blobsums []digest.Digest, mediaType string, repo distribution.Repository, pushState *pushState) (distribution.Descriptor, bool, error) {
 	for _, dgst := range blobsums {
 		descriptor, err := repo.Blobs(ctx).Stat(ctx, dgst)
 		switch err {
 		case nil:
-			descriptor.MediaType = schema2.MediaTypeLayer
+			descriptor.MediaType = mediaType
 			return descriptor, true, nil
 		case distribution.ErrBlobUnknown:
 			// nop
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -23,13 +25,19 @@ const maxDownloadAttempts = 5
 type LayerDownloadManager struct {
 	layerStore layer.Store
 	tm         TransferManager
+
+	// stageLayers controls whether layers that finish downloading before
+	// their parent is registered are extracted to a staging file instead
+	// of waiting with the compressed data.
+	stageLayers bool
 }
 
 // NewLayerDownloadManager returns a new LayerDownloadManager.
 func NewLayerDownloadManager(layerStore layer.Store, concurrencyLimit int) *LayerDownloadManager {
 	return &LayerDownloadManager{
-		layerStore: layerStore,
-		tm:         NewTransferManager(concurrencyLimit),
+		layerStore:  layerStore,
+		tm:          NewTransferManager(concurrencyLimit),
+		stageLayers: true,
 	}
 }
 
@@ -207,6 +215,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				progress.Update(progressOutput, descriptor.ID(), "Waiting")
 				<-start
 			}
+			begin := time.Now()
 
 			if parentDownload != nil {
 				// Did the parent download already fail or get
@@ -229,8 +238,9 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				retries        int
 			)
 
+			counter := &byteCounter{Output: progressOutput, counter: pullBytes}
 			for {
-				downloadReader, size, err = descriptor.Download(d.Transfer.Context(), progressOutput)
+				downloadReader, size, err = descriptor.Download(d.Transfer.Context(), counter)
 				if err == nil {
 					break
 				}
@@ -276,11 +286,51 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 
 			close(inactive)
 
+			reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(d.Transfer.Context(), downloadReader), progressOutput, size, descriptor.ID(), "Extracting")
+
+			inflatedLayerData, err := archive.DecompressStream(reader)
+			if err != nil {
+				reader.Close()
+				d.err = fmt.Errorf("could not get decompression stream: %v", err)
+				return
+			}
+			defer func() {
+				// Closing the decompression stream waits for an
+				// external decompressor like unpigz to exit, which
+				// only happens once its input is closed, so the
+				// download must be closed first.
+				reader.Close()
+				inflatedLayerData.Close()
+			}()
+
+			var layerData io.Reader = inflatedLayerData
 			if parentDownload != nil {
+				// If the parent layer is still being downloaded or
+				// registered, extract this layer into a staging file
+				// in the meantime, so that decompression overlaps with
+				// the registration of the layers below it.
+				select {
+				case <-parentDownload.Done():
+				default:
+					if ldm.stageLayers {
+						staged, err := stageLayer(inflatedLayerData)
+						if err != nil {
+							select {
+							case <-d.Transfer.Context().Done():
+								d.err = errors.New("layer extraction cancelled")
+							default:
+								d.err = fmt.Errorf("failed to stage layer: %v", err)
+							}
+							return
+						}
+						defer staged.Close()
+						layerData = staged
+					}
+				}
+
 				select {
 				case <-d.Transfer.Context().Done():
 					d.err = errors.New("layer registration cancelled")
-					downloadReader.Close()
 					return
 				case <-parentDownload.Done():
 				}
@@ -288,22 +338,12 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				l, err := parentDownload.result()
 				if err != nil {
 					d.err = err
-					downloadReader.Close()
 					return
 				}
 				parentLayer = l.ChainID()
 			}
 
-			reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(d.Transfer.Context(), downloadReader), progressOutput, size, descriptor.ID(), "Extracting")
-			defer reader.Close()
-
-			inflatedLayerData, err := archive.DecompressStream(reader)
-			if err != nil {
-				d.err = fmt.Errorf("could not get decompression stream: %v", err)
-				return
-			}
-
-			d.layer, err = d.layerStore.Register(inflatedLayerData, parentLayer)
+			d.layer, err = d.layerStore.Register(layerData, parentLayer)
 			if err != nil {
 				select {
 				case <-d.Transfer.Context().Done():
@@ -315,6 +355,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 			}
 
 			progress.Update(progressOutput, descriptor.ID(), "Pull complete")
+			pullSeconds.ObserveSince(begin)
 			withRegistered, hasRegistered := descriptor.(DownloadDescriptorWithRegistered)
 			if hasRegistered {
 				withRegistered.Registered(d.layer.DiffID())
@@ -334,6 +375,40 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 	}
 }
 
+// stagedLayer is uncompressed layer data spooled to a temporary file. The
+// file is removed when the stagedLayer is closed.
+type stagedLayer struct {
+	*os.File
+}
+
+// stageLayer copies the uncompressed layer data from r into a temporary file
+// and returns it positioned at the beginning of the data.
+func stageLayer(r io.Reader) (*stagedLayer, error) {
+	f, err := ioutil.TempFile("", "docker-layer-staging-")
+	if err != nil {
+		return nil, err
+	}
+	staged := &stagedLayer{File: f}
+	if _, err := io.Copy(f, r); err != nil {
+		staged.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		staged.Close()
+		return nil, err
+	}
+	return staged, nil
+}
+
+// Close closes and removes the staging file.
+func (s *stagedLayer) Close() error {
+	err := s.File.Close()
+	if rmErr := os.Remove(s.File.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
 // makeDownloadFuncFromDownload returns a function that performs the layer
 // registration when the layer data is coming from an existing download. It
 // waits for sourceDownload and parentDownload to complete, and then
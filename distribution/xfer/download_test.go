@@ -3,8 +3,10 @@ package xfer
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -12,6 +14,9 @@ import (
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"golang.org/x/net/context"
 )
@@ -109,6 +114,10 @@ func (ls *mockLayerStore) CreateRWLayer(string, layer.ChainID, string, layer.Mou
 	return nil, errors.New("not implemented")
 }
 
+func (ls *mockLayerStore) CreateRWLayerWithIDMaps(string, layer.ChainID, string, layer.MountInit, []idtools.IDMap, []idtools.IDMap) (layer.RWLayer, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (ls *mockLayerStore) GetRWLayer(string) (layer.RWLayer, error) {
 	return nil, errors.New("not implemented")
 
@@ -325,3 +334,114 @@ func TestCancelledDownload(t *testing.T) {
 	close(progressChan)
 	<-progressDone
 }
+
+// slowLayerStore simulates the cost of applying a layer to the graph driver
+// by delaying each registration.
+type slowLayerStore struct {
+	*mockLayerStore
+	delay time.Duration
+}
+
+func (ls *slowLayerStore) Register(reader io.Reader, parentID layer.ChainID) (layer.Layer, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(ls.delay)
+	return ls.mockLayerStore.Register(bytes.NewReader(data), parentID)
+}
+
+// compressedDownloadDescriptor returns gzipped layer data without any
+// simulated network delay.
+type compressedDownloadDescriptor struct {
+	id   string
+	data []byte
+}
+
+func (d *compressedDownloadDescriptor) Key() string {
+	return d.id
+}
+
+func (d *compressedDownloadDescriptor) ID() string {
+	return d.id
+}
+
+func (d *compressedDownloadDescriptor) DiffID() (layer.DiffID, error) {
+	return "", errors.New("no diffID available")
+}
+
+func (d *compressedDownloadDescriptor) Download(ctx context.Context, progressOutput progress.Output) (io.ReadCloser, int64, error) {
+	return ioutil.NopCloser(bytes.NewReader(d.data)), int64(len(d.data)), nil
+}
+
+// compressedDescriptors returns a fixture of layerCount gzipped layers with
+// layerSize bytes of uncompressed data each.
+func compressedDescriptors(layerCount, layerSize int) ([]DownloadDescriptor, error) {
+	const alphabet = "0123456789abcdef"
+	rnd := rand.New(rand.NewSource(1))
+
+	var descriptors []DownloadDescriptor
+	for i := 0; i < layerCount; i++ {
+		data := make([]byte, layerSize)
+		for n := range data {
+			data[n] = alphabet[rnd.Intn(len(alphabet))]
+		}
+
+		var buf bytes.Buffer
+		w, err := archive.CompressStream(ioutils.NopWriteCloser(&buf), archive.Gzip)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, &compressedDownloadDescriptor{
+			id:   fmt.Sprintf("layer%d", i),
+			data: buf.Bytes(),
+		})
+	}
+	return descriptors, nil
+}
+
+// benchmarkDownload pulls a fixture of gzipped layers into a layer store
+// which takes some time to apply each layer. With staging, the later layers
+// are decompressed on the spare cores while the earlier ones are applied, so
+// the benchmarks only differ on machines with more than one core.
+func benchmarkDownload(b *testing.B, stageLayers bool) {
+	const (
+		layerCount = 8
+		layerSize  = 8 << 20
+	)
+	descriptors, err := compressedDescriptors(layerCount, layerSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.SetBytes(layerCount * layerSize)
+	for n := 0; n < b.N; n++ {
+		layerStore := &slowLayerStore{
+			mockLayerStore: &mockLayerStore{make(map[layer.ChainID]*mockLayer)},
+			delay:          50 * time.Millisecond,
+		}
+		ldm := NewLayerDownloadManager(layerStore, maxDownloadConcurrency)
+		ldm.stageLayers = stageLayers
+
+		_, releaseFunc, err := ldm.Download(context.Background(), *image.NewRootFS(), descriptors, progress.ChanOutput(make(chan progress.Progress, 10000)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		releaseFunc()
+	}
+}
+
+func BenchmarkDownloadSerialExtraction(b *testing.B) {
+	benchmarkDownload(b, false)
+}
+
+func BenchmarkDownloadStagedExtraction(b *testing.B) {
+	benchmarkDownload(b, true)
+}
@@ -0,0 +1,55 @@
+package xfer
+
+import (
+	"github.com/docker/docker/pkg/metrics"
+	"github.com/docker/docker/pkg/progress"
+)
+
+// transferBuckets are the buckets of the layer transfer durations, in
+// seconds.
+var transferBuckets = []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+var (
+	pullBytes = metrics.NewCounter(
+		"engine_daemon_image_pull_bytes_total",
+		"The number of bytes of image layers downloaded",
+	)
+	pushBytes = metrics.NewCounter(
+		"engine_daemon_image_push_bytes_total",
+		"The number of bytes of image layers uploaded",
+	)
+	pullSeconds = metrics.NewHistogram(
+		"engine_daemon_image_pull_layer_seconds",
+		"The number of seconds it takes to download and register an image layer",
+		transferBuckets,
+	)
+	pushSeconds = metrics.NewHistogram(
+		"engine_daemon_image_push_layer_seconds",
+		"The number of seconds it takes to upload an image layer",
+		transferBuckets,
+	)
+)
+
+func init() {
+	metrics.MustRegister(pullBytes, pushBytes, pullSeconds, pushSeconds)
+}
+
+// byteCounter is a progress output that adds the bytes transferred, as
+// reported by the progress of a single transfer, to a counter. A transfer
+// that is retried starts reporting its progress from the beginning again.
+type byteCounter struct {
+	progress.Output
+	counter *metrics.Counter
+	current int64
+}
+
+func (c *byteCounter) WriteProgress(p progress.Progress) error {
+	if p.Current > 0 {
+		if p.Current < c.current {
+			c.current = 0
+		}
+		c.counter.Add(float64(p.Current - c.current))
+		c.current = p.Current
+	}
+	return c.Output.WriteProgress(p)
+}
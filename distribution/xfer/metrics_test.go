@@ -0,0 +1,38 @@
+package xfer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/docker/pkg/metrics"
+	"github.com/docker/docker/pkg/progress"
+)
+
+type discardOutput struct{}
+
+func (discardOutput) WriteProgress(progress.Progress) error {
+	return nil
+}
+
+func TestByteCounter(t *testing.T) {
+	counter := metrics.NewCounter("test_bytes_total", "Test")
+	out := &byteCounter{Output: discardOutput{}, counter: counter}
+
+	for _, p := range []progress.Progress{
+		{Action: "Downloading", Current: 100, Total: 300},
+		{Action: "Downloading", Current: 250, Total: 300},
+		// A status update without progress is not counted.
+		{Action: "Retrying in 5 seconds"},
+		// The retry starts from the beginning.
+		{Action: "Downloading", Current: 50, Total: 300},
+		{Action: "Downloading", Current: 300, Total: 300},
+	} {
+		out.WriteProgress(p)
+	}
+
+	var buf bytes.Buffer
+	counter.Write(&buf)
+	if !bytes.Contains(buf.Bytes(), []byte("test_bytes_total 550\n")) {
+		t.Fatalf("Expected 550 bytes to be counted, got\n%s", buf.String())
+	}
+}
@@ -103,10 +103,13 @@ func (lum *LayerUploadManager) makeUploadFunc(descriptor UploadDescriptor) DoFun
 				<-start
 			}
 
+			begin := time.Now()
+			counter := &byteCounter{Output: progressOutput, counter: pushBytes}
 			retries := 0
 			for {
-				err := descriptor.Upload(u.Transfer.Context(), progressOutput)
+				err := descriptor.Upload(u.Transfer.Context(), counter)
 				if err == nil {
+					pushSeconds.ObserveSince(begin)
 					break
 				}
 
@@ -0,0 +1,24 @@
+// +build linux
+
+// docker-init is the init process of containers run with --init. It is kept
+// small and static, as it is bind mounted into containers.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/pkg/reaper"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: docker-init COMMAND [ARG...]")
+		os.Exit(1)
+	}
+	status, err := reaper.Run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(status)
+}
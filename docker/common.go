@@ -14,10 +14,11 @@ import (
 )
 
 const (
-	defaultTrustKeyFile = "key.json"
-	defaultCaFile       = "ca.pem"
-	defaultKeyFile      = "key.pem"
-	defaultCertFile     = "cert.pem"
+	defaultTrustKeyFile   = "key.json"
+	defaultSecretsKeyFile = "secrets.key"
+	defaultCaFile         = "ca.pem"
+	defaultKeyFile        = "key.pem"
+	defaultCertFile       = "cert.pem"
 )
 
 var (
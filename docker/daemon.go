@@ -47,6 +47,7 @@ func NewDaemonCli() *DaemonCli {
 	daemonConfig := new(daemon.Config)
 	daemonConfig.LogConfig.Config = make(map[string]string)
 	daemonConfig.ClusterOpts = make(map[string]string)
+	daemonConfig.AuditLogOpts = make(map[string]string)
 	daemonConfig.InstallFlags(daemonFlags, presentInHelp)
 	daemonConfig.InstallFlags(flag.CommandLine, absentFromHelp)
 	registryOptions := new(registry.Options)
@@ -145,6 +146,9 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	if commonFlags.TrustKey == "" {
 		commonFlags.TrustKey = filepath.Join(getDaemonConfDir(), defaultTrustKeyFile)
 	}
+	if cli.Config.SecretsKeyPath == "" {
+		cli.Config.SecretsKeyPath = filepath.Join(getDaemonConfDir(), defaultSecretsKeyFile)
+	}
 
 	if utils.ExperimentalBuild() {
 		logrus.Warn("Running experimental build")
@@ -180,6 +184,9 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 		AuthZPluginNames: cli.Config.AuthZPlugins,
 		Logging:          true,
 		Version:          dockerversion.Version,
+		MetricsAddr:      cli.Config.MetricsAddress,
+		AuditLog:         cli.Config.AuditLog,
+		AuditLogOpts:     cli.Config.AuditLogOpts,
 	}
 	serverConfig = setPlatformServerConfig(serverConfig, cli.Config)
 
@@ -194,6 +201,7 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 			logrus.Fatal(err)
 		}
 		serverConfig.TLSConfig = tlsConfig
+		serverConfig.TLSOptions = commonFlags.TLSOptions
 		defaultHost = opts.DefaultTLSHost
 	}
 
@@ -244,6 +252,12 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}).Info("Docker daemon")
 
 	api.InitRouters(d)
+	if policy := d.AuthZPolicy(); policy != nil {
+		api.SetAuthZPolicy(policy)
+	}
+	api.SetTLSReloadHook(func(attributes map[string]string) {
+		d.LogDaemonEvent("tls-reload", attributes)
+	})
 
 	// The serve API routine never exits unless an error occurs
 	// We need to start it as a goroutine and wait on it so
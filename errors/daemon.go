@@ -144,6 +144,15 @@ var (
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
 
+	// ErrorCodeDeviceCgroupRule is generated when a device cgroup rule
+	// is not in the expected format.
+	ErrorCodeDeviceCgroupRule = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "DEVICECGROUPRULE",
+		Message:        "Invalid device cgroup rule %q, expected 'type major:minor access'",
+		Description:    "A device cgroup rule is not in the 'type major:minor access' format",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
 	// ErrorCodeEmptyEndpoint is generated when the endpoint for a port
 	// map is nil.
 	ErrorCodeEmptyEndpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
@@ -957,4 +966,137 @@ var (
 		Description:    "Engine's predefined networks cannot be deleted",
 		HTTPStatusCode: http.StatusForbidden,
 	})
+
+	// ErrorCodeNoInit is generated when a container is run under an init,
+	// but no init binary can be found.
+	ErrorCodeNoInit = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOINIT",
+		Message:        "Cannot find the docker-init binary to run as the init of the container",
+		Description:    "The container is run under an init, but the daemon cannot find its docker-init binary",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeNoSuchCheckpoint is generated when a checkpoint of a
+	// container can not be found.
+	ErrorCodeNoSuchCheckpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOSUCHCHECKPOINT",
+		Message:        "No such checkpoint: %s",
+		Description:    "The specified checkpoint of the container can not be found",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeCheckpointExists is generated when a checkpoint is created
+	// with the name of an existing checkpoint of the container.
+	ErrorCodeCheckpointExists = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CHECKPOINTEXISTS",
+		Message:        "Checkpoint %s already exists",
+		Description:    "A checkpoint of the container with the specified name already exists",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeCheckpointName is generated when a checkpoint name is not
+	// valid.
+	ErrorCodeCheckpointName = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CHECKPOINTNAME",
+		Message:        "Invalid checkpoint name (%s), only %s are allowed",
+		Description:    "The checkpoint name contains characters which are not allowed",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeCantCheckpoint is generated when a container can not be
+	// checkpointed in its current state.
+	ErrorCodeCantCheckpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTCHECKPOINT",
+		Message:        "Cannot checkpoint container %s: %s",
+		Description:    "The container can not be checkpointed in its current state",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeTrustPolicy is generated when an image is pulled or used
+	// in violation of the daemon's trust policy.
+	ErrorCodeTrustPolicy = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "TRUSTPOLICY",
+		Message:        "%v",
+		Description:    "The image is not acceptable under the daemon's trust policy",
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
+	// ErrorCodeAdmissionPolicy is generated when a container is created
+	// from an image which violates the daemon's admission policy.
+	ErrorCodeAdmissionPolicy = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "ADMISSIONPOLICY",
+		Message:        "%v",
+		Description:    "The image violates a rule of the daemon's admission policy",
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
+	// ErrorCodeNoSecurityProfiles is generated when the security profiles
+	// are reloaded with an execution driver which has none.
+	ErrorCodeNoSecurityProfiles = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOSECURITYPROFILES",
+		Message:        "The %s execution driver has no security profiles to reload",
+		Description:    "The execution driver of the daemon does not support reloading security profiles",
+		HTTPStatusCode: http.StatusNotImplemented,
+	})
+
+	// ErrorCodeNoAuthZPolicy is generated when the authorization policy is
+	// reloaded by a daemon which has none.
+	ErrorCodeNoAuthZPolicy = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOAUTHZPOLICY",
+		Message:        "The daemon has no authorization policy to reload, it is set with --authz-policy",
+		Description:    "The daemon was started without an authorization policy file",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeNoAdmissionPolicy is generated when the admission policy is
+	// reloaded by a daemon which has none.
+	ErrorCodeNoAdmissionPolicy = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOADMISSIONPOLICY",
+		Message:        "The daemon has no admission policy to reload, it is set with --admission-policy",
+		Description:    "The daemon was started without an admission policy file",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeNoSuchSecret is generated when a secret can not be found.
+	ErrorCodeNoSuchSecret = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOSUCHSECRET",
+		Message:        "No such secret: %s",
+		Description:    "The specified secret can not be found",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeSecretExists is generated when a secret is created with the
+	// name of an existing secret.
+	ErrorCodeSecretExists = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "SECRETEXISTS",
+		Message:        "Secret %s already exists",
+		Description:    "A secret with the specified name already exists",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeSecretName is generated when a secret name is not valid.
+	ErrorCodeSecretName = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "SECRETNAME",
+		Message:        "Invalid secret name (%s), only %s are allowed",
+		Description:    "The secret name contains characters which are not allowed",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeSecretInUse is generated when a secret mounted in a
+	// container is removed.
+	ErrorCodeSecretInUse = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "SECRETINUSE",
+		Message:        "Unable to remove secret %s, it is used by container %s",
+		Description:    "The secret is mounted in a container and can not be removed",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeSecretTarget is generated when the target of a secret in a
+	// container is not valid.
+	ErrorCodeSecretTarget = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "SECRETTARGET",
+		Message:        "Invalid target %s of secret %s: %s",
+		Description:    "The path of a secret in the container is not valid",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
 )
@@ -128,6 +128,9 @@ install -p -m 755 bundles/%{_origversion}/dynbinary/docker-%{_origversion} $RPM_
 install -d $RPM_BUILD_ROOT/%{_libexecdir}/docker
 install -p -m 755 bundles/%{_origversion}/dynbinary/dockerinit-%{_origversion} $RPM_BUILD_ROOT/%{_libexecdir}/docker/dockerinit
 
+# install docker-init
+install -p -m 755 bundles/%{_origversion}/dynbinary/docker-init-%{_origversion} $RPM_BUILD_ROOT/%{_libexecdir}/docker/docker-init
+
 # install udev rules
 install -d $RPM_BUILD_ROOT/%{_sysconfdir}/udev/rules.d
 install -p -m 644 contrib/udev/80-docker.rules $RPM_BUILD_ROOT/%{_sysconfdir}/udev/rules.d/80-docker.rules
@@ -176,6 +179,7 @@ install -p -m 644 contrib/syntax/nano/Dockerfile.nanorc $RPM_BUILD_ROOT/usr/shar
 %doc AUTHORS CHANGELOG.md CONTRIBUTING.md LICENSE MAINTAINERS NOTICE README.md
 /%{_bindir}/docker
 /%{_libexecdir}/docker/dockerinit
+/%{_libexecdir}/docker/docker-init
 /%{_sysconfdir}/udev/rules.d/80-docker.rules
 %if 0%{?is_systemd}
 /%{_unitdir}/docker.service
@@ -56,6 +56,14 @@ clone git github.com/miekg/pkcs11 80f102b5cac759de406949c47f0928b99bd64cdf
 clone git github.com/jfrazelle/go v1.5.1-1
 clone git github.com/agl/ed25519 d2b94fd789ea21d12fac1a4443dd3a3f79cda72c
 
+# TODO: bump to an upstream runc with the pids cgroup and
+# Config.NoNewPrivileges; until then the vendored copy carries these changes
+# and re-running this script drops them:
+# - pids cgroup: cgroups/fs/pids.go (new), cgroups/fs/apply_raw.go,
+#   cgroups/systemd/apply_systemd.go (joinPids), configs/cgroup_unix.go
+#   (PidsLimit)
+# - no_new_privs: configs/config.go, standard_init_linux.go,
+#   setns_init_linux.go, system/linux.go (Prctl)
 clone git github.com/opencontainers/runc d97d5e8b007e4657316eed76ea30bc0f690230cf # libcontainer
 clone git github.com/seccomp/libseccomp-golang 1b506fc7c24eec5a3693cdcbed40d9c226cfc6a1
 # libcontainer deps (see src/github.com/opencontainers/runc/Godeps/Godeps.json)
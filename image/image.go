@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/engine-api/types/container"
 )
 
@@ -108,7 +109,30 @@ type History struct {
 type Exporter interface {
 	Load(io.ReadCloser, io.Writer) error
 	// TODO: Load(net.Context, io.ReadCloser, <- chan StatusMessage) error
-	Save([]string, io.Writer) error
+	Save([]string, ExportOptions, io.Writer) error
+}
+
+const (
+	// ExportFormatDocker is Docker's image archive format, with a
+	// manifest.json file and a directory per layer.
+	ExportFormatDocker = "docker"
+	// ExportFormatOCI is the OCI image layout format, with an index.json
+	// file and content-addressable blobs.
+	ExportFormatOCI = "oci"
+)
+
+// ExportOptions holds the options for exporting images.
+type ExportOptions struct {
+	// Format is the archive format, ExportFormatDocker or
+	// ExportFormatOCI. An empty format selects ExportFormatDocker.
+	Format string
+	// LayerCompression is the compression applied to each layer tarball
+	// in the exported archive.
+	LayerCompression archive.Compression
+	// ExcludeBase names an image whose layers are left out of the
+	// archive, for receivers which already have that image. Only
+	// supported with ExportFormatDocker.
+	ExcludeBase string
 }
 
 // NewFromJSON creates an Image configuration from json.
@@ -28,6 +28,14 @@ func (l *tarexporter) Load(inTar io.ReadCloser, outStream io.Writer) error {
 	if err := chrootarchive.Untar(inTar, tmpDir, nil); err != nil {
 		return err
 	}
+	// load OCI image layouts, detected by their oci-layout file
+	ociLayoutPath, err := safePath(tmpDir, ociLayoutFileName)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(ociLayoutPath); err == nil {
+		return l.loadOCI(tmpDir, outStream)
+	}
 	// read manifest, if no file then load in legacy mode
 	manifestPath, err := safePath(tmpDir, manifestFileName)
 	if err != nil {
@@ -60,33 +68,30 @@ func (l *tarexporter) Load(inTar io.ReadCloser, outStream io.Writer) error {
 		if err != nil {
 			return err
 		}
-		var rootFS image.RootFS
-		rootFS = *img.RootFS
-		rootFS.DiffIDs = nil
 
-		if expected, actual := len(m.Layers), len(img.RootFS.DiffIDs); expected != actual {
-			return fmt.Errorf("invalid manifest, layers length mismatch: expected %q, got %q", expected, actual)
-		}
-
-		for i, diffID := range img.RootFS.DiffIDs {
-			layerPath, err := safePath(tmpDir, m.Layers[i])
+		// layers left out of a partial archive must already be present
+		var layerPaths []string
+		if m.ParentLayer != "" {
+			parentLayer, err := l.parentLayer(img, m)
 			if err != nil {
 				return err
 			}
-			r := rootFS
-			r.Append(diffID)
-			newLayer, err := l.ls.Get(r.ChainID())
+			defer layer.ReleaseAndLog(l.ls, parentLayer)
+			layerPaths = make([]string, len(img.RootFS.DiffIDs)-len(m.Layers))
+		}
+		for _, layerName := range m.Layers {
+			layerPath, err := safePath(tmpDir, layerName)
 			if err != nil {
-				newLayer, err = l.loadLayer(layerPath, rootFS)
-				if err != nil {
-					return err
-				}
+				return err
 			}
+			layerPaths = append(layerPaths, layerPath)
+		}
+		layers, err := l.loadLayers(img, layerPaths)
+		for _, newLayer := range layers {
 			defer layer.ReleaseAndLog(l.ls, newLayer)
-			if expected, actual := diffID, newLayer.DiffID(); expected != actual {
-				return fmt.Errorf("invalid diffID for layer %d: expected %q, got %q", i, expected, actual)
-			}
-			rootFS.Append(diffID)
+		}
+		if err != nil {
+			return err
 		}
 
 		imgID, err := l.is.Create(config)
@@ -111,6 +116,63 @@ func (l *tarexporter) Load(inTar io.ReadCloser, outStream io.Writer) error {
 	return nil
 }
 
+// parentLayer returns the layer which the layers of a partial archive are
+// stacked on, checking that it is present in the layer store and matches the
+// bottom layers of img.
+func (l *tarexporter) parentLayer(img *image.Image, m manifestItem) (layer.Layer, error) {
+	if len(m.Layers) >= len(img.RootFS.DiffIDs) {
+		return nil, fmt.Errorf("invalid manifest, no layers left out for parent layer %s", m.ParentLayer)
+	}
+	rootFS := *img.RootFS
+	rootFS.DiffIDs = rootFS.DiffIDs[:len(rootFS.DiffIDs)-len(m.Layers)]
+	if chainID := rootFS.ChainID(); chainID != m.ParentLayer {
+		return nil, fmt.Errorf("invalid manifest, parent layer %s does not match image layers %s", m.ParentLayer, chainID)
+	}
+	parentLayer, err := l.ls.Get(m.ParentLayer)
+	if err != nil {
+		return nil, fmt.Errorf("parent layer %s of image %s is not present, load its base image first", m.ParentLayer, m.Config)
+	}
+	return parentLayer, nil
+}
+
+// loadLayers makes sure the layers of img are present in the layer store,
+// registering them from the tar files in layerPaths where needed. Layers with
+// an empty path must already be present. The caller
+// must release the returned layers once the image has been created, even if
+// an error is returned.
+func (l *tarexporter) loadLayers(img *image.Image, layerPaths []string) ([]layer.Layer, error) {
+	if expected, actual := len(layerPaths), len(img.RootFS.DiffIDs); expected != actual {
+		return nil, fmt.Errorf("invalid manifest, layers length mismatch: expected %d, got %d", expected, actual)
+	}
+
+	var (
+		layers []layer.Layer
+		rootFS = *img.RootFS
+	)
+	rootFS.DiffIDs = nil
+
+	for i, diffID := range img.RootFS.DiffIDs {
+		r := rootFS
+		r.Append(diffID)
+		newLayer, err := l.ls.Get(r.ChainID())
+		if err != nil {
+			if layerPaths[i] == "" {
+				return layers, fmt.Errorf("layer %d is not present and not included in the archive", i)
+			}
+			newLayer, err = l.loadLayer(layerPaths[i], rootFS)
+			if err != nil {
+				return layers, err
+			}
+		}
+		layers = append(layers, newLayer)
+		if expected, actual := diffID, newLayer.DiffID(); expected != actual {
+			return layers, fmt.Errorf("invalid diffID for layer %d: expected %q, got %q", i, expected, actual)
+		}
+		rootFS.Append(diffID)
+	}
+	return layers, nil
+}
+
 func (l *tarexporter) loadLayer(filename string, rootFS image.RootFS) (layer.Layer, error) {
 	rawTar, err := os.Open(filename)
 	if err != nil {
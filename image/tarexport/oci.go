@@ -0,0 +1,448 @@
+package tarexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/reference"
+)
+
+const (
+	ociLayoutFileName = "oci-layout"
+	ociIndexFileName  = "index.json"
+	ociBlobsDirName   = "blobs"
+	ociLayoutVersion  = "1.0.0"
+
+	ociIndexMediaType    = "application/vnd.oci.image.index.v1+json"
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ociConfigMediaType   = "application/vnd.oci.image.config.v1+json"
+	ociLayerMediaType    = "application/vnd.oci.image.layer.v1.tar"
+
+	// ociRefNameAnnotation holds the tag of an image in the index.
+	ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+	// ociImageNameAnnotation holds the full reference of an image in the
+	// index, since the OCI annotation only holds the tag. It is the same
+	// annotation other OCI tools use for this purpose.
+	ociImageNameAnnotation = "io.containerd.image.name"
+)
+
+type ociLayout struct {
+	ImageLayoutVersion string `json:"imageLayoutVersion"`
+}
+
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      digest.Digest     `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *ociPlatform      `json:"platform,omitempty"`
+}
+
+// byImageName sorts the descriptors of an index by the image name they are
+// annotated with, then by digest.
+type byImageName []ociDescriptor
+
+func (d byImageName) Len() int      { return len(d) }
+func (d byImageName) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d byImageName) Less(i, j int) bool {
+	if ni, nj := d[i].Annotations[ociImageNameAnnotation], d[j].Annotations[ociImageNameAnnotation]; ni != nj {
+		return ni < nj
+	}
+	return d[i].Digest < d[j].Digest
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType,omitempty"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType,omitempty"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+// ociLayerMediaTypeFor returns the OCI media type of layers with the given
+// compression.
+func ociLayerMediaTypeFor(compression archive.Compression) (string, error) {
+	switch compression {
+	case archive.Uncompressed:
+		return ociLayerMediaType, nil
+	case archive.Gzip:
+		return ociLayerMediaType + "+gzip", nil
+	case archive.Zstd:
+		return ociLayerMediaType + "+zstd", nil
+	}
+	return "", fmt.Errorf("unsupported layer compression for OCI image layout: %s", (&compression).Extension())
+}
+
+func (s *saveSession) saveOCI(outStream io.Writer) error {
+	layerMediaType, err := ociLayerMediaTypeFor(s.options.LayerCompression)
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := ioutil.TempDir("", "docker-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	s.outDir = tempDir
+	if err := os.MkdirAll(filepath.Join(tempDir, ociBlobsDirName, string(digest.Canonical)), 0755); err != nil {
+		return err
+	}
+
+	savedLayers := make(map[layer.ChainID]ociDescriptor)
+	index := ociIndex{SchemaVersion: 2}
+
+	for id, imageDescr := range s.images {
+		manifest, err := s.saveOCIImage(id, layerMediaType, savedLayers)
+		if err != nil {
+			return err
+		}
+
+		if len(imageDescr.refs) == 0 {
+			index.Manifests = append(index.Manifests, manifest)
+			continue
+		}
+		for _, ref := range imageDescr.refs {
+			tagged := manifest
+			tagged.Annotations = map[string]string{
+				ociRefNameAnnotation:   ref.Tag(),
+				ociImageNameAnnotation: ref.String(),
+			}
+			index.Manifests = append(index.Manifests, tagged)
+		}
+	}
+
+	// the images are saved in map order, so the index is sorted for the same
+	// images to always be saved in the same archive
+	sort.Sort(byImageName(index.Manifests))
+	if err := writeOCIFile(filepath.Join(tempDir, ociIndexFileName), index); err != nil {
+		return err
+	}
+	if err := writeOCIFile(filepath.Join(tempDir, ociLayoutFileName), ociLayout{ImageLayoutVersion: ociLayoutVersion}); err != nil {
+		return err
+	}
+
+	fs, err := archive.Tar(tempDir, archive.Uncompressed)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	_, err = io.Copy(outStream, fs)
+	return err
+}
+
+// saveOCIImage writes the layers, config and manifest of an image as blobs
+// and returns the descriptor of the manifest. savedLayers holds the
+// descriptors of the layers written so far, which are shared between images.
+func (s *saveSession) saveOCIImage(id image.ID, layerMediaType string, savedLayers map[layer.ChainID]ociDescriptor) (ociDescriptor, error) {
+	img, err := s.is.Get(id)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+
+	if len(img.RootFS.DiffIDs) == 0 {
+		return ociDescriptor{}, fmt.Errorf("empty export - not implemented")
+	}
+
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+	}
+
+	for i := range img.RootFS.DiffIDs {
+		rootFS := *img.RootFS
+		rootFS.DiffIDs = rootFS.DiffIDs[:i+1]
+		chainID := rootFS.ChainID()
+
+		descriptor, saved := savedLayers[chainID]
+		if !saved {
+			if descriptor, err = s.saveOCILayer(chainID, layerMediaType); err != nil {
+				return ociDescriptor{}, err
+			}
+			savedLayers[chainID] = descriptor
+		}
+		manifest.Layers = append(manifest.Layers, descriptor)
+	}
+
+	if manifest.Config, err = s.writeOCIBlob(ociConfigMediaType, func(w io.Writer) error {
+		_, err := w.Write(img.RawJSON())
+		return err
+	}); err != nil {
+		return ociDescriptor{}, err
+	}
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	return s.writeOCIBlob(ociManifestMediaType, func(w io.Writer) error {
+		_, err := w.Write(manifestJSON)
+		return err
+	})
+}
+
+func (s *saveSession) saveOCILayer(id layer.ChainID, mediaType string) (ociDescriptor, error) {
+	l, err := s.ls.Get(id)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer layer.ReleaseAndLog(s.ls, l)
+
+	arch, err := l.TarStream()
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer arch.Close()
+
+	return s.writeOCIBlob(mediaType, func(w io.Writer) error {
+		return s.writeLayerData(ioutils.NopWriteCloser(w), arch)
+	})
+}
+
+// writeOCIBlob stores the data written by write as a content-addressable
+// blob, and returns its descriptor.
+func (s *saveSession) writeOCIBlob(mediaType string, write func(io.Writer) error) (ociDescriptor, error) {
+	blobsDir := filepath.Join(s.outDir, ociBlobsDirName, string(digest.Canonical))
+	f, err := ioutil.TempFile(blobsDir, ".tmp-")
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer f.Close()
+
+	digester := digest.Canonical.New()
+	if err := write(io.MultiWriter(f, digester.Hash())); err != nil {
+		os.Remove(f.Name())
+		return ociDescriptor{}, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		os.Remove(f.Name())
+		return ociDescriptor{}, err
+	}
+
+	dgst := digester.Digest()
+	if err := os.Rename(f.Name(), filepath.Join(blobsDir, dgst.Hex())); err != nil {
+		os.Remove(f.Name())
+		return ociDescriptor{}, err
+	}
+
+	return ociDescriptor{
+		MediaType: mediaType,
+		Digest:    dgst,
+		Size:      fi.Size(),
+	}, nil
+}
+
+func writeOCIFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	return os.Chtimes(path, time.Unix(0, 0), time.Unix(0, 0))
+}
+
+func (l *tarexporter) loadOCI(tmpDir string, outStream io.Writer) error {
+	layoutPath, err := safePath(tmpDir, ociLayoutFileName)
+	if err != nil {
+		return err
+	}
+	layoutData, err := ioutil.ReadFile(layoutPath)
+	if err != nil {
+		return err
+	}
+	var layout ociLayout
+	if err := json.Unmarshal(layoutData, &layout); err != nil {
+		return fmt.Errorf("invalid OCI image layout: %v", err)
+	}
+	if layout.ImageLayoutVersion != ociLayoutVersion {
+		return fmt.Errorf("unsupported OCI image layout version %q", layout.ImageLayoutVersion)
+	}
+
+	indexPath, err := safePath(tmpDir, ociIndexFileName)
+	if err != nil {
+		return err
+	}
+	indexData, err := ioutil.ReadFile(indexPath)
+	if err != nil {
+		return err
+	}
+	return l.loadOCIIndex(tmpDir, indexData, outStream)
+}
+
+func (l *tarexporter) loadOCIIndex(tmpDir string, indexData []byte, outStream io.Writer) error {
+	var index ociIndex
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return fmt.Errorf("invalid OCI image index: %v", err)
+	}
+
+	for _, descriptor := range index.Manifests {
+		if p := descriptor.Platform; p != nil && (p.OS != runtime.GOOS || p.Architecture != runtime.GOARCH) {
+			logrus.Debugf("Skipping %s for platform %s/%s", descriptor.Digest, p.OS, p.Architecture)
+			continue
+		}
+
+		switch descriptor.MediaType {
+		case ociManifestMediaType:
+			if err := l.loadOCIManifest(tmpDir, descriptor, outStream); err != nil {
+				return err
+			}
+		case ociIndexMediaType:
+			nestedIndex, err := readOCIBlob(tmpDir, descriptor)
+			if err != nil {
+				return err
+			}
+			if err := l.loadOCIIndex(tmpDir, nestedIndex, outStream); err != nil {
+				return err
+			}
+		default:
+			logrus.Warnf("Skipping %s with unsupported media type %s", descriptor.Digest, descriptor.MediaType)
+		}
+	}
+	return nil
+}
+
+func (l *tarexporter) loadOCIManifest(tmpDir string, descriptor ociDescriptor, outStream io.Writer) error {
+	manifestData, err := readOCIBlob(tmpDir, descriptor)
+	if err != nil {
+		return err
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return fmt.Errorf("invalid OCI image manifest %s: %v", descriptor.Digest, err)
+	}
+
+	config, err := readOCIBlob(tmpDir, manifest.Config)
+	if err != nil {
+		return err
+	}
+	img, err := image.NewFromJSON(config)
+	if err != nil {
+		return err
+	}
+
+	var layerPaths []string
+	for _, layerDescriptor := range manifest.Layers {
+		layerPath, err := ociBlobPath(tmpDir, layerDescriptor.Digest)
+		if err != nil {
+			return err
+		}
+		if err := verifyOCIBlob(layerPath, layerDescriptor); err != nil {
+			return err
+		}
+		layerPaths = append(layerPaths, layerPath)
+	}
+	layers, err := l.loadLayers(img, layerPaths)
+	for _, newLayer := range layers {
+		defer layer.ReleaseAndLog(l.ls, newLayer)
+	}
+	if err != nil {
+		return err
+	}
+
+	imgID, err := l.is.Create(config)
+	if err != nil {
+		return err
+	}
+
+	ref, ok := ociImageReference(descriptor.Annotations)
+	if !ok {
+		fmt.Fprintf(outStream, "Loaded image ID: %s\n", imgID)
+		return nil
+	}
+	return l.setLoadedTag(ref, imgID, outStream)
+}
+
+// ociImageReference returns the name of an image from the annotations of its
+// index entry. The full name is taken from the annotation used by other OCI
+// tools, or from the OCI reference name annotation if it holds a full name
+// rather than just a tag.
+func ociImageReference(annotations map[string]string) (reference.NamedTagged, bool) {
+	for _, key := range []string{ociImageNameAnnotation, ociRefNameAnnotation} {
+		name := annotations[key]
+		if name == "" {
+			continue
+		}
+		named, err := reference.ParseNamed(name)
+		if err != nil {
+			continue
+		}
+		if tagged, ok := named.(reference.NamedTagged); ok {
+			return tagged, true
+		}
+	}
+	return nil, false
+}
+
+func ociBlobPath(tmpDir string, dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", err
+	}
+	return safePath(tmpDir, filepath.Join(ociBlobsDirName, string(dgst.Algorithm()), dgst.Hex()))
+}
+
+// readOCIBlob reads the blob referenced by descriptor and verifies its
+// size and digest.
+func readOCIBlob(tmpDir string, descriptor ociDescriptor) ([]byte, error) {
+	blobPath, err := ociBlobPath(tmpDir, descriptor.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyOCIBlob(blobPath, descriptor); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(blobPath)
+}
+
+// verifyOCIBlob checks that the blob file at blobPath has the size and
+// digest of descriptor.
+func verifyOCIBlob(blobPath string, descriptor ociDescriptor) error {
+	f, err := os.Open(blobPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	verifier, err := digest.NewDigestVerifier(descriptor.Digest)
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(verifier, f)
+	if err != nil {
+		return err
+	}
+	if size != descriptor.Size {
+		return fmt.Errorf("size mismatch for blob %s: expected %d bytes, got %d", descriptor.Digest, descriptor.Size, size)
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("digest mismatch for blob %s", descriptor.Digest)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package tarexport
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/reference"
+)
+
+func TestOCIImageReference(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			annotations: map[string]string{
+				ociRefNameAnnotation:   "latest",
+				ociImageNameAnnotation: "busybox:latest",
+			},
+			expected: "busybox:latest",
+		},
+		{
+			annotations: map[string]string{
+				ociRefNameAnnotation: "registry.example.com/app:1.0",
+			},
+			expected: "registry.example.com/app:1.0",
+		},
+		{
+			annotations: map[string]string{
+				ociRefNameAnnotation: "latest",
+			},
+		},
+		{},
+	}
+
+	for _, tc := range testCases {
+		ref, ok := ociImageReference(tc.annotations)
+		if tc.expected == "" {
+			if ok {
+				t.Fatalf("expected no reference for %v, got %s", tc.annotations, ref)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatalf("expected reference %s for %v", tc.expected, tc.annotations)
+		}
+		if ref.String() != tc.expected {
+			t.Fatalf("expected reference %s for %v, got %s", tc.expected, tc.annotations, ref)
+		}
+	}
+}
+
+func TestOCILayerMediaType(t *testing.T) {
+	for compression, expected := range map[archive.Compression]string{
+		archive.Uncompressed: "application/vnd.oci.image.layer.v1.tar",
+		archive.Gzip:         "application/vnd.oci.image.layer.v1.tar+gzip",
+		archive.Zstd:         "application/vnd.oci.image.layer.v1.tar+zstd",
+	} {
+		mediaType, err := ociLayerMediaTypeFor(compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mediaType != expected {
+			t.Fatalf("expected %s, got %s", expected, mediaType)
+		}
+	}
+	if _, err := ociLayerMediaTypeFor(archive.Xz); err == nil {
+		t.Fatal("expected an error for xz compressed layers")
+	}
+}
+
+func TestReadOCIBlob(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "oci-blob-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	data := []byte(`{"schemaVersion":2}`)
+	dgst := digest.FromBytes(data)
+	blobsDir := filepath.Join(tmpDir, ociBlobsDirName, string(dgst.Algorithm()))
+	if err := os.MkdirAll(blobsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(blobsDir, dgst.Hex()), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor := ociDescriptor{Digest: dgst, Size: int64(len(data))}
+	blob, err := readOCIBlob(tmpDir, descriptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(blob) != string(data) {
+		t.Fatalf("expected %s, got %s", data, blob)
+	}
+
+	// A blob whose size doesn't match its descriptor must be rejected.
+	if _, err := readOCIBlob(tmpDir, ociDescriptor{Digest: dgst, Size: 1}); err == nil {
+		t.Fatal("expected a size mismatch error")
+	}
+
+	// A blob whose content doesn't match its name must be rejected.
+	if err := ioutil.WriteFile(filepath.Join(blobsDir, dgst.Hex()), []byte(`{"schemaVersion":3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readOCIBlob(tmpDir, descriptor); err == nil {
+		t.Fatal("expected a digest mismatch error")
+	}
+
+	if _, err := readOCIBlob(tmpDir, ociDescriptor{Digest: "sha256:../../etc/passwd"}); err == nil {
+		t.Fatal("expected an error for an invalid digest")
+	}
+}
+
+func TestOCISaveLoad(t *testing.T) {
+	e := newTestExporter(t)
+	defer e.cleanup()
+	id := e.createImage(t, "registry.example.com/app:1.0", "base", "app")
+
+	saved := e.save(t, image.ExportOptions{Format: image.ExportFormatOCI, LayerCompression: archive.Gzip}, "registry.example.com/app:1.0")
+
+	dest := newTestExporter(t)
+	defer dest.cleanup()
+	if err := dest.load(saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dest.is.Get(id); err != nil {
+		t.Fatalf("Expected the image to be loaded: %v", err)
+	}
+	named, err := reference.ParseNamed("registry.example.com/app:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loadedID, err := dest.rs.Get(named); err != nil || loadedID != id {
+		t.Fatalf("Expected the tag to reference %s, got %s (%v)", id, loadedID, err)
+	}
+
+	// A layer blob which doesn't match its descriptor is rejected.
+	var index ociIndex
+	if err := json.Unmarshal(readArchiveFile(t, saved, ociIndexFileName), &index); err != nil {
+		t.Fatal(err)
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(readArchiveFile(t, saved, ociBlobName(index.Manifests[0].Digest)), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	layerBlob := ociBlobName(manifest.Layers[1].Digest)
+	tampered := readArchiveFile(t, saved, ociBlobName(manifest.Layers[0].Digest))
+	for _, data := range [][]byte{tampered, append(readArchiveFile(t, saved, layerBlob), 0)} {
+		dest := newTestExporter(t)
+		defer dest.cleanup()
+		if err := dest.load(replaceArchiveFile(t, saved, layerBlob, data)); err == nil || !strings.Contains(err.Error(), "mismatch") {
+			t.Fatalf("Expected a mismatch error loading a tampered layer, got %v", err)
+		}
+	}
+}
+
+func TestOCISaveIndexOrder(t *testing.T) {
+	e := newTestExporter(t)
+	defer e.cleanup()
+	names := []string{"app:1.0", "app:2.0", "db:1.0", "web:1.0"}
+	for _, name := range names {
+		e.createImage(t, name, name)
+	}
+
+	options := image.ExportOptions{Format: image.ExportFormatOCI}
+	expected := readArchiveFile(t, e.save(t, options, names...), ociIndexFileName)
+	for i := 0; i < 5; i++ {
+		if index := readArchiveFile(t, e.save(t, options, names...), ociIndexFileName); string(index) != string(expected) {
+			t.Fatalf("Expected the same index saving the same images, got %s and %s", expected, index)
+		}
+	}
+}
+
+func ociBlobName(dgst digest.Digest) string {
+	return filepath.Join(ociBlobsDirName, string(dgst.Algorithm()), dgst.Hex())
+}
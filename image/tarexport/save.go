@@ -18,8 +18,10 @@ import (
 )
 
 type imageDescriptor struct {
-	refs   []reference.NamedTagged
-	layers []string
+	refs        []reference.NamedTagged
+	layers      []string
+	topLayer    string
+	parentLayer layer.ChainID
 }
 
 type saveSession struct {
@@ -27,15 +29,66 @@ type saveSession struct {
 	outDir      string
 	images      map[image.ID]*imageDescriptor
 	savedLayers map[string]struct{}
+	options     image.ExportOptions
+	baseRootFS  *image.RootFS
 }
 
-func (l *tarexporter) Save(names []string, outStream io.Writer) error {
+func (l *tarexporter) Save(names []string, options image.ExportOptions, outStream io.Writer) error {
 	images, err := l.parseNames(names)
 	if err != nil {
 		return err
 	}
 
-	return (&saveSession{tarexporter: l, images: images}).save(outStream)
+	if err := archive.CheckCompression(options.LayerCompression); err != nil {
+		return err
+	}
+
+	s := &saveSession{tarexporter: l, images: images, options: options}
+	if options.ExcludeBase != "" {
+		if s.baseRootFS, err = l.baseRootFS(options.ExcludeBase); err != nil {
+			return err
+		}
+	}
+	switch options.Format {
+	case "", image.ExportFormatDocker:
+		return s.save(outStream)
+	case image.ExportFormatOCI:
+		if s.baseRootFS != nil {
+			return fmt.Errorf("excluding a base image is not supported with the %s format", image.ExportFormatOCI)
+		}
+		return s.saveOCI(outStream)
+	}
+	return fmt.Errorf("unknown image archive format %q", options.Format)
+}
+
+// baseRootFS returns the root filesystem of the base image name, whose
+// layers are left out of the archive.
+func (l *tarexporter) baseRootFS(name string) (*image.RootFS, error) {
+	images, err := l.parseNames([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(images) != 1 {
+		return nil, fmt.Errorf("base image %s must refer to a single image", name)
+	}
+	for id := range images {
+		img, err := l.is.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		return img.RootFS, nil
+	}
+	return nil, nil
+}
+
+// sharedLayers returns the number of bottom layers rootFS has in common with
+// base.
+func sharedLayers(rootFS, base *image.RootFS) int {
+	n := 0
+	for n < len(rootFS.DiffIDs) && n < len(base.DiffIDs) && rootFS.DiffIDs[n] == base.DiffIDs[n] {
+		n++
+	}
+	return n
 }
 
 func (l *tarexporter) parseNames(names []string) (map[image.ID]*imageDescriptor, error) {
@@ -129,21 +182,27 @@ func (s *saveSession) save(outStream io.Writer) error {
 		var layers []string
 
 		for _, ref := range imageDescr.refs {
-			if _, ok := reposLegacy[ref.Name()]; !ok {
-				reposLegacy[ref.Name()] = make(map[string]string)
+			// The legacy loader reads the top layer directory of each
+			// tag, which is missing when all the layers of the image
+			// are left out.
+			if s.legacyLayout() && len(imageDescr.layers) > 0 {
+				if _, ok := reposLegacy[ref.Name()]; !ok {
+					reposLegacy[ref.Name()] = make(map[string]string)
+				}
+				reposLegacy[ref.Name()][ref.Tag()] = imageDescr.topLayer
 			}
-			reposLegacy[ref.Name()][ref.Tag()] = imageDescr.layers[len(imageDescr.layers)-1]
 			repoTags = append(repoTags, ref.String())
 		}
 
 		for _, l := range imageDescr.layers {
-			layers = append(layers, filepath.Join(l, legacyLayerFileName))
+			layers = append(layers, s.layerPath(l))
 		}
 
 		manifest = append(manifest, manifestItem{
-			Config:   digest.Digest(id).Hex() + ".json",
-			RepoTags: repoTags,
-			Layers:   layers,
+			Config:      digest.Digest(id).Hex() + ".json",
+			RepoTags:    repoTags,
+			Layers:      layers,
+			ParentLayer: imageDescr.parentLayer,
 		})
 	}
 
@@ -203,7 +262,14 @@ func (s *saveSession) saveImage(id image.ID) error {
 		return fmt.Errorf("empty export - not implemented")
 	}
 
+	// layers shared with the base image are left out of the archive
+	var shared int
+	if s.baseRootFS != nil {
+		shared = sharedLayers(img.RootFS, s.baseRootFS)
+	}
+
 	var parent digest.Digest
+	var parentLayer layer.ChainID
 	var layers []string
 	for i := range img.RootFS.DiffIDs {
 		v1Img := image.V1Image{}
@@ -222,10 +288,14 @@ func (s *saveSession) saveImage(id image.ID) error {
 			v1Img.Parent = parent.Hex()
 		}
 
-		if err := s.saveLayer(rootFS.ChainID(), v1Img, img.Created); err != nil {
-			return err
+		if i < shared {
+			parentLayer = rootFS.ChainID()
+		} else {
+			if err := s.saveLayer(rootFS.ChainID(), v1Img, img.Created); err != nil {
+				return err
+			}
+			layers = append(layers, v1Img.ID)
 		}
-		layers = append(layers, v1Img.ID)
 		parent = v1ID
 	}
 
@@ -238,6 +308,8 @@ func (s *saveSession) saveImage(id image.ID) error {
 	}
 
 	s.images[id].layers = layers
+	s.images[id].topLayer = parent.Hex()
+	s.images[id].parentLayer = parentLayer
 	return nil
 }
 
@@ -246,27 +318,32 @@ func (s *saveSession) saveLayer(id layer.ChainID, legacyImg image.V1Image, creat
 		return nil
 	}
 
-	outDir := filepath.Join(s.outDir, legacyImg.ID)
-	if err := os.Mkdir(outDir, 0755); err != nil {
-		return err
-	}
+	var files []string
+	if s.legacyLayout() {
+		outDir := filepath.Join(s.outDir, legacyImg.ID)
+		if err := os.Mkdir(outDir, 0755); err != nil {
+			return err
+		}
 
-	// todo: why is this version file here?
-	if err := ioutil.WriteFile(filepath.Join(outDir, legacyVersionFileName), []byte("1.0"), 0644); err != nil {
-		return err
-	}
+		// todo: why is this version file here?
+		if err := ioutil.WriteFile(filepath.Join(outDir, legacyVersionFileName), []byte("1.0"), 0644); err != nil {
+			return err
+		}
 
-	imageConfig, err := json.Marshal(legacyImg)
-	if err != nil {
-		return err
-	}
+		imageConfig, err := json.Marshal(legacyImg)
+		if err != nil {
+			return err
+		}
 
-	if err := ioutil.WriteFile(filepath.Join(outDir, legacyConfigFileName), imageConfig, 0644); err != nil {
-		return err
+		if err := ioutil.WriteFile(filepath.Join(outDir, legacyConfigFileName), imageConfig, 0644); err != nil {
+			return err
+		}
+		files = append(files, outDir, filepath.Join(outDir, legacyVersionFileName), filepath.Join(outDir, legacyConfigFileName))
 	}
 
 	// serialize filesystem
-	tarFile, err := os.Create(filepath.Join(outDir, legacyLayerFileName))
+	layerPath := filepath.Join(s.outDir, s.layerPath(legacyImg.ID))
+	tarFile, err := os.Create(layerPath)
 	if err != nil {
 		return err
 	}
@@ -284,13 +361,13 @@ func (s *saveSession) saveLayer(id layer.ChainID, legacyImg image.V1Image, creat
 	}
 	defer arch.Close()
 
-	if _, err := io.Copy(tarFile, arch); err != nil {
+	if err := s.writeLayerData(tarFile, arch); err != nil {
 		return err
 	}
 
-	for _, fname := range []string{"", legacyVersionFileName, legacyConfigFileName, legacyLayerFileName} {
+	for _, fname := range append(files, layerPath) {
 		// todo: maybe save layer created timestamp?
-		if err := os.Chtimes(filepath.Join(outDir, fname), createdTime, createdTime); err != nil {
+		if err := os.Chtimes(fname, createdTime, createdTime); err != nil {
 			return err
 		}
 	}
@@ -298,3 +375,40 @@ func (s *saveSession) saveLayer(id layer.ChainID, legacyImg image.V1Image, creat
 	s.savedLayers[legacyImg.ID] = struct{}{}
 	return nil
 }
+
+// legacyLayout returns whether the archive has the legacy layout read by
+// loaders older than the manifest file: a directory per layer, holding the
+// layer as an uncompressed "layer.tar", and a repositories file. Archives
+// with compressed layers only have the manifest file, since older loaders
+// expect the layer tarballs to be uncompressed.
+func (s *saveSession) legacyLayout() bool {
+	return s.options.LayerCompression == archive.Uncompressed
+}
+
+// layerPath returns the path in the archive of the tarball of the layer with
+// the legacy ID id.
+func (s *saveSession) layerPath(id string) string {
+	if s.legacyLayout() {
+		return filepath.Join(id, legacyLayerFileName)
+	}
+	return id + "." + s.options.LayerCompression.Extension()
+}
+
+// writeLayerData copies the layer tar stream to w, compressing it if
+// requested.
+func (s *saveSession) writeLayerData(w io.WriteCloser, arch io.Reader) error {
+	if s.options.LayerCompression == archive.Uncompressed {
+		_, err := io.Copy(w, arch)
+		return err
+	}
+
+	compressed, err := archive.CompressStream(w, s.options.LayerCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(compressed, arch); err != nil {
+		compressed.Close()
+		return err
+	}
+	return compressed.Close()
+}
@@ -0,0 +1,132 @@
+package tarexport
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+)
+
+func TestSharedLayers(t *testing.T) {
+	rootFS := func(diffIDs ...layer.DiffID) *image.RootFS {
+		r := image.NewRootFS()
+		for _, d := range diffIDs {
+			r.Append(d)
+		}
+		return r
+	}
+	a := layer.DiffID("sha256:aaaa")
+	b := layer.DiffID("sha256:bbbb")
+	c := layer.DiffID("sha256:cccc")
+
+	cases := []struct {
+		rootFS, base *image.RootFS
+		expected     int
+	}{
+		{rootFS(a, b, c), rootFS(a, b), 2},
+		{rootFS(a, b), rootFS(a, b), 2},
+		{rootFS(a), rootFS(a, b), 1},
+		{rootFS(a, c), rootFS(a, b), 1},
+		{rootFS(b, c), rootFS(a, b), 0},
+		{rootFS(a, b), rootFS(), 0},
+	}
+	for i, c := range cases {
+		if actual := sharedLayers(c.rootFS, c.base); actual != c.expected {
+			t.Errorf("case %d: expected %d shared layers, got %d", i, c.expected, actual)
+		}
+	}
+}
+
+func TestSaveCompressedLayers(t *testing.T) {
+	e := newTestExporter(t)
+	defer e.cleanup()
+	id := e.createImage(t, "app:1.0", "base", "app")
+
+	// The legacy layout holds the layers as uncompressed layer.tar files.
+	files := archiveFiles(t, e.save(t, image.ExportOptions{}, "app:1.0"))
+	var layerTars int
+	for _, f := range files {
+		if filepath.Base(f) == legacyLayerFileName {
+			layerTars++
+		}
+	}
+	if layerTars != 2 || files[len(files)-1] != legacyRepositoriesFileName {
+		t.Fatalf("Expected the legacy layout, got %v", files)
+	}
+
+	// A compressed archive only has the manifest entries.
+	saved := e.save(t, image.ExportOptions{LayerCompression: archive.Gzip}, "app:1.0")
+	files = archiveFiles(t, saved)
+	if len(files) != 4 {
+		t.Fatalf("Expected a config, two layers and a manifest, got %v", files)
+	}
+	var manifest []manifestItem
+	if err := json.Unmarshal(readArchiveFile(t, saved, manifestFileName), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range manifest[0].Layers {
+		if filepath.Dir(l) != "." || !strings.HasSuffix(l, ".tar.gz") {
+			t.Fatalf("Expected a compressed layer outside the legacy layout, got %s", l)
+		}
+		if c := archive.DetectCompression(readArchiveFile(t, saved, l)); c != archive.Gzip {
+			t.Fatalf("Expected %s to be gzip compressed", l)
+		}
+	}
+
+	dest := newTestExporter(t)
+	defer dest.cleanup()
+	if err := dest.load(saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dest.is.Get(id); err != nil {
+		t.Fatalf("Expected the image to be loaded: %v", err)
+	}
+}
+
+func TestSaveExcludeBase(t *testing.T) {
+	e := newTestExporter(t)
+	defer e.cleanup()
+	baseID := e.createImage(t, "base:1.0", "base")
+	appID := e.createImage(t, "app:1.0", "base", "app")
+	sameID := e.createImage(t, "same:1.0", "base")
+
+	saved := e.save(t, image.ExportOptions{ExcludeBase: "base:1.0"}, "app:1.0", "same:1.0")
+
+	// The image with all its layers left out has no entry in the
+	// repositories file, which would reference a missing layer directory.
+	var repositories map[string]map[string]string
+	if err := json.Unmarshal(readArchiveFile(t, saved, legacyRepositoriesFileName), &repositories); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repositories["same"]; ok || len(repositories["app"]) != 1 {
+		t.Fatalf("Expected only app in the repositories file, got %v", repositories)
+	}
+	for _, f := range archiveFiles(t, saved) {
+		if filepath.Base(f) == legacyLayerFileName && f != filepath.Join(repositories["app"]["1.0"], legacyLayerFileName) {
+			t.Fatalf("Expected only the top layer of app in the archive, got %s", f)
+		}
+	}
+
+	// The layers left out must already be present.
+	dest := newTestExporter(t)
+	defer dest.cleanup()
+	if err := dest.load(saved); err == nil || !strings.Contains(err.Error(), "is not present, load its base image first") {
+		t.Fatalf("Expected an error about the missing parent layer, got %v", err)
+	}
+
+	if err := dest.load(e.save(t, image.ExportOptions{}, "base:1.0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dest.load(saved); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []image.ID{baseID, appID, sameID} {
+		if _, err := dest.is.Get(id); err != nil {
+			t.Fatalf("Expected image %s to be loaded: %v", id, err)
+		}
+	}
+}
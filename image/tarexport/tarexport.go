@@ -18,6 +18,9 @@ type manifestItem struct {
 	Config   string
 	RepoTags []string
 	Layers   []string
+	// ParentLayer is the ChainID of the layers of the image which were
+	// left out of the archive, and must be present on the receiving end.
+	ParentLayer layer.ChainID `json:",omitempty"`
 }
 
 type tarexporter struct {
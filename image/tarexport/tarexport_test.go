@@ -0,0 +1,218 @@
+package tarexport
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/graphdriver/vfs"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/reference"
+)
+
+func init() {
+	reexec.Init()
+}
+
+// testExporter is a tarexporter backed by image, layer and reference stores
+// in a temporary directory.
+type testExporter struct {
+	*tarexporter
+	root string
+}
+
+func newTestExporter(t *testing.T) *testExporter {
+	root, err := ioutil.TempDir("", "tarexport-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uidMaps := []idtools.IDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	gidMaps := []idtools.IDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	driver, err := vfs.Init(filepath.Join(root, "vfs"), nil, uidMaps, gidMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fms, err := layer.NewFSMetadataStore(filepath.Join(root, "layerdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := layer.NewStoreFromGraphDriver(fms, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := image.NewFSStoreBackend(filepath.Join(root, "imagedb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, err := image.NewImageStore(backend, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := reference.NewReferenceStore(filepath.Join(root, "repositories.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testExporter{
+		tarexporter: &tarexporter{is: is, ls: ls, rs: rs},
+		root:        root,
+	}
+}
+
+func (e *testExporter) cleanup() {
+	os.RemoveAll(e.root)
+}
+
+// layerTar returns a tar stream holding a file with the given name and
+// content.
+func layerTar(t *testing.T, name, content string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: time.Unix(0, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// createImage registers a layer for each of files, mapping a file name to
+// its content, and creates an image of these layers tagged as name.
+func (e *testExporter) createImage(t *testing.T, name string, files ...string) image.ID {
+	rootFS := image.NewRootFS()
+	for _, f := range files {
+		l, err := e.ls.Register(bytes.NewReader(layerTar(t, f, f+" content")), rootFS.ChainID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rootFS.Append(l.DiffID())
+		defer layer.ReleaseAndLog(e.ls, l)
+	}
+	config, err := json.Marshal(&image.Image{
+		V1Image: image.V1Image{
+			Architecture: "amd64",
+			OS:           "linux",
+			Created:      time.Date(2016, 4, 1, 10, 0, 0, 0, time.UTC),
+			Comment:      name,
+		},
+		RootFS: rootFS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := e.is.Create(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := reference.ParseNamed(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.rs.AddTag(named, id, true); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// save saves the images names in an archive, and returns it.
+func (e *testExporter) save(t *testing.T, options image.ExportOptions, names ...string) []byte {
+	var buf bytes.Buffer
+	if err := e.Save(names, options, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// load loads the images of an archive.
+func (e *testExporter) load(archive []byte) error {
+	return e.Load(ioutil.NopCloser(bytes.NewReader(archive)), ioutil.Discard)
+}
+
+// archiveFiles returns the sorted names of the regular files of an archive.
+func archiveFiles(t *testing.T, archive []byte) []string {
+	var files []string
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			files = append(files, hdr.Name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// readArchiveFile returns the content of the file name in an archive.
+func readArchiveFile(t *testing.T, archive []byte, name string) []byte {
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			t.Fatalf("%s not found in the archive", name)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == name {
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return data
+		}
+	}
+}
+
+// replaceArchiveFile returns a copy of an archive with the content of the
+// file name replaced by data.
+func replaceArchiveFile(t *testing.T, archive []byte, name string, data []byte) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == name {
+			content = data
+			hdr.Size = int64(len(data))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -2,6 +2,7 @@ package layer
 
 import (
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/ioutils"
 )
 
@@ -226,6 +228,23 @@ func (fms *fileMetadataStore) SetMountParent(mount string, parent ChainID) error
 	return ioutil.WriteFile(fms.getMountFilename(mount, "parent"), []byte(digest.Digest(parent).String()), 0644)
 }
 
+// mountIDMaps are the ID mappings of a mount with ID mappings of its own.
+type mountIDMaps struct {
+	UIDMaps []idtools.IDMap
+	GIDMaps []idtools.IDMap
+}
+
+func (fms *fileMetadataStore) SetMountIDMaps(mount string, uidMaps, gidMaps []idtools.IDMap) error {
+	if err := os.MkdirAll(fms.getMountDirectory(mount), 0755); err != nil {
+		return err
+	}
+	content, err := json.Marshal(mountIDMaps{UIDMaps: uidMaps, GIDMaps: gidMaps})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fms.getMountFilename(mount, "id-maps"), content, 0644)
+}
+
 func (fms *fileMetadataStore) GetMountID(mount string) (string, error) {
 	content, err := ioutil.ReadFile(fms.getMountFilename(mount, "mount-id"))
 	if err != nil {
@@ -272,6 +291,23 @@ func (fms *fileMetadataStore) GetMountParent(mount string) (ChainID, error) {
 	return ChainID(dgst), nil
 }
 
+func (fms *fileMetadataStore) GetMountIDMaps(mount string) ([]idtools.IDMap, []idtools.IDMap, bool, error) {
+	content, err := ioutil.ReadFile(fms.getMountFilename(mount, "id-maps"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, false, nil
+		}
+		return nil, nil, false, err
+	}
+
+	var maps mountIDMaps
+	if err := json.Unmarshal(content, &maps); err != nil {
+		return nil, nil, false, err
+	}
+
+	return maps.UIDMaps, maps.GIDMaps, true, nil
+}
+
 func (fms *fileMetadataStore) List() ([]ChainID, []string, error) {
 	var ids []ChainID
 	for _, algorithm := range supportedAlgorithms {
@@ -16,6 +16,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 )
 
 var (
@@ -168,6 +169,11 @@ type Store interface {
 	Release(Layer) ([]Metadata, error)
 
 	CreateRWLayer(id string, parent ChainID, mountLabel string, initFunc MountInit) (RWLayer, error)
+	// CreateRWLayerWithIDMaps creates a read-write layer whose files are
+	// owned by the host IDs uidMaps and gidMaps map the IDs of the parent
+	// layer to, copying the parent layer if the mappings differ from the
+	// ones of the store.
+	CreateRWLayerWithIDMaps(id string, parent ChainID, mountLabel string, initFunc MountInit, uidMaps, gidMaps []idtools.IDMap) (RWLayer, error)
 	GetRWLayer(id string) (RWLayer, error)
 	ReleaseRWLayer(RWLayer) ([]Metadata, error)
 
@@ -207,10 +213,14 @@ type MetadataStore interface {
 	SetMountID(string, string) error
 	SetInitID(string, string) error
 	SetMountParent(string, ChainID) error
+	SetMountIDMaps(string, []idtools.IDMap, []idtools.IDMap) error
 
 	GetMountID(string) (string, error)
 	GetInitID(string) (string, error)
 	GetMountParent(string) (ChainID, error)
+	// GetMountIDMaps returns the ID mappings of a mount, and whether the
+	// mount has ID mappings of its own.
+	GetMountIDMaps(string) ([]idtools.IDMap, []idtools.IDMap, bool, error)
 
 	// List returns the full list of referenced
 	// read-only and read-write layers
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"sync"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/daemon/graphdriver"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/vbatts/tar-split/tar/asm"
@@ -24,10 +26,18 @@ import (
 // used to create a rwlayer.
 const maxLayerDepth = 125
 
+// untar unpacks the copies of layers made for mounts with their own ID
+// mappings.
+var untar = chrootarchive.Untar
+
 type layerStore struct {
 	store  MetadataStore
 	driver graphdriver.Driver
 
+	// uidMaps and gidMaps are the ID mappings of the files of the layers
+	uidMaps []idtools.IDMap
+	gidMaps []idtools.IDMap
+
 	layerMap map[ChainID]*roLayer
 	layerL   sync.Mutex
 
@@ -63,16 +73,22 @@ func NewStoreFromOptions(options StoreOptions) (Store, error) {
 		return nil, err
 	}
 
-	return NewStoreFromGraphDriver(fms, driver)
+	return newStoreFromGraphDriver(fms, graphdriver.NewMeteredDriver(driver), options.UIDMaps, options.GIDMaps)
 }
 
 // NewStoreFromGraphDriver creates a new Store instance using the provided
 // metadata store and graph driver. The metadata store will be used to restore
 // the Store.
 func NewStoreFromGraphDriver(store MetadataStore, driver graphdriver.Driver) (Store, error) {
+	return newStoreFromGraphDriver(store, driver, nil, nil)
+}
+
+func newStoreFromGraphDriver(store MetadataStore, driver graphdriver.Driver, uidMaps, gidMaps []idtools.IDMap) (Store, error) {
 	ls := &layerStore{
 		store:    store,
 		driver:   driver,
+		uidMaps:  uidMaps,
+		gidMaps:  gidMaps,
 		layerMap: map[ChainID]*roLayer{},
 		mounts:   map[string]*mountedLayer{},
 	}
@@ -169,10 +185,18 @@ func (ls *layerStore) loadMount(mount string) error {
 		return err
 	}
 
+	uidMaps, gidMaps, idMapped, err := ls.store.GetMountIDMaps(mount)
+	if err != nil {
+		return err
+	}
+
 	ml := &mountedLayer{
 		name:       mount,
 		mountID:    mountID,
 		initID:     initID,
+		idMapped:   idMapped,
+		uidMaps:    uidMaps,
+		gidMaps:    gidMaps,
 		layerStore: ls,
 		references: map[RWLayer]*referencedRWLayer{},
 	}
@@ -414,6 +438,10 @@ func (ls *layerStore) Release(l Layer) ([]Metadata, error) {
 }
 
 func (ls *layerStore) CreateRWLayer(name string, parent ChainID, mountLabel string, initFunc MountInit) (RWLayer, error) {
+	return ls.CreateRWLayerWithIDMaps(name, parent, mountLabel, initFunc, ls.uidMaps, ls.gidMaps)
+}
+
+func (ls *layerStore) CreateRWLayerWithIDMaps(name string, parent ChainID, mountLabel string, initFunc MountInit, uidMaps, gidMaps []idtools.IDMap) (RWLayer, error) {
 	ls.mountL.Lock()
 	defer ls.mountL.Unlock()
 	m, ok := ls.mounts[name]
@@ -449,7 +477,16 @@ func (ls *layerStore) CreateRWLayer(name string, parent ChainID, mountLabel stri
 		references: map[RWLayer]*referencedRWLayer{},
 	}
 
-	if initFunc != nil {
+	if !sameIDMaps(uidMaps, ls.uidMaps) || !sameIDMaps(gidMaps, ls.gidMaps) {
+		m.idMapped = true
+		m.uidMaps = uidMaps
+		m.gidMaps = gidMaps
+		pid, err = ls.initIDMappedMount(m.mountID, p, mountLabel, initFunc, uidMaps, gidMaps)
+		if err != nil {
+			return nil, err
+		}
+		m.initID = pid
+	} else if initFunc != nil {
 		pid, err = ls.initMount(m.mountID, pid, mountLabel, initFunc)
 		if err != nil {
 			return nil, err
@@ -461,6 +498,14 @@ func (ls *layerStore) CreateRWLayer(name string, parent ChainID, mountLabel stri
 		return nil, err
 	}
 
+	if m.idMapped {
+		// graph drivers create the root of the layer with their own
+		// mappings rather than copying it from the parent
+		if err = ls.chownMount(m.mountID, uidMaps, gidMaps); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = ls.saveMount(m); err != nil {
 		return nil, err
 	}
@@ -540,6 +585,12 @@ func (ls *layerStore) saveMount(mount *mountedLayer) error {
 		}
 	}
 
+	if mount.idMapped {
+		if err := ls.store.SetMountIDMaps(mount.name, mount.uidMaps, mount.gidMaps); err != nil {
+			return err
+		}
+	}
+
 	ls.mounts[mount.name] = mount
 
 	return nil
@@ -572,12 +623,114 @@ func (ls *layerStore) initMount(graphID, parent, mountLabel string, initFunc Mou
 	return initID, nil
 }
 
+// initIDMappedMount creates the init layer of a mount whose files are owned
+// by other host IDs than the ones of the layers. As the layers cannot be
+// shared with the mount, the init layer has no parent and holds a copy of
+// the parent layer chowned to the ID mappings of the mount.
+func (ls *layerStore) initIDMappedMount(graphID string, parent *roLayer, mountLabel string, initFunc MountInit, uidMaps, gidMaps []idtools.IDMap) (string, error) {
+	initID := fmt.Sprintf("%s-init", graphID)
+
+	if err := ls.driver.Create(initID, "", mountLabel); err != nil {
+		return "", err
+	}
+	p, err := ls.driver.Get(initID, "")
+	if err != nil {
+		return "", err
+	}
+
+	if err := ls.copyLayer(parent, p, uidMaps, gidMaps); err != nil {
+		ls.driver.Put(initID)
+		return "", err
+	}
+
+	if initFunc != nil {
+		if err := initFunc(p); err != nil {
+			ls.driver.Put(initID)
+			return "", err
+		}
+	}
+
+	if err := ls.driver.Put(initID); err != nil {
+		return "", err
+	}
+
+	return initID, nil
+}
+
+// copyLayer copies the content of the layer l and its parents to dest,
+// translating the owners of the files from the ID mappings of the layers
+// to uidMaps and gidMaps.
+func (ls *layerStore) copyLayer(l *roLayer, dest string, uidMaps, gidMaps []idtools.IDMap) error {
+	if err := chownRoot(dest, uidMaps, gidMaps); err != nil {
+		return err
+	}
+	if l == nil {
+		return nil
+	}
+
+	src, err := ls.driver.Get(l.cacheID, "")
+	if err != nil {
+		return err
+	}
+	defer ls.driver.Put(l.cacheID)
+
+	rc, err := archive.TarWithOptions(src, &archive.TarOptions{
+		Compression: archive.Uncompressed,
+		UIDMaps:     ls.uidMaps,
+		GIDMaps:     ls.gidMaps,
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return untar(rc, dest, &archive.TarOptions{
+		UIDMaps: uidMaps,
+		GIDMaps: gidMaps,
+	})
+}
+
+// chownMount chowns the root of the layer graphID to the root of uidMaps
+// and gidMaps.
+func (ls *layerStore) chownMount(graphID string, uidMaps, gidMaps []idtools.IDMap) error {
+	p, err := ls.driver.Get(graphID, "")
+	if err != nil {
+		return err
+	}
+	if err := chownRoot(p, uidMaps, gidMaps); err != nil {
+		ls.driver.Put(graphID)
+		return err
+	}
+	return ls.driver.Put(graphID)
+}
+
+func chownRoot(path string, uidMaps, gidMaps []idtools.IDMap) error {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return err
+	}
+	return os.Chown(path, rootUID, rootGID)
+}
+
+// sameIDMaps returns whether the ID mappings a and b map the same IDs.
+func sameIDMaps(a, b []idtools.IDMap) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (ls *layerStore) assembleTarTo(graphID string, metadata io.ReadCloser, size *int64, w io.Writer) error {
 	type diffPathDriver interface {
 		DiffPath(string) (string, func() error, error)
 	}
 
-	diffDriver, ok := ls.driver.(diffPathDriver)
+	diffDriver, ok := graphdriver.Unwrap(ls.driver).(diffPathDriver)
 	if !ok {
 		diffDriver = &naiveDiffPathDriver{ls.driver}
 	}
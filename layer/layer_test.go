@@ -19,6 +19,7 @@ import (
 func init() {
 	graphdriver.ApplyUncompressedLayer = archive.UnpackLayer
 	vfs.CopyWithTar = archive.CopyWithTar
+	untar = archive.Untar
 }
 
 func newVFSGraphDriver(td string) (graphdriver.Driver, error) {
@@ -94,5 +94,5 @@ func (ls *layerStore) mountID(name string) string {
 }
 
 func (ls *layerStore) GraphDriver() graphdriver.Driver {
-	return ls.driver
+	return graphdriver.Unwrap(ls.driver)
 }
@@ -1,13 +1,17 @@
 package layer
 
 import (
+	"archive/tar"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
+	"syscall"
 	"testing"
 
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 )
 
 func TestMountInit(t *testing.T) {
@@ -184,6 +188,81 @@ func TestMountChanges(t *testing.T) {
 	})
 }
 
+func TestMountIDMaps(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("Chowning the copy of the layer requires root")
+	}
+	ls, cleanup := newTestStore(t)
+	defer cleanup()
+
+	li := initWithFiles(newTestFile("file1", []byte("base data!"), 0644))
+	layer, err := createLayer(ls, "", li)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idMaps := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	m, err := ls.CreateRWLayerWithIDMaps("mount-id-maps", layer.ChainID(), "", nil, idMaps, idMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := m.Mount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{path, filepath.Join(path, "file1")} {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st := fi.Sys().(*syscall.Stat_t); st.Uid != 100000 || st.Gid != 100000 {
+			t.Fatalf("Expected %s to be owned by 100000:100000, got %d:%d", p, st.Uid, st.Gid)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(path, "file2"), []byte("mount data!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(filepath.Join(path, "file2"), 100005, 100005); err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := m.TarStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	tr := tar.NewReader(ts)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "file2" && (hdr.Uid != 5 || hdr.Gid != 5) {
+			t.Fatalf("Expected file2 to be owned by 5:5 in the tar stream, got %d:%d", hdr.Uid, hdr.Gid)
+		}
+	}
+	if len(names) != 1 || names[0] != "file2" {
+		t.Fatalf("Expected only file2 in the tar stream, got %v", names)
+	}
+
+	ls2, err := NewStoreFromGraphDriver(ls.(*layerStore).store, ls.(*layerStore).driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ml := ls2.(*layerStore).mounts["mount-id-maps"]; ml == nil || !ml.idMapped || !sameIDMaps(ml.uidMaps, idMaps) {
+		t.Fatalf("Expected the ID mappings of the mount to be restored, got %v", ml)
+	}
+}
+
 func assertChange(t *testing.T, actual, expected archive.Change) {
 	if actual.Path != expected.Path {
 		t.Fatalf("Unexpected change path %s, expected %s", actual.Path, expected.Path)
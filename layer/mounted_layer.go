@@ -5,6 +5,8 @@ import (
 	"sync"
 
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/ioutils"
 )
 
 type mountedLayer struct {
@@ -14,6 +16,12 @@ type mountedLayer struct {
 	parent     *roLayer
 	layerStore *layerStore
 
+	// idMapped is set when the files of the mount are owned by the
+	// uidMaps and gidMaps mappings rather than the ones of the store
+	idMapped bool
+	uidMaps  []idtools.IDMap
+	gidMaps  []idtools.IDMap
+
 	references map[RWLayer]*referencedRWLayer
 }
 
@@ -28,6 +36,9 @@ func (ml *mountedLayer) cacheParent() string {
 }
 
 func (ml *mountedLayer) TarStream() (io.ReadCloser, error) {
+	if ml.idMapped {
+		return ml.idMappedTarStream()
+	}
 	archiver, err := ml.layerStore.driver.Diff(ml.mountID, ml.cacheParent())
 	if err != nil {
 		return nil, err
@@ -35,6 +46,31 @@ func (ml *mountedLayer) TarStream() (io.ReadCloser, error) {
 	return archiver, nil
 }
 
+// idMappedTarStream returns the changes of a mount with ID mappings of its
+// own. The graph driver diffs with the mappings of the store, so the changes
+// are exported here with the mappings of the mount.
+func (ml *mountedLayer) idMappedTarStream() (io.ReadCloser, error) {
+	driver := ml.layerStore.driver
+	changes, err := driver.Changes(ml.mountID, ml.initID)
+	if err != nil {
+		return nil, err
+	}
+	path, err := driver.Get(ml.mountID, "")
+	if err != nil {
+		return nil, err
+	}
+	archiver, err := archive.ExportChanges(path, changes, ml.uidMaps, ml.gidMaps)
+	if err != nil {
+		driver.Put(ml.mountID)
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(archiver, func() error {
+		err := archiver.Close()
+		driver.Put(ml.mountID)
+		return err
+	}), nil
+}
+
 func (ml *mountedLayer) Name() string {
 	return ml.name
 }
@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/Sirupsen/logrus"
@@ -75,6 +76,13 @@ var (
 	// ErrNotImplemented is the error message of function not implemented.
 	ErrNotImplemented = errors.New("Function not implemented")
 	defaultArchiver   = &Archiver{Untar: Untar, UIDMaps: nil, GIDMaps: nil}
+
+	// unpigzPath is the path of the unpigz binary used to decompress gzip
+	// streams on multiple cores. It is empty if unpigz is not installed or
+	// its use was disabled through the DOCKER_DISABLE_PIGZ environment
+	// variable, in which case compress/gzip is used.
+	unpigzPath     string
+	unpigzPathOnce sync.Once
 )
 
 const (
@@ -91,6 +99,8 @@ const (
 	Gzip
 	// Xz is xz compression algorithm.
 	Xz
+	// Zstd is zstd compression algorithm.
+	Zstd
 )
 
 // IsArchive checks for the magic bytes of a tar or any supported compression
@@ -128,6 +138,7 @@ func DetectCompression(source []byte) Compression {
 		Bzip2: {0x42, 0x5A, 0x68},
 		Gzip:  {0x1F, 0x8B, 0x08},
 		Xz:    {0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00},
+		Zstd:  {0x28, 0xB5, 0x2F, 0xFD},
 	} {
 		if len(source) < len(m) {
 			logrus.Debugf("Len too short")
@@ -146,7 +157,70 @@ func xzDecompress(archive io.Reader) (io.ReadCloser, <-chan struct{}, error) {
 	return cmdStream(exec.Command(args[0], args[1:]...), archive)
 }
 
+// lookupZstd returns the path of the zstd binary, which compresses and
+// decompresses zstd streams.
+func lookupZstd() (string, error) {
+	path, err := exec.LookPath("zstd")
+	if err != nil {
+		return "", errors.New("zstd compression requires the zstd binary, which was not found in PATH")
+	}
+	return path, nil
+}
+
+func zstdDecompress(archive io.Reader) (io.ReadCloser, <-chan struct{}, error) {
+	path, err := lookupZstd()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cmdStream(exec.Command(path, "-d", "-c", "-q"), archive)
+}
+
+func lookupUnpigz() string {
+	unpigzPathOnce.Do(func() {
+		if os.Getenv("DOCKER_DISABLE_PIGZ") != "" {
+			logrus.Debug("unpigz disabled, using compress/gzip for decompression")
+			return
+		}
+		path, err := exec.LookPath("unpigz")
+		if err != nil {
+			logrus.Debugf("unpigz binary not found in PATH, using compress/gzip for decompression: %v", err)
+			return
+		}
+		unpigzPath = path
+	})
+	return unpigzPath
+}
+
+func gzipDecompress(archive io.Reader) (io.ReadCloser, <-chan struct{}, error) {
+	if path := lookupUnpigz(); path != "" {
+		return cmdStream(exec.Command(path, "-d", "-c"), archive)
+	}
+
+	gzReader, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+	chdone := make(chan struct{})
+	close(chdone)
+	return gzReader, chdone, nil
+}
+
+// wrapCmdStream returns a ReadCloser for the output of a decompression
+// command. Closing it stops the command if it is still running and waits for
+// it to exit before buf is returned to its pool, since the command may still
+// be reading from it.
+func wrapCmdStream(p *pools.BufioReaderPool, buf *bufio.Reader, output io.ReadCloser, chdone <-chan struct{}) io.ReadCloser {
+	readBufWrapper := p.NewReadCloserWrapper(buf, output)
+	return ioutils.NewReadCloserWrapper(readBufWrapper, func() error {
+		output.Close()
+		<-chdone
+		return readBufWrapper.Close()
+	})
+}
+
 // DecompressStream decompress the archive and returns a ReaderCloser with the decompressed archive.
+// Gzip streams are decompressed with unpigz when it is available.
 func DecompressStream(archive io.Reader) (io.ReadCloser, error) {
 	p := pools.BufioReader32KPool
 	buf := p.Get(archive)
@@ -167,12 +241,11 @@ func DecompressStream(archive io.Reader) (io.ReadCloser, error) {
 		readBufWrapper := p.NewReadCloserWrapper(buf, buf)
 		return readBufWrapper, nil
 	case Gzip:
-		gzReader, err := gzip.NewReader(buf)
+		gzReader, chdone, err := gzipDecompress(buf)
 		if err != nil {
 			return nil, err
 		}
-		readBufWrapper := p.NewReadCloserWrapper(buf, gzReader)
-		return readBufWrapper, nil
+		return wrapCmdStream(p, buf, gzReader, chdone), nil
 	case Bzip2:
 		bz2Reader := bzip2.NewReader(buf)
 		readBufWrapper := p.NewReadCloserWrapper(buf, bz2Reader)
@@ -182,11 +255,13 @@ func DecompressStream(archive io.Reader) (io.ReadCloser, error) {
 		if err != nil {
 			return nil, err
 		}
-		readBufWrapper := p.NewReadCloserWrapper(buf, xzReader)
-		return ioutils.NewReadCloserWrapper(readBufWrapper, func() error {
-			<-chdone
-			return readBufWrapper.Close()
-		}), nil
+		return wrapCmdStream(p, buf, xzReader, chdone), nil
+	case Zstd:
+		zstdReader, chdone, err := zstdDecompress(buf)
+		if err != nil {
+			return nil, err
+		}
+		return wrapCmdStream(p, buf, zstdReader, chdone), nil
 	default:
 		return nil, fmt.Errorf("Unsupported compression format %s", (&compression).Extension())
 	}
@@ -204,15 +279,39 @@ func CompressStream(dest io.WriteCloser, compression Compression) (io.WriteClose
 		gzWriter := gzip.NewWriter(dest)
 		writeBufWrapper := p.NewWriteCloserWrapper(buf, gzWriter)
 		return writeBufWrapper, nil
+	case Zstd:
+		// The zstd process does its own buffering, and its exit status
+		// must be reported when the stream is closed.
+		p.Put(buf)
+		path, err := lookupZstd()
+		if err != nil {
+			return nil, err
+		}
+		return cmdWriteStream(exec.Command(path, "-c", "-q"), dest)
 	case Bzip2, Xz:
 		// archive/bzip2 does not support writing, and there is no xz support at all
-		// However, this is not a problem as docker only currently generates gzipped tars
+		// However, this is not a problem as docker only currently generates gzipped
+		// and zstd compressed tars
 		return nil, fmt.Errorf("Unsupported compression format %s", (&compression).Extension())
 	default:
 		return nil, fmt.Errorf("Unsupported compression format %s", (&compression).Extension())
 	}
 }
 
+// CheckCompression returns an error if CompressStream cannot compress with
+// the given compression algorithm on this host, for instance because the
+// zstd binary is not installed.
+func CheckCompression(compression Compression) error {
+	switch compression {
+	case Uncompressed, Gzip:
+		return nil
+	case Zstd:
+		_, err := lookupZstd()
+		return err
+	}
+	return fmt.Errorf("Unsupported compression format %s", (&compression).Extension())
+}
+
 // Extension returns the extension of a file that uses the specified compression algorithm.
 func (compression *Compression) Extension() string {
 	switch *compression {
@@ -224,10 +323,30 @@ func (compression *Compression) Extension() string {
 		return "tar.gz"
 	case Xz:
 		return "tar.xz"
+	case Zstd:
+		return "tar.zst"
 	}
 	return ""
 }
 
+// ParseCompression returns the compression algorithm with the given name.
+// An empty name or "none" selects Uncompressed.
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return Uncompressed, nil
+	case "bzip2":
+		return Bzip2, nil
+	case "gzip":
+		return Gzip, nil
+	case "xz":
+		return Xz, nil
+	case "zstd":
+		return Zstd, nil
+	}
+	return Uncompressed, fmt.Errorf("Unknown compression format %s", name)
+}
+
 type tarAppender struct {
 	TarWriter *tar.Writer
 	Buffer    *bufio.Writer
@@ -995,6 +1114,32 @@ func cmdStream(cmd *exec.Cmd, input io.Reader) (io.ReadCloser, <-chan struct{},
 	return pipeR, chdone, nil
 }
 
+// cmdWriteStream executes a command that writes its stdout to dest, and
+// returns a WriteCloser feeding its stdin. Closing the WriteCloser waits for
+// the command to finish, and returns an error including anything written on
+// stderr if it didn't complete successfully.
+func cmdWriteStream(cmd *exec.Cmd, dest io.Writer) (io.WriteCloser, error) {
+	cmd.Stdout = dest
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return ioutils.NewWriteCloserWrapper(stdin, func() error {
+		stdin.Close()
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("%s: %s", err, errBuf.String())
+		}
+		return nil
+	}), nil
+}
+
 // NewTempArchive reads the content of src into a temporary file, and returns the contents
 // of that file as an archive. The archive can only be read once - as soon as reading completes,
 // the file will be deleted.
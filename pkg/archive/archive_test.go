@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/system"
 )
 
@@ -101,6 +102,75 @@ func TestDecompressStreamGzip(t *testing.T) {
 	}
 }
 
+// useUnpigz makes DecompressStream use the given unpigz binary, or
+// compress/gzip if path is empty.
+func useUnpigz(path string) {
+	unpigzPathOnce.Do(func() {})
+	unpigzPath = path
+}
+
+func gzipTestData(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	w, err := CompressStream(ioutils.NopWriteCloser(&compressed), Gzip)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+func TestDecompressStreamGzipUnpigz(t *testing.T) {
+	path, err := exec.LookPath("unpigz")
+	if err != nil {
+		t.Skip("unpigz not installed")
+	}
+	defer useUnpigz(lookupUnpigz())
+	useUnpigz(path)
+
+	data := bytes.Repeat([]byte("docker"), 1<<20)
+	compressed, err := gzipTestData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := DecompressStream(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if !bytes.Equal(out, data) {
+		t.Fatal("unpigz output differs from the original data")
+	}
+
+	// Closing before reading the whole stream must not block on the
+	// still running unpigz process.
+	r, err = DecompressStream(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- r.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("closing a partially read unpigz stream timed out")
+	}
+}
+
 func TestDecompressStreamBzip2(t *testing.T) {
 	cmd := exec.Command("/bin/sh", "-c", "touch /tmp/archive && bzip2 -f /tmp/archive")
 	output, err := cmd.CombinedOutput()
@@ -127,6 +197,115 @@ func TestDecompressStreamXz(t *testing.T) {
 	}
 }
 
+func TestDecompressStreamZstd(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd not installed")
+	}
+	tmp, err := ioutil.TempDir("", "docker-archive-zstd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	archivePath := filepath.Join(tmp, "archive")
+	cmd := exec.Command("/bin/sh", "-c", "touch "+archivePath+" && zstd -q -f "+archivePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Fail to create an archive file for test : %s.", output)
+	}
+	archive, err := os.Open(archivePath + ".zst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	r, err := DecompressStream(archive)
+	if err != nil {
+		t.Fatalf("Failed to decompress a zstd file.")
+	}
+	r.Close()
+}
+
+func TestCompressStreamZstd(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd not installed")
+	}
+	data := bytes.Repeat([]byte("docker"), 1024)
+
+	var compressed bytes.Buffer
+	w, err := CompressStream(ioutils.NopWriteCloser(&compressed), Zstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c := DetectCompression(compressed.Bytes()); c != Zstd {
+		t.Fatalf("Expected zstd compressed data, got %s", (&c).Extension())
+	}
+
+	r, err := DecompressStream(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Fatal("Decompressed zstd data differs from the original data")
+	}
+}
+
+func TestCheckCompression(t *testing.T) {
+	for _, compression := range []Compression{Uncompressed, Gzip} {
+		if err := CheckCompression(compression); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", (&compression).Extension(), err)
+		}
+	}
+	if err := CheckCompression(Xz); err == nil {
+		t.Fatal("Expected an error for xz compression")
+	}
+
+	// Without zstd in the PATH, both compressing and decompressing fail
+	// with an explicit error.
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", "")
+	if err := CheckCompression(Zstd); err == nil || !strings.Contains(err.Error(), "zstd binary") {
+		t.Fatalf("Expected an error about the missing zstd binary, got %v", err)
+	}
+	if _, err := CompressStream(ioutils.NopWriteCloser(ioutil.Discard), Zstd); err == nil || !strings.Contains(err.Error(), "zstd binary") {
+		t.Fatalf("Expected an error about the missing zstd binary, got %v", err)
+	}
+	if _, err := DecompressStream(bytes.NewReader([]byte{0x28, 0xB5, 0x2F, 0xFD, 0, 0, 0, 0, 0, 0})); err == nil || !strings.Contains(err.Error(), "zstd binary") {
+		t.Fatalf("Expected an error about the missing zstd binary, got %v", err)
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for name, expected := range map[string]Compression{
+		"":     Uncompressed,
+		"none": Uncompressed,
+		"gzip": Gzip,
+		"zstd": Zstd,
+		"ZSTD": Zstd,
+	} {
+		c, err := ParseCompression(name)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", name, err)
+		}
+		if c != expected {
+			t.Fatalf("Expected %q to be parsed as %s, got %s", name, (&expected).Extension(), (&c).Extension())
+		}
+	}
+	if _, err := ParseCompression("lz4"); err == nil {
+		t.Fatal("Expected an error for an unknown compression format")
+	}
+}
+
 func TestCompressStreamXzUnsuported(t *testing.T) {
 	dest, err := os.Create("/tmp/dest")
 	if err != nil {
@@ -1006,6 +1185,45 @@ func BenchmarkTarUntarWithLinks(b *testing.B) {
 	}
 }
 
+func benchmarkDecompressStream(b *testing.B, unpigz string) {
+	defer useUnpigz(lookupUnpigz())
+	useUnpigz(unpigz)
+
+	data := make([]byte, 32<<20)
+	for i := range data {
+		data[i] = byte(i % 251 * i % 13)
+	}
+	compressed, err := gzipTestData(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.SetBytes(int64(len(data)))
+	for n := 0; n < b.N; n++ {
+		r, err := DecompressStream(bytes.NewReader(compressed))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(ioutil.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+		r.Close()
+	}
+}
+
+func BenchmarkDecompressStreamGzip(b *testing.B) {
+	benchmarkDecompressStream(b, "")
+}
+
+func BenchmarkDecompressStreamUnpigz(b *testing.B) {
+	path, err := exec.LookPath("unpigz")
+	if err != nil {
+		b.Skip("unpigz not installed")
+	}
+	benchmarkDecompressStream(b, path)
+}
+
 func TestUntarInvalidFilenames(t *testing.T) {
 	for i, headers := range [][]*tar.Header{
 		{
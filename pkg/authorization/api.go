@@ -19,6 +19,13 @@ type Request struct {
 	// UserAuthNMethod holds the mechanism used to extract user details (e.g., krb)
 	UserAuthNMethod string `json:"UserAuthNMethod,omitempty"`
 
+	// UserGroups holds the groups of the user, the organizations of its TLS
+	// client certificate when the user is authenticated with TLS
+	UserGroups []string `json:"UserGroups,omitempty"`
+
+	// UserCertificate holds the verified TLS client certificate of the user
+	UserCertificate *Certificate `json:"UserCertificate,omitempty"`
+
 	// RequestMethod holds the HTTP method (GET/POST/PUT)
 	RequestMethod string `json:"RequestMethod,omitempty"`
 
@@ -51,4 +58,43 @@ type Response struct {
 
 	// Err stores a message in case there's an error
 	Err string `json:"Err,omitempty"`
+
+	// ModifiedBody replaces the body of the daemon response when set in
+	// response to AuthZApiResponse
+	ModifiedBody []byte `json:"ModifiedBody,omitempty"`
+
+	// ModifiedHeader replaces the headers of the daemon response when set in
+	// response to AuthZApiResponse, it holds a JSON map[string][]string
+	ModifiedHeader []byte `json:"ModifiedHeader,omitempty"`
+
+	// ModifiedStatusCode replaces the status code of the daemon response when
+	// set in response to AuthZApiResponse
+	ModifiedStatusCode int `json:"ModifiedStatusCode,omitempty"`
+}
+
+// Certificate holds the identity of a verified TLS client certificate
+type Certificate struct {
+	// Subject holds the distinguished name of the subject (e.g., CN=alice,O=dev)
+	Subject string `json:"Subject,omitempty"`
+
+	// CommonName holds the common name of the subject
+	CommonName string `json:"CommonName,omitempty"`
+
+	// Organization holds the organizations of the subject
+	Organization []string `json:"Organization,omitempty"`
+
+	// OrganizationalUnit holds the organizational units of the subject
+	OrganizationalUnit []string `json:"OrganizationalUnit,omitempty"`
+
+	// Issuer holds the distinguished name of the issuer
+	Issuer string `json:"Issuer,omitempty"`
+
+	// SerialNumber holds the serial number of the certificate in decimal
+	SerialNumber string `json:"SerialNumber,omitempty"`
+
+	// DNSNames, EmailAddresses and IPAddresses hold the subject alternative
+	// names of the certificate
+	DNSNames       []string `json:"DNSNames,omitempty"`
+	EmailAddresses []string `json:"EmailAddresses,omitempty"`
+	IPAddresses    []string `json:"IPAddresses,omitempty"`
 }
@@ -44,10 +44,18 @@ type Ctx struct {
 	requestMethod   string
 	requestURI      string
 	plugins         []Plugin
+	// userCertificate stores the verified TLS client certificate of the user
+	userCertificate *Certificate
 	// authReq stores the cached request object for the current transaction
 	authReq *Request
 }
 
+// SetUserCertificate sets the verified TLS client certificate of the user,
+// whose organizations are passed to the plugins as the groups of the user
+func (ctx *Ctx) SetUserCertificate(cert *Certificate) {
+	ctx.userCertificate = cert
+}
+
 // AuthZRequest authorized the request to the docker daemon using authZ plugins
 func (ctx *Ctx) AuthZRequest(w http.ResponseWriter, r *http.Request) error {
 	var body []byte
@@ -80,6 +88,10 @@ func (ctx *Ctx) AuthZRequest(w http.ResponseWriter, r *http.Request) error {
 		RequestBody:     body,
 		RequestHeaders:  headers(r.Header),
 	}
+	if ctx.userCertificate != nil {
+		ctx.authReq.UserCertificate = ctx.userCertificate
+		ctx.authReq.UserGroups = ctx.userCertificate.Organization
+	}
 
 	for _, plugin := range ctx.plugins {
 		logrus.Debugf("AuthZ request using plugin %s", plugin.Name())
@@ -117,6 +129,10 @@ func (ctx *Ctx) AuthZResponse(rm ResponseModifier, r *http.Request) error {
 		if !authRes.Allow {
 			return fmt.Errorf("authorization denied by plugin %s: %s", plugin.Name(), authRes.Msg)
 		}
+
+		if err := ctx.modifyResponse(rm, authRes); err != nil {
+			return fmt.Errorf("plugin %s returned an invalid response modification: %s", plugin.Name(), err)
+		}
 	}
 
 	rm.Flush()
@@ -124,6 +140,28 @@ func (ctx *Ctx) AuthZResponse(rm ResponseModifier, r *http.Request) error {
 	return nil
 }
 
+// modifyResponse applies the modifications of the daemon response returned by
+// a plugin, and passes the modified response to the next plugins
+func (ctx *Ctx) modifyResponse(rm ResponseModifier, authRes *Response) error {
+	if authRes.ModifiedHeader != nil {
+		if err := rm.OverrideHeader(authRes.ModifiedHeader); err != nil {
+			return err
+		}
+		ctx.authReq.ResponseHeaders = headers(rm.Header())
+	}
+	if authRes.ModifiedBody != nil {
+		rm.OverrideBody(authRes.ModifiedBody)
+		// the length of the original body no longer applies
+		rm.Header().Del("Content-Length")
+		ctx.authReq.ResponseBody = authRes.ModifiedBody
+	}
+	if authRes.ModifiedStatusCode > 0 {
+		rm.OverrideStatusCode(authRes.ModifiedStatusCode)
+		ctx.authReq.ResponseStatusCode = authRes.ModifiedStatusCode
+	}
+	return nil
+}
+
 // drainBody dump the body, it reads the body data into memory and
 // see go sources /go/src/net/http/httputil/dump.go
 func drainBody(b io.ReadCloser) (io.ReadCloser, io.ReadCloser, error) {
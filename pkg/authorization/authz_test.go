@@ -1,9 +1,13 @@
 package authorization
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -111,6 +115,138 @@ func TestAuthZResponsePlugin(t *testing.T) {
 	}
 }
 
+func TestNewCertificate(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "alice",
+			Organization:       []string{"dev", "ops"},
+			OrganizationalUnit: []string{"platform, infra"},
+		},
+		Issuer:         pkix.Name{CommonName: "ca", Country: []string{"US"}},
+		SerialNumber:   big.NewInt(42),
+		DNSNames:       []string{"alice.example.com"},
+		EmailAddresses: []string{"alice@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	}
+
+	if c := NewCertificate(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}); c != nil {
+		t.Fatalf("Expected no identity for a certificate which was not verified, got %v", c)
+	}
+
+	c := NewCertificate(&tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	})
+	expected := &Certificate{
+		Subject:            `CN=alice,OU=platform\, infra,O=dev,O=ops`,
+		CommonName:         "alice",
+		Organization:       []string{"dev", "ops"},
+		OrganizationalUnit: []string{"platform, infra"},
+		Issuer:             "CN=ca,C=US",
+		SerialNumber:       "42",
+		DNSNames:           []string{"alice.example.com"},
+		EmailAddresses:     []string{"alice@example.com"},
+		IPAddresses:        []string{"10.0.0.1"},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, c)
+	}
+}
+
+func TestAuthZRequestUserCertificate(t *testing.T) {
+	server := authZPluginTestServer{t: t}
+	go server.start()
+	defer server.stop()
+
+	server.replayResponse = Response{Allow: true}
+	cert := &Certificate{CommonName: "alice", Organization: []string{"dev"}}
+	ctx := NewCtx([]Plugin{createTestPlugin(t)}, "alice", TLSAuthNMethod, "GET", "/containers/json")
+	ctx.SetUserCertificate(cert)
+
+	r, err := http.NewRequest("GET", "/containers/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.AuthZRequest(httptest.NewRecorder(), r); err != nil {
+		t.Fatalf("Failed to authorize request %v", err)
+	}
+	if server.recordedRequest.User != "alice" || server.recordedRequest.UserAuthNMethod != TLSAuthNMethod {
+		t.Fatalf("Expected the user of the certificate, got %q (%q)", server.recordedRequest.User, server.recordedRequest.UserAuthNMethod)
+	}
+	if !reflect.DeepEqual(server.recordedRequest.UserCertificate, cert) {
+		t.Fatalf("Expected the user certificate, got %+v", server.recordedRequest.UserCertificate)
+	}
+	if !reflect.DeepEqual(server.recordedRequest.UserGroups, []string{"dev"}) {
+		t.Fatalf("Expected the organizations as groups, got %v", server.recordedRequest.UserGroups)
+	}
+}
+
+func TestAuthZResponseModified(t *testing.T) {
+	server := authZPluginTestServer{t: t}
+	go server.start()
+	defer server.stop()
+
+	type container struct {
+		ID     string
+		Labels map[string]string
+	}
+	// the plugin only lets the users see their own containers
+	server.replayFunc = func(req Request) Response {
+		if req.ResponseStatusCode == 0 {
+			// the request is authorized before the daemon responds
+			return Response{Allow: true}
+		}
+		var containers, owned []container
+		if err := json.Unmarshal(req.ResponseBody, &containers); err != nil {
+			return Response{Err: err.Error()}
+		}
+		for _, c := range containers {
+			if c.Labels["owner"] == req.User {
+				owned = append(owned, c)
+			}
+		}
+		b, err := json.Marshal(owned)
+		if err != nil {
+			return Response{Err: err.Error()}
+		}
+		return Response{Allow: true, ModifiedBody: b}
+	}
+
+	ctx := NewCtx([]Plugin{createTestPlugin(t)}, "alice", TLSAuthNMethod, "GET", "/containers/json")
+	r, err := http.NewRequest("GET", "/containers/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.AuthZRequest(httptest.NewRecorder(), r); err != nil {
+		t.Fatalf("Failed to authorize request %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	rm := NewResponseModifier(rec)
+	rm.Header().Set("Content-Type", "application/json")
+	rm.Header().Set("Content-Length", "1024")
+	rm.WriteHeader(http.StatusOK)
+	json.NewEncoder(rm).Encode([]container{
+		{ID: "1", Labels: map[string]string{"owner": "alice"}},
+		{ID: "2", Labels: map[string]string{"owner": "bob"}},
+		{ID: "3"},
+	})
+	if err := ctx.AuthZResponse(rm, r); err != nil {
+		t.Fatalf("Failed to authorize response %v", err)
+	}
+
+	var containers []container
+	if err := json.Unmarshal(rec.Body.Bytes(), &containers); err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 1 || containers[0].ID != "1" {
+		t.Fatalf("Expected the response to be filtered to the containers of the user, got %v", containers)
+	}
+	if rec.Header().Get("Content-Length") != "" {
+		t.Fatalf("Expected the length of the original body to be removed, got %q", rec.Header().Get("Content-Length"))
+	}
+}
+
 func TestResponseModifier(t *testing.T) {
 	r := httptest.NewRecorder()
 	m := NewResponseModifier(r)
@@ -183,6 +319,8 @@ type authZPluginTestServer struct {
 	recordedRequest Request
 	// response stores the response sent from the plugin to the daemon
 	replayResponse Response
+	// replayFunc, if set, returns the response to send for the request
+	replayFunc func(Request) Response
 }
 
 // start starts the test server that implements the plugin
@@ -217,6 +355,9 @@ func (t *authZPluginTestServer) auth(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	body, err := ioutil.ReadAll(r.Body)
 	json.Unmarshal(body, &t.recordedRequest)
+	if t.replayFunc != nil {
+		t.replayResponse = t.replayFunc(t.recordedRequest)
+	}
 	b, err := json.Marshal(t.replayResponse)
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,78 @@
+package authorization
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"strings"
+)
+
+// TLSAuthNMethod is the authentication method of the users identified by a
+// verified TLS client certificate
+const TLSAuthNMethod = "TLS"
+
+// NewCertificate returns the identity of the TLS client certificate of a
+// connection, or nil if the client did not present a certificate the daemon
+// verified
+func NewCertificate(state *tls.ConnectionState) *Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := state.VerifiedChains[0][0]
+	c := &Certificate{
+		Subject:            distinguishedName(cert.Subject),
+		CommonName:         cert.Subject.CommonName,
+		Organization:       cert.Subject.Organization,
+		OrganizationalUnit: cert.Subject.OrganizationalUnit,
+		Issuer:             distinguishedName(cert.Issuer),
+		DNSNames:           cert.DNSNames,
+		EmailAddresses:     cert.EmailAddresses,
+	}
+	if cert.SerialNumber != nil {
+		c.SerialNumber = cert.SerialNumber.String()
+	}
+	for _, ip := range cert.IPAddresses {
+		c.IPAddresses = append(c.IPAddresses, ip.String())
+	}
+	return c
+}
+
+// distinguishedName returns the string form of a name, most specific
+// attribute first as in RFC 2253
+func distinguishedName(name pkix.Name) string {
+	var rdns []string
+	add := func(attr string, values ...string) {
+		for _, v := range values {
+			if v != "" {
+				rdns = append(rdns, attr+"="+escapeAttributeValue(v))
+			}
+		}
+	}
+	add("CN", name.CommonName)
+	add("SERIALNUMBER", name.SerialNumber)
+	add("OU", name.OrganizationalUnit...)
+	add("O", name.Organization...)
+	add("STREET", name.StreetAddress...)
+	add("L", name.Locality...)
+	add("ST", name.Province...)
+	add("POSTALCODE", name.PostalCode...)
+	add("C", name.Country...)
+	return strings.Join(rdns, ",")
+}
+
+// escapeAttributeValue escapes the characters RFC 2253 reserves in attribute
+// values
+func escapeAttributeValue(v string) string {
+	var b []byte
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == ',', c == '+', c == '"', c == '\\', c == '<', c == '>', c == ';',
+			c == '#' && i == 0,
+			c == ' ' && (i == 0 || i == len(v)-1):
+			b = append(b, '\\', c)
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,345 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PolicyPluginName is the name of the built-in plugin enforcing a Policy
+const PolicyPluginName = "policy"
+
+// policyUserVariable is replaced by the name of the user in the label values
+// of the rules
+const policyUserVariable = "$USER"
+
+// versionPrefix matches the API version prefix of a request path
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+// Rule allows API requests to a set of routes
+type Rule struct {
+	// Methods holds the HTTP methods the rule allows, all of them if empty
+	Methods []string `json:"methods,omitempty"`
+	// Routes holds the patterns of the request paths the rule allows,
+	// without the API version prefix, as in path.Match (e.g.,
+	// /containers/*/json)
+	Routes []string `json:"routes"`
+	// Labels restricts the rule to the containers with these labels. A value
+	// of $USER stands for the name of the user. The rule then only allows
+	// creating, listing and operating on such containers.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Policy maps users to roles, and roles to the API requests they allow. A
+// request is allowed if a rule of one of the roles of the user allows it.
+type Policy struct {
+	// Users maps user names to their roles
+	Users map[string][]string `json:"users,omitempty"`
+	// Groups maps groups to the roles of their users
+	Groups map[string][]string `json:"groups,omitempty"`
+	// Anonymous holds the roles of the requests without a user, such as the
+	// requests on the unix socket
+	Anonymous []string `json:"anonymous,omitempty"`
+	// Roles maps role names to their rules
+	Roles map[string][]Rule `json:"roles"`
+}
+
+// LoadPolicy reads and validates a JSON policy file
+func LoadPolicy(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p Policy
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid authorization policy %s: %v", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authorization policy %s: %v", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks that the roles of the policy are defined and that their
+// rules are valid
+func (p *Policy) Validate() error {
+	for name, rules := range p.Roles {
+		for i, rule := range rules {
+			if len(rule.Routes) == 0 {
+				return fmt.Errorf("rule %d of role %s has no routes", i, name)
+			}
+			for _, route := range rule.Routes {
+				if _, err := path.Match(route, ""); err != nil {
+					return fmt.Errorf("rule %d of role %s: invalid route %q", i, name, route)
+				}
+			}
+		}
+	}
+	check := func(kind, name string, roles []string) error {
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				return fmt.Errorf("unknown role %s for %s %s", role, kind, name)
+			}
+		}
+		return nil
+	}
+	for user, roles := range p.Users {
+		if err := check("user", user, roles); err != nil {
+			return err
+		}
+	}
+	for group, roles := range p.Groups {
+		if err := check("group", group, roles); err != nil {
+			return err
+		}
+	}
+	return check("anonymous", "users", p.Anonymous)
+}
+
+// roles returns the roles of the user of a request
+func (p *Policy) roles(authReq *Request) []string {
+	if authReq.User == "" {
+		return p.Anonymous
+	}
+	// the roles of the user are copied, appending to them would write to the
+	// policy shared by concurrent requests
+	roles := append([]string(nil), p.Users[authReq.User]...)
+	for _, group := range authReq.UserGroups {
+		roles = append(roles, p.Groups[group]...)
+	}
+	return roles
+}
+
+// matchingRules returns the rules of the roles of the user which allow the
+// method and path of a request, with their role
+func (p *Policy) matchingRules(authReq *Request, method, reqPath string) ([]Rule, []string) {
+	var (
+		rules []Rule
+		names []string
+	)
+	for _, role := range p.roles(authReq) {
+		for _, rule := range p.Roles[role] {
+			if rule.matches(method, reqPath) {
+				rules = append(rules, rule)
+				names = append(names, role)
+			}
+		}
+	}
+	return rules, names
+}
+
+func (r Rule) matches(method, reqPath string) bool {
+	if len(r.Methods) > 0 {
+		allowed := false
+		for _, m := range r.Methods {
+			if strings.EqualFold(m, method) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	for _, route := range r.Routes {
+		if ok, _ := path.Match(route, reqPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// selector returns the labels of the rule with the user name expanded
+func (r Rule) selector(user string) map[string]string {
+	labels := make(map[string]string, len(r.Labels))
+	for k, v := range r.Labels {
+		labels[k] = strings.Replace(v, policyUserVariable, user, -1)
+	}
+	return labels
+}
+
+// PolicyBackend provides the policy plugin with the labels of the containers
+// and records its decisions
+type PolicyBackend interface {
+	// ContainerLabels returns the labels of a container
+	ContainerLabels(name string) (map[string]string, error)
+	// LogAuthZDecision records whether a request is allowed, and why
+	LogAuthZDecision(authReq *Request, allow bool, msg string)
+}
+
+// PolicyPlugin is a built-in Plugin enforcing the Policy of a file
+type PolicyPlugin interface {
+	Plugin
+
+	// Reload reads the policy file again
+	Reload() error
+}
+
+// NewPolicyPlugin returns a plugin enforcing the policy of the file at path
+func NewPolicyPlugin(path string, backend PolicyBackend) (PolicyPlugin, error) {
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	return &policyPlugin{path: path, policy: policy, backend: backend}, nil
+}
+
+type policyPlugin struct {
+	path    string
+	backend PolicyBackend
+
+	mu     sync.RWMutex
+	policy *Policy
+}
+
+func (p *policyPlugin) Name() string {
+	return PolicyPluginName
+}
+
+func (p *policyPlugin) Reload() error {
+	policy, err := LoadPolicy(p.path)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.policy = policy
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *policyPlugin) getPolicy() *Policy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.policy
+}
+
+// AuthZRequest allows the request if a rule of the roles of the user allows
+// its route and, for rules with labels, the container it operates on or
+// creates has the labels. Listing containers is allowed by rules with labels,
+// the response is then filtered.
+func (p *policyPlugin) AuthZRequest(authReq *Request) (*Response, error) {
+	method, reqPath, err := requestPath(authReq)
+	if err != nil {
+		return nil, err
+	}
+	rules, roles := p.getPolicy().matchingRules(authReq, method, reqPath)
+
+	res := &Response{}
+	for i, rule := range rules {
+		if len(rule.Labels) == 0 || p.labelsMatch(authReq, method, reqPath, rule.selector(authReq.User)) {
+			res.Allow = true
+			res.Msg = fmt.Sprintf("allowed by role %s", roles[i])
+			break
+		}
+	}
+	if !res.Allow {
+		res.Msg = fmt.Sprintf("%s %s is not allowed for user %q", method, reqPath, authReq.User)
+	}
+	p.backend.LogAuthZDecision(authReq, res.Allow, res.Msg)
+	return res, nil
+}
+
+// AuthZResponse filters the list of containers to the ones the user can see
+// if the request was only allowed by rules with labels
+func (p *policyPlugin) AuthZResponse(authReq *Request) (*Response, error) {
+	method, reqPath, err := requestPath(authReq)
+	if err != nil {
+		return nil, err
+	}
+	if method != "GET" || reqPath != "/containers/json" || authReq.ResponseStatusCode != http.StatusOK {
+		return &Response{Allow: true}, nil
+	}
+
+	rules, _ := p.getPolicy().matchingRules(authReq, method, reqPath)
+	var selectors []map[string]string
+	for _, rule := range rules {
+		if len(rule.Labels) == 0 {
+			return &Response{Allow: true}, nil
+		}
+		selectors = append(selectors, rule.selector(authReq.User))
+	}
+
+	var containers []map[string]json.RawMessage
+	if err := json.Unmarshal(authReq.ResponseBody, &containers); err != nil {
+		msg := fmt.Sprintf("cannot filter the containers of user %q: %v", authReq.User, err)
+		p.backend.LogAuthZDecision(authReq, false, msg)
+		return &Response{Msg: msg}, nil
+	}
+	filtered := []map[string]json.RawMessage{}
+	for _, c := range containers {
+		var labels map[string]string
+		if raw, ok := c["Labels"]; ok {
+			json.Unmarshal(raw, &labels)
+		}
+		for _, selector := range selectors {
+			if hasLabels(labels, selector) {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	b, err := json.Marshal(filtered)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Allow: true, ModifiedBody: b}, nil
+}
+
+// labelsMatch returns whether the container a request creates or operates on
+// has the labels of the selector. Listing containers matches any selector.
+func (p *policyPlugin) labelsMatch(authReq *Request, method, reqPath string, selector map[string]string) bool {
+	parts := strings.Split(strings.TrimPrefix(reqPath, "/"), "/")
+	if len(parts) < 2 || parts[0] != "containers" {
+		return false
+	}
+	switch {
+	case parts[1] == "json" && len(parts) == 2:
+		return method == "GET"
+	case parts[1] == "create" && len(parts) == 2:
+		var config struct {
+			Labels map[string]string
+		}
+		if err := json.Unmarshal(authReq.RequestBody, &config); err != nil {
+			return false
+		}
+		return hasLabels(config.Labels, selector)
+	default:
+		labels, err := p.backend.ContainerLabels(parts[1])
+		if err != nil {
+			return false
+		}
+		return hasLabels(labels, selector)
+	}
+}
+
+// hasLabels returns whether labels hold all the labels of the selector
+func hasLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// requestPath returns the method and path of a request, without the API
+// version prefix
+func requestPath(authReq *Request) (string, string, error) {
+	u, err := url.ParseRequestURI(authReq.RequestURI)
+	if err != nil {
+		return "", "", err
+	}
+	reqPath := versionPrefix.ReplaceAllString(path.Clean(u.Path), "")
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	return strings.ToUpper(authReq.RequestMethod), reqPath, nil
+}
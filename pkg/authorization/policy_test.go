@@ -0,0 +1,196 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testPolicy = `{
+	"users": {"alice": ["admin"]},
+	"groups": {"dev": ["developer"]},
+	"anonymous": ["viewer"],
+	"roles": {
+		"admin": [{"routes": ["/*", "/*/*", "/*/*/*"]}],
+		"developer": [
+			{"methods": ["GET"], "routes": ["/info", "/version"]},
+			{"routes": ["/containers/json", "/containers/create", "/containers/*/*"], "labels": {"owner": "$USER"}}
+		],
+		"viewer": [{"methods": ["GET"], "routes": ["/version"]}]
+	}
+}`
+
+// policyTestBackend is a PolicyBackend with fixed containers which records
+// the decisions of the plugin
+type policyTestBackend struct {
+	containers map[string]map[string]string
+	decisions  []bool
+}
+
+func (b *policyTestBackend) ContainerLabels(name string) (map[string]string, error) {
+	labels, ok := b.containers[name]
+	if !ok {
+		return nil, fmt.Errorf("no such container: %s", name)
+	}
+	return labels, nil
+}
+
+func (b *policyTestBackend) LogAuthZDecision(authReq *Request, allow bool, msg string) {
+	b.decisions = append(b.decisions, allow)
+}
+
+func newTestPolicyPlugin(t *testing.T, policy string) (PolicyPlugin, *policyTestBackend, string) {
+	dir, err := ioutil.TempDir("", "authz-policy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(path, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	backend := &policyTestBackend{containers: map[string]map[string]string{
+		"mine":   {"owner": "bob"},
+		"theirs": {"owner": "carol"},
+	}}
+	p, err := NewPolicyPlugin(path, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, backend, path
+}
+
+func TestPolicyValidate(t *testing.T) {
+	for _, policy := range []Policy{
+		{Users: map[string][]string{"alice": {"admin"}}},
+		{Anonymous: []string{"admin"}},
+		{Roles: map[string][]Rule{"admin": {{Methods: []string{"GET"}}}}},
+		{Roles: map[string][]Rule{"admin": {{Routes: []string{"/containers/["}}}}},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Fatalf("Expected %+v to be invalid", policy)
+		}
+	}
+}
+
+func TestPolicyRoles(t *testing.T) {
+	var p Policy
+	policy := `{
+		"users": {"bob": ["dev", "ops", "qa"]},
+		"groups": {"web": ["viewer"], "db": ["admin"]}
+	}`
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	web := p.roles(&Request{User: "bob", UserGroups: []string{"web"}})
+	db := p.roles(&Request{User: "bob", UserGroups: []string{"db"}})
+	if expected := []string{"dev", "ops", "qa", "viewer"}; !reflect.DeepEqual(web, expected) {
+		t.Fatalf("Expected roles %v, got %v", expected, web)
+	}
+	if expected := []string{"dev", "ops", "qa", "admin"}; !reflect.DeepEqual(db, expected) {
+		t.Fatalf("Expected roles %v, got %v", expected, db)
+	}
+	if expected := []string{"dev", "ops", "qa"}; !reflect.DeepEqual(p.Users["bob"], expected) {
+		t.Fatalf("Expected the roles of the policy to be kept, got %v", p.Users["bob"])
+	}
+}
+
+func TestPolicyPluginRequest(t *testing.T) {
+	p, backend, path := newTestPolicyPlugin(t, testPolicy)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	for _, tc := range []struct {
+		req   Request
+		allow bool
+	}{
+		{Request{User: "alice", RequestMethod: "DELETE", RequestURI: "/v1.22/images/busybox"}, true},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "GET", RequestURI: "/v1.22/info"}, true},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "POST", RequestURI: "/v1.22/containers/mine/start"}, true},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "POST", RequestURI: "/v1.22/containers/theirs/start"}, false},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "POST", RequestURI: "/v1.22/containers/unknown/start"}, false},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "POST", RequestURI: "/v1.22/containers/create?name=web", RequestBody: []byte(`{"Image": "busybox", "Labels": {"owner": "bob"}}`)}, true},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "POST", RequestURI: "/v1.22/containers/create", RequestBody: []byte(`{"Image": "busybox"}`)}, false},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "GET", RequestURI: "/v1.22/containers/json?all=1"}, true},
+		{Request{User: "bob", UserGroups: []string{"dev"}, RequestMethod: "DELETE", RequestURI: "/v1.22/images/busybox"}, false},
+		{Request{User: "bob", RequestMethod: "GET", RequestURI: "/v1.22/info"}, false},
+		{Request{RequestMethod: "GET", RequestURI: "/version"}, true},
+		{Request{RequestMethod: "GET", RequestURI: "/info"}, false},
+	} {
+		res, err := p.AuthZRequest(&tc.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allow != tc.allow {
+			t.Fatalf("Expected %s %s by %q to be allowed: %v, got %v (%s)", tc.req.RequestMethod, tc.req.RequestURI, tc.req.User, tc.allow, res.Allow, res.Msg)
+		}
+		if last := backend.decisions[len(backend.decisions)-1]; last != tc.allow {
+			t.Fatalf("Expected the decision for %s %s to be logged", tc.req.RequestMethod, tc.req.RequestURI)
+		}
+	}
+}
+
+func TestPolicyPluginFilterContainers(t *testing.T) {
+	p, _, path := newTestPolicyPlugin(t, testPolicy)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	body := []byte(`[{"Id": "1", "Labels": {"owner": "bob"}}, {"Id": "2", "Labels": {"owner": "carol"}}, {"Id": "3"}]`)
+	req := &Request{
+		User:               "bob",
+		UserGroups:         []string{"dev"},
+		RequestMethod:      "GET",
+		RequestURI:         "/v1.22/containers/json",
+		ResponseStatusCode: 200,
+		ResponseBody:       body,
+	}
+	res, err := p.AuthZResponse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var containers []struct{ ID string }
+	if err := json.Unmarshal(res.ModifiedBody, &containers); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allow || len(containers) != 1 || containers[0].ID != "1" {
+		t.Fatalf("Expected the containers of bob only, got %s", res.ModifiedBody)
+	}
+
+	// the rules without labels see all the containers
+	req.User, req.UserGroups = "alice", nil
+	if res, err = p.AuthZResponse(req); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allow || res.ModifiedBody != nil {
+		t.Fatalf("Expected the response to be unmodified, got %+v", res)
+	}
+}
+
+func TestPolicyPluginReload(t *testing.T) {
+	p, _, path := newTestPolicyPlugin(t, testPolicy)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	req := &Request{RequestMethod: "GET", RequestURI: "/info"}
+	if err := ioutil.WriteFile(path, []byte(`{"anonymous": ["admin"], "roles": {"admin": [{"routes": ["/*"]}]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := p.AuthZRequest(req); err != nil || !res.Allow {
+		t.Fatalf("Expected the reloaded policy to allow the request, got %+v, %v", res, err)
+	}
+
+	// an invalid policy keeps the current one
+	if err := ioutil.WriteFile(path, []byte(`{"anonymous": ["unknown"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Reload(); err == nil {
+		t.Fatal("Expected an error reloading an invalid policy")
+	}
+	if res, err := p.AuthZRequest(req); err != nil || !res.Allow {
+		t.Fatalf("Expected the previous policy to be kept, got %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,160 @@
+package idtools
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RangeSize is the number of IDs of each range a RangeAllocator hands out,
+// which covers the 16-bit IDs images use.
+const RangeSize = 65536
+
+// RangeAllocator sets aside ranges of RangeSize IDs at the end of the
+// subordinate ID ranges of a user and group, so that user namespaces can be
+// given IDs no other user namespace is mapped to. The IDs which are not set
+// aside are the default mapping, the ranges set aside are handed out by
+// Allocate.
+type RangeAllocator struct {
+	mu sync.Mutex
+	// defaultUIDMaps and defaultGIDMaps are the default mapping
+	defaultUIDMaps []IDMap
+	defaultGIDMaps []IDMap
+	// uidStarts and gidStarts are the first host IDs of the ranges which
+	// can be allocated, paired by index
+	uidStarts []int
+	gidStarts []int
+	used      map[int]bool
+}
+
+// NewRangeAllocator returns an allocator setting aside n ranges of the
+// subordinate ID ranges of username and groupname in /etc/subuid and
+// /etc/subgid. With n 0, the default mapping holds all the IDs, as the one
+// of CreateIDMappings, and no range can be allocated.
+func NewRangeAllocator(username, groupname string, n int) (*RangeAllocator, error) {
+	subuidRanges, err := parseSubuid(username)
+	if err != nil {
+		return nil, err
+	}
+	subgidRanges, err := parseSubgid(groupname)
+	if err != nil {
+		return nil, err
+	}
+	if len(subuidRanges) == 0 {
+		return nil, fmt.Errorf("No subuid ranges found for user %q", username)
+	}
+	if len(subgidRanges) == 0 {
+		return nil, fmt.Errorf("No subgid ranges found for group %q", groupname)
+	}
+	return newRangeAllocator(subuidRanges, subgidRanges, n)
+}
+
+func newRangeAllocator(subuidRanges, subgidRanges ranges, n int) (*RangeAllocator, error) {
+	defaultUIDRanges, uidStarts, err := carveRanges(subuidRanges, n)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot set aside %d subuid ranges: %v", n, err)
+	}
+	defaultGIDRanges, gidStarts, err := carveRanges(subgidRanges, n)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot set aside %d subgid ranges: %v", n, err)
+	}
+	return &RangeAllocator{
+		defaultUIDMaps: createIDMap(defaultUIDRanges),
+		defaultGIDMaps: createIDMap(defaultGIDRanges),
+		uidStarts:      uidStarts,
+		gidStarts:      gidStarts,
+		used:           make(map[int]bool),
+	}, nil
+}
+
+// carveRanges sets aside n ranges of RangeSize IDs at the end of the
+// subordinate ID ranges. It returns the IDs left, and the first host IDs of
+// the ranges set aside, lowest first.
+func carveRanges(subidRanges ranges, n int) (ranges, []int, error) {
+	if n < 0 {
+		return nil, nil, fmt.Errorf("invalid number of ranges %d", n)
+	}
+	left := make(ranges, len(subidRanges))
+	copy(left, subidRanges)
+	sort.Sort(left)
+	var starts []int
+	for i := len(left) - 1; i >= 0 && len(starts) < n; i-- {
+		for len(starts) < n && left[i].Length >= RangeSize {
+			left[i].Length -= RangeSize
+			starts = append(starts, left[i].Start+left[i].Length)
+		}
+	}
+	if len(starts) < n {
+		return nil, nil, fmt.Errorf("the subordinate IDs only hold %d ranges of %d IDs", len(starts), RangeSize)
+	}
+	var kept ranges
+	for _, r := range left {
+		if r.Length > 0 {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, nil, fmt.Errorf("no subordinate IDs are left for the default mapping")
+	}
+	sort.Ints(starts)
+	return kept, starts, nil
+}
+
+func rangeIDMap(start int) []IDMap {
+	return []IDMap{{ContainerID: 0, HostID: start, Size: RangeSize}}
+}
+
+// Default returns the default mapping, which is not handed out by Allocate.
+func (a *RangeAllocator) Default() ([]IDMap, []IDMap) {
+	return a.defaultUIDMaps, a.defaultGIDMaps
+}
+
+// Allocate returns the mapping to a range of IDs no other mapping returned
+// by the allocator uses, until it is released.
+func (a *RangeAllocator) Allocate() ([]IDMap, []IDMap, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range a.uidStarts {
+		if !a.used[i] {
+			a.used[i] = true
+			return rangeIDMap(a.uidStarts[i]), rangeIDMap(a.gidStarts[i]), nil
+		}
+	}
+	return nil, nil, fmt.Errorf("No subordinate ID range of %d IDs left to allocate", RangeSize)
+}
+
+// Reserve marks the range of a mapping returned by Allocate as used, for
+// instance when the mapping was persisted and is loaded again.
+func (a *RangeAllocator) Reserve(uidMaps, gidMaps []IDMap) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	i, err := a.index(uidMaps, gidMaps)
+	if err != nil {
+		return err
+	}
+	if a.used[i] {
+		return fmt.Errorf("Subordinate ID range starting at %d:%d is already in use", a.uidStarts[i], a.gidStarts[i])
+	}
+	a.used[i] = true
+	return nil
+}
+
+// Release frees the range of a mapping returned by Allocate.
+func (a *RangeAllocator) Release(uidMaps, gidMaps []IDMap) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if i, err := a.index(uidMaps, gidMaps); err == nil {
+		delete(a.used, i)
+	}
+}
+
+func (a *RangeAllocator) index(uidMaps, gidMaps []IDMap) (int, error) {
+	if len(uidMaps) == 1 && len(gidMaps) == 1 && uidMaps[0].Size == RangeSize && gidMaps[0].Size == RangeSize {
+		for i := range a.uidStarts {
+			if a.uidStarts[i] == uidMaps[0].HostID && a.gidStarts[i] == gidMaps[0].HostID {
+				return i, nil
+			}
+		}
+	}
+	return -1, fmt.Errorf("ID mapping %v:%v is not a subordinate ID range of the allocator", uidMaps, gidMaps)
+}
@@ -0,0 +1,76 @@
+package idtools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRangeAllocator(t *testing.T) {
+	a, err := newRangeAllocator(
+		ranges{{Start: 300000, Length: 65536}, {Start: 100000, Length: 2*65536 + 10}},
+		ranges{{Start: 100000, Length: 4 * 65536}},
+		2,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last range of each is set aside first
+	uidMaps, gidMaps := a.Default()
+	expectedUIDMaps := []IDMap{{ContainerID: 0, HostID: 100000, Size: 65536 + 10}}
+	expectedGIDMaps := []IDMap{{ContainerID: 0, HostID: 100000, Size: 2 * 65536}}
+	if !reflect.DeepEqual(uidMaps, expectedUIDMaps) || !reflect.DeepEqual(gidMaps, expectedGIDMaps) {
+		t.Fatalf("Expected the default mapping to keep the IDs which are not set aside, got %v:%v", uidMaps, gidMaps)
+	}
+
+	expected := [][2]int{{165546, 231072}, {300000, 296608}}
+	var allocated [][]IDMap
+	for i, start := range expected {
+		uidMaps, gidMaps, err := a.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uidMaps[0].HostID != start[0] || gidMaps[0].HostID != start[1] {
+			t.Fatalf("Expected range %d to start at %v, got %v:%v", i, start, uidMaps, gidMaps)
+		}
+		allocated = append(allocated, uidMaps, gidMaps)
+	}
+	if _, _, err := a.Allocate(); err == nil {
+		t.Fatal("Expected an error allocating more ranges than there are")
+	}
+
+	a.Release(allocated[0], allocated[1])
+	if err := a.Reserve(allocated[2], allocated[3]); err == nil {
+		t.Fatal("Expected an error reserving a range in use")
+	}
+	if err := a.Reserve(allocated[0], allocated[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Reserve(rangeIDMap(1000), rangeIDMap(1000)); err == nil {
+		t.Fatal("Expected an error reserving a range of another allocator")
+	}
+}
+
+func TestRangeAllocatorNoRanges(t *testing.T) {
+	subuidRanges := ranges{{Start: 100000, Length: 10 * 65536}}
+	a, err := newRangeAllocator(subuidRanges, ranges{{Start: 100000, Length: 1000}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uidMaps, gidMaps := a.Default()
+	if !reflect.DeepEqual(uidMaps, createIDMap(subuidRanges)) || !reflect.DeepEqual(gidMaps, []IDMap{{ContainerID: 0, HostID: 100000, Size: 1000}}) {
+		t.Fatalf("Expected the default mapping to hold all the IDs, got %v:%v", uidMaps, gidMaps)
+	}
+	if _, _, err := a.Allocate(); err == nil {
+		t.Fatal("Expected an error allocating a range")
+	}
+}
+
+func TestRangeAllocatorTooFewIDs(t *testing.T) {
+	for _, n := range []int{-1, 2, 3} {
+		if _, err := newRangeAllocator(ranges{{Start: 100000, Length: 2 * 65536}}, ranges{{Start: 100000, Length: 4 * 65536}}, n); err == nil {
+			t.Fatalf("Expected an error setting aside %d ranges", n)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package metrics provides counters, gauges and histograms which are
+// exposed in the Prometheus text format.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefBuckets are the default buckets of a histogram, suited for measuring
+// the latency of operations in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var validName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Collector is a family of metrics which can be registered with a Registry.
+type Collector interface {
+	// Name returns the name of the metric family.
+	Name() string
+	// Write writes the metric family in the Prometheus text format to w.
+	Write(w io.Writer) error
+}
+
+// desc describes a metric family.
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func newDesc(name, help, typ string, labels []string) desc {
+	if !validName.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, l := range labels {
+		if !validName.MatchString(l) || strings.Contains(l, ":") || l == "le" {
+			panic(fmt.Sprintf("metrics: invalid label name %q of %s", l, name))
+		}
+	}
+	return desc{name: name, help: help, typ: typ, labels: labels}
+}
+
+// Name returns the name of the metric family.
+func (d *desc) Name() string {
+	return d.name
+}
+
+// key returns the key of the series with the label values, which must match
+// the labels of the family in number.
+func (d *desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (d *desc) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.typ)
+}
+
+// writeSample writes a sample of the series with the label values. The
+// extra label and value are added if extra is not empty.
+func (d *desc) writeSample(w io.Writer, suffix string, labelValues []string, extra, extraValue string, v float64) {
+	var pairs []string
+	for i, l := range d.labels {
+		pairs = append(pairs, l+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra+`="`+escapeLabelValue(extraValue)+`"`)
+	}
+	labels := ""
+	if len(pairs) > 0 {
+		labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(w, "%s%s%s %s\n", d.name, suffix, labels, formatFloat(v))
+}
+
+// series is the value of a counter or gauge for a set of label values.
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// vec holds the series of a counter or gauge.
+type vec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func (v *vec) add(delta float64, labelValues []string) {
+	key := v.key(labelValues)
+	v.mu.Lock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	s.value += delta
+	v.mu.Unlock()
+}
+
+func (v *vec) set(value float64, labelValues []string) {
+	key := v.key(labelValues)
+	v.mu.Lock()
+	v.series[key] = &series{labelValues: append([]string(nil), labelValues...), value: value}
+	v.mu.Unlock()
+}
+
+// Write writes the metric family in the Prometheus text format to w.
+func (v *vec) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	v.writeHeader(&buf)
+
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := v.series[k]
+		v.writeSample(&buf, "", s.labelValues, "", "", s.value)
+	}
+	v.mu.Unlock()
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Counter is a metric whose value only goes up, partitioned by labels.
+type Counter struct {
+	vec
+}
+
+// NewCounter returns a counter with the name, help text and label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{vec{desc: newDesc(name, help, "counter", labels), series: make(map[string]*series)}}
+}
+
+// Inc increments the counter of the label values by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the counter of the label
+// values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+	c.add(v, labelValues)
+}
+
+// Gauge is a metric whose value can go up and down, partitioned by labels.
+type Gauge struct {
+	vec
+}
+
+// NewGauge returns a gauge with the name, help text and label names.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{vec{desc: newDesc(name, help, "gauge", labels), series: make(map[string]*series)}}
+}
+
+// Set sets the gauge of the label values to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.set(v, labelValues)
+}
+
+// Add adds v to the gauge of the label values.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.add(v, labelValues)
+}
+
+// Inc increments the gauge of the label values by one.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.add(1, labelValues)
+}
+
+// Dec decrements the gauge of the label values by one.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.add(-1, labelValues)
+}
+
+// GaugeFunc is a gauge with a single label whose values are computed each
+// time it is written.
+type GaugeFunc struct {
+	desc
+	f func() map[string]float64
+}
+
+// NewGaugeFunc returns a gauge with the name, help text and label name. f
+// returns the value of the gauge for each value of the label.
+func NewGaugeFunc(name, help, label string, f func() map[string]float64) *GaugeFunc {
+	return &GaugeFunc{desc: newDesc(name, help, "gauge", []string{label}), f: f}
+}
+
+// Write writes the metric family in the Prometheus text format to w.
+func (g *GaugeFunc) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	g.writeHeader(&buf)
+
+	values := g.f()
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		g.writeSample(&buf, "", []string{k}, "", "", values[k])
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// histogramSeries holds the observations of a histogram for a set of label
+// values.
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// Histogram counts observations, such as latencies, in configurable
+// buckets, partitioned by labels.
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// NewHistogram returns a histogram with the name, help text, upper bounds
+// of the buckets in increasing order, and label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic(fmt.Sprintf("metrics: buckets of %s are not in increasing order", name))
+		}
+	}
+	return &Histogram{
+		desc:    newDesc(name, help, "histogram", labels),
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+// Observe adds the observation v to the histogram of the label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// ObserveSince adds the number of seconds since start to the histogram of
+// the label values.
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Write writes the metric family in the Prometheus text format to w.
+func (h *Histogram) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	h.writeHeader(&buf)
+
+	h.mu.Lock()
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series[k]
+		for i, b := range h.buckets {
+			h.writeSample(&buf, "_bucket", s.labelValues, "le", formatFloat(b), float64(s.counts[i]))
+		}
+		h.writeSample(&buf, "_bucket", s.labelValues, "le", "+Inf", float64(s.count))
+		h.writeSample(&buf, "_sum", s.labelValues, "", "", s.sum)
+		h.writeSample(&buf, "_count", s.labelValues, "", "", float64(s.count))
+	}
+	h.mu.Unlock()
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("test_requests_total", "The number of requests", "method", "code")
+	c.Inc("GET", "200")
+	c.Inc("GET", "200")
+	c.Add(3, "POST", "500")
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_requests_total The number of requests
+# TYPE test_requests_total counter
+test_requests_total{method="GET",code="200"} 2
+test_requests_total{method="POST",code="500"} 3
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestCounterDecrease(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic when decreasing a counter")
+		}
+	}()
+	NewCounter("test_total", "Test").Add(-1)
+}
+
+func TestLabelValuesMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic when the label values do not match the labels")
+		}
+	}()
+	NewCounter("test_total", "Test", "a", "b").Inc("a")
+}
+
+func TestInvalidName(t *testing.T) {
+	for _, name := range []string{"", "0abc", "a-b", "a b"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Expected a panic for the invalid name %q", name)
+				}
+			}()
+			NewGauge(name, "Test")
+		}()
+	}
+}
+
+func TestGauge(t *testing.T) {
+	g := NewGauge("test_inflight", "In flight \\ requests\nnow")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	g.Add(0.5)
+
+	var buf bytes.Buffer
+	if err := g.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_inflight In flight \\ requests\nnow
+# TYPE test_inflight gauge
+test_inflight 1.5
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	g.Set(7)
+	buf.Reset()
+	g.Write(&buf)
+	if !bytes.Contains(buf.Bytes(), []byte("test_inflight 7\n")) {
+		t.Fatalf("Expected the gauge to be set to 7, got\n%s", buf.String())
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	g := NewGaugeFunc("test_containers", "Containers", "state", func() map[string]float64 {
+		return map[string]float64{"running": 2, "exited": 1}
+	})
+
+	var buf bytes.Buffer
+	if err := g.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_containers Containers
+# TYPE test_containers gauge
+test_containers{state="exited"} 1
+test_containers{state="running"} 2
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("test_seconds", "Latency", []float64{0.1, 1}, "action")
+	h.Observe(0.05, "start")
+	h.Observe(0.5, "start")
+	h.Observe(2, "start")
+
+	var buf bytes.Buffer
+	if err := h.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_seconds Latency
+# TYPE test_seconds histogram
+test_seconds_bucket{action="start",le="0.1"} 1
+test_seconds_bucket{action="start",le="1"} 2
+test_seconds_bucket{action="start",le="+Inf"} 3
+test_seconds_sum{action="start"} 2.55
+test_seconds_count{action="start"} 3
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	c := NewCounter("test_total", "Test", "path")
+	c.Inc("a\"b\\c\nd")
+
+	var buf bytes.Buffer
+	c.Write(&buf)
+	if !bytes.Contains(buf.Bytes(), []byte(`test_total{path="a\"b\\c\nd"} 1`)) {
+		t.Fatalf("Expected the label value to be escaped, got\n%s", buf.String())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	b := NewCounter("b_total", "B")
+	a := NewGauge("a", "A")
+	r.MustRegister(b, a)
+	if err := r.Register(NewCounter("a", "Another A")); err == nil {
+		t.Fatal("Expected an error registering a metric twice")
+	}
+	b.Inc()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, nil)
+	if ct := rec.Header().Get("Content-Type"); ct != contentType {
+		t.Fatalf("Expected content type %q, got %q", contentType, ct)
+	}
+	expected := `# HELP a A
+# TYPE a gauge
+# HELP b_total B
+# TYPE b_total counter
+b_total 1
+`
+	if rec.Body.String() != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, rec.Body.String())
+	}
+
+	r.Unregister(a)
+	var buf bytes.Buffer
+	r.Write(&buf)
+	if bytes.Contains(buf.Bytes(), []byte("# TYPE a gauge")) {
+		t.Fatalf("Expected a to be unregistered, got\n%s", buf.String())
+	}
+}
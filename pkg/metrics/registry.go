@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// contentType is the content type of the Prometheus text format.
+const contentType = "text/plain; version=0.0.4"
+
+// Registry holds the collectors whose metrics are exposed together.
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]Collector
+}
+
+// DefaultRegistry is the registry the package level functions use.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Register adds the collector c to the registry. It returns an error if a
+// collector with the same name is already registered.
+func (r *Registry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[c.Name()]; exists {
+		return fmt.Errorf("metric %s is already registered", c.Name())
+	}
+	r.collectors[c.Name()] = c
+	return nil
+}
+
+// MustRegister adds the collectors to the registry, and panics if any of
+// them cannot be registered.
+func (r *Registry) MustRegister(cs ...Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Unregister removes the collector c from the registry.
+func (r *Registry) Unregister(c Collector) {
+	r.mu.Lock()
+	if r.collectors[c.Name()] == c {
+		delete(r.collectors, c.Name())
+	}
+	r.mu.Unlock()
+}
+
+// Write writes the metrics of all the collectors in the registry, sorted by
+// name, in the Prometheus text format to w.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+
+	sort.Sort(byName(collectors))
+	for _, c := range collectors {
+		if err := c.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP writes the metrics of the registry in response to a scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		logrus.Errorf("Error collecting metrics: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	buf.WriteTo(w)
+}
+
+type byName []Collector
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i].Name() < s[j].Name() }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Register adds the collector c to the default registry.
+func Register(c Collector) error {
+	return DefaultRegistry.Register(c)
+}
+
+// MustRegister adds the collectors to the default registry, and panics if
+// any of them cannot be registered.
+func MustRegister(cs ...Collector) {
+	DefaultRegistry.MustRegister(cs...)
+}
+
+// Unregister removes the collector c from the default registry.
+func Unregister(c Collector) {
+	DefaultRegistry.Unregister(c)
+}
+
+// Handler returns a handler that serves the metrics of the default registry.
+func Handler() http.Handler {
+	return DefaultRegistry
+}
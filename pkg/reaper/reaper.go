@@ -0,0 +1,84 @@
+// +build linux
+
+// Package reaper implements a minimal init process, which runs a command as
+// its child, forwards signals to it and reaps the processes reparented to it.
+package reaper
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// Signals are the signals an init forwards to its child. SIGKILL and SIGSTOP
+// can not be caught, and the other signals are left to their default action.
+var Signals = []os.Signal{
+	syscall.SIGHUP,
+	syscall.SIGINT,
+	syscall.SIGQUIT,
+	syscall.SIGTERM,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+	syscall.SIGWINCH,
+}
+
+// Run runs the command args as a child of the process, forwarding Signals to
+// it and reaping any exited child on SIGCHLD, until the command exits. It
+// returns the exit status of the command, or 128 plus the number of the
+// signal which terminated it.
+func Run(args []string) (int, error) {
+	// signals are caught before the child is started, so that none is lost
+	sigc := make(chan os.Signal, 128)
+	signal.Notify(sigc, append([]os.Signal{syscall.SIGCHLD}, Signals...)...)
+	defer signal.Stop(sigc)
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return 127, err
+	}
+	child, err := os.StartProcess(path, args, &os.ProcAttr{
+		Env:   os.Environ(),
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	})
+	if err != nil {
+		return 126, err
+	}
+
+	for sig := range sigc {
+		if sig != syscall.SIGCHLD {
+			child.Signal(sig)
+			continue
+		}
+		if status, exited := Reap(child.Pid); exited {
+			return status, nil
+		}
+	}
+	panic("unreachable")
+}
+
+// Reap waits for all the exited children of the process, and returns the
+// exit status of the child pid if it is one of them.
+func Reap(pid int) (int, bool) {
+	var (
+		status int
+		exited bool
+	)
+	for {
+		var ws syscall.WaitStatus
+		wpid, err := syscall.Wait4(-1, &ws, syscall.WNOHANG, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil || wpid <= 0 {
+			return status, exited
+		}
+		if wpid == pid {
+			exited = true
+			status = ws.ExitStatus()
+			if ws.Signaled() {
+				status = 128 + int(ws.Signal())
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// +build linux
+
+package reaper
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReap(t *testing.T) {
+	other, err := os.StartProcess("/bin/sh", []string{"sh", "-c", "exit 0"}, &os.ProcAttr{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := os.StartProcess("/bin/sh", []string{"sh", "-c", "sleep 0.1; exit 5"}, &os.ProcAttr{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		if status, exited := Reap(child.Pid); exited {
+			if status != 5 {
+				t.Fatalf("Expected exit status 5, got %d", status)
+			}
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatal("Timed out reaping the child")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// the other child exited first, and was reaped as well
+	if _, err := syscall.Wait4(other.Pid, nil, syscall.WNOHANG, nil); err != syscall.ECHILD {
+		t.Fatalf("Expected the other child to be reaped, got %v", err)
+	}
+}
+
+func TestRunExitStatus(t *testing.T) {
+	status, err := Run([]string{"sh", "-c", "exit 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 3 {
+		t.Fatalf("Expected exit status 3, got %d", status)
+	}
+
+	if status, err := Run([]string{"docker-reaper-test-missing"}); err == nil || status != 127 {
+		t.Fatalf("Expected status 127 and an error for a missing command, got %d, %v", status, err)
+	}
+}
+
+func TestRunForwardsSignals(t *testing.T) {
+	// the child sends SIGTERM to its parent, which forwards it back
+	status, err := Run([]string{"sh", "-c", "kill -TERM $PPID; while :; do :; done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 128 + int(syscall.SIGTERM); status != expected {
+		t.Fatalf("Expected exit status %d, got %d", expected, status)
+	}
+}
+
+func TestRunIgnoresOtherSignals(t *testing.T) {
+	// SIGALRM is not forwarded, so the child exits normally
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGALRM)
+	defer signal.Stop(sigc)
+
+	status, err := Run([]string{"sh", "-c", "kill -ALRM $PPID; sleep 0.1; exit 4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 4 {
+		t.Fatalf("Expected exit status 4, got %d", status)
+	}
+	select {
+	case <-sigc:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected SIGALRM to be sent")
+	}
+}
@@ -26,6 +26,9 @@ type SysInfo struct {
 
 	// Whether the cgroup has the mountpoint of "devices" or not
 	CgroupDevicesEnabled bool
+
+	// Whether the cgroup has the mountpoint of "pids" or not
+	PidsLimit bool
 }
 
 type cgroupMemInfo struct {
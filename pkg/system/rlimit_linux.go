@@ -0,0 +1,17 @@
+package system
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Prlimit gets and sets the resource limit of the process pid. If newLimit
+// is not nil, the limit is set to it. If oldLimit is not nil, the previous
+// limit is stored in it.
+func Prlimit(pid int, resource int, newLimit, oldLimit *syscall.Rlimit) error {
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(resource), uintptr(unsafe.Pointer(newLimit)), uintptr(unsafe.Pointer(oldLimit)), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -143,15 +143,20 @@ func TestPidModeTest(t *testing.T) {
 }
 
 func TestRestartPolicy(t *testing.T) {
-	restartPolicies := map[container.RestartPolicy][]bool{
-		// none, always, failure
-		container.RestartPolicy{}:                {false, false, false},
-		container.RestartPolicy{"something", 0}:  {false, false, false},
-		container.RestartPolicy{"no", 0}:         {true, false, false},
-		container.RestartPolicy{"always", 0}:     {false, true, false},
-		container.RestartPolicy{"on-failure", 0}: {false, false, true},
+	restartPolicies := []struct {
+		restartPolicy container.RestartPolicy
+		state         []bool
+	}{
+		// none, always, failure, exit codes
+		{container.RestartPolicy{}, []bool{false, false, false, false}},
+		{container.RestartPolicy{Name: "something"}, []bool{false, false, false, false}},
+		{container.RestartPolicy{Name: "no"}, []bool{true, false, false, false}},
+		{container.RestartPolicy{Name: "always"}, []bool{false, true, false, false}},
+		{container.RestartPolicy{Name: "on-failure"}, []bool{false, false, true, false}},
+		{container.RestartPolicy{Name: "on-exit-codes", ExitCodes: []int{1}}, []bool{false, false, false, true}},
 	}
-	for restartPolicy, state := range restartPolicies {
+	for _, p := range restartPolicies {
+		restartPolicy, state := p.restartPolicy, p.state
 		if restartPolicy.IsNone() != state[0] {
 			t.Fatalf("RestartPolicy.IsNone for %v should have been %v but was %v", restartPolicy, state[0], restartPolicy.IsNone())
 		}
@@ -161,6 +166,9 @@ func TestRestartPolicy(t *testing.T) {
 		if restartPolicy.IsOnFailure() != state[2] {
 			t.Fatalf("RestartPolicy.IsOnFailure for %v should have been %v but was %v", restartPolicy, state[2], restartPolicy.IsOnFailure())
 		}
+		if restartPolicy.IsOnExitCodes() != state[3] {
+			t.Fatalf("RestartPolicy.IsOnExitCodes for %v should have been %v but was %v", restartPolicy, state[3], restartPolicy.IsOnExitCodes())
+		}
 	}
 }
 func TestDecodeHostConfig(t *testing.T) {
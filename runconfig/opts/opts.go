@@ -3,8 +3,10 @@ package opts
 import (
 	"fmt"
 	fopts "github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/mount"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -68,3 +70,18 @@ func ValidateMACAddress(val string) (string, error) {
 	}
 	return val, nil
 }
+
+// ValidateTmpfs validates a tmpfs mount in the form dest[:options], where
+// dest is an absolute path and options are valid tmpfs mount options.
+func ValidateTmpfs(val string) (string, error) {
+	arr := strings.SplitN(val, ":", 2)
+	if !filepath.IsAbs(arr[0]) {
+		return "", fmt.Errorf("invalid tmpfs mount %s: destination must be an absolute path", val)
+	}
+	if len(arr) > 1 {
+		if _, _, err := mount.ParseTmpfsOptions(arr[1]); err != nil {
+			return "", err
+		}
+	}
+	return val, nil
+}
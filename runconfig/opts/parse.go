@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
@@ -38,8 +40,12 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flEnv               = opts.NewListOpts(ValidateEnv)
 		flLabels            = opts.NewListOpts(ValidateEnv)
 		flDevices           = opts.NewListOpts(ValidateDevice)
+		flDeviceCgroupRules = opts.NewListOpts(ValidateDeviceCgroupRule)
+		flNetEgressRate     = opts.NewListOpts(ValidateNetworkRate)
+		flNetIngressRate    = opts.NewListOpts(ValidateNetworkRate)
 
 		flUlimits = NewUlimitOpt(nil)
+		flSecrets = NewSecretOpt()
 
 		flPublish           = opts.NewListOpts(nil)
 		flExpose            = opts.NewListOpts(nil)
@@ -53,15 +59,18 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flCapDrop           = opts.NewListOpts(nil)
 		flGroupAdd          = opts.NewListOpts(nil)
 		flSecurityOpt       = opts.NewListOpts(nil)
+		flSysctls           = opts.NewMapOpts(nil, ValidateSysctl)
 		flLabelsFile        = opts.NewListOpts(nil)
 		flLoggingOpts       = opts.NewListOpts(nil)
 		flPrivileged        = cmd.Bool([]string{"-privileged"}, false, "Give extended privileges to this container")
 		flPidMode           = cmd.String([]string{"-pid"}, "", "PID namespace to use")
 		flUTSMode           = cmd.String([]string{"-uts"}, "", "UTS namespace to use")
+		flUsernsMode        = cmd.String([]string{"-userns"}, "", "User namespace to use")
 		flPublishAll        = cmd.Bool([]string{"P", "-publish-all"}, false, "Publish all exposed ports to random ports")
 		flStdin             = cmd.Bool([]string{"i", "-interactive"}, false, "Keep STDIN open even if not attached")
 		flTty               = cmd.Bool([]string{"t", "-tty"}, false, "Allocate a pseudo-TTY")
 		flOomKillDisable    = cmd.Bool([]string{"-oom-kill-disable"}, false, "Disable OOM Killer")
+		flInit              = cmd.Bool([]string{"-init"}, false, "Run an init inside the container that forwards signals and reaps processes")
 		flOomScoreAdj       = cmd.Int([]string{"-oom-score-adj"}, 0, "Tune host's OOM preferences (-1000 to 1000)")
 		flContainerIDFile   = cmd.String([]string{"-cidfile"}, "", "Write the container ID to the file")
 		flEntrypoint        = cmd.String([]string{"-entrypoint"}, "", "Overwrite the default ENTRYPOINT of the image")
@@ -70,6 +79,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flMemoryReservation = cmd.String([]string{"-memory-reservation"}, "", "Memory soft limit")
 		flMemorySwap        = cmd.String([]string{"-memory-swap"}, "", "Swap limit equal to memory plus swap: '-1' to enable unlimited swap")
 		flKernelMemory      = cmd.String([]string{"-kernel-memory"}, "", "Kernel memory limit")
+		flPidsLimit         = cmd.Int64([]string{"-pids-limit"}, 0, "Tune container pids limit (set -1 for unlimited)")
 		flUser              = cmd.String([]string{"u", "-user"}, "", "Username or UID (format: <name|uid>[:<group|gid>])")
 		flWorkingDir        = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
 		flCPUShares         = cmd.Int64([]string{"#c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
@@ -85,11 +95,16 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flIPv6Address       = cmd.String([]string{"-ip6"}, "", "Container IPv6 address (e.g. 2001:db8::33)")
 		flIpcMode           = cmd.String([]string{"-ipc"}, "", "IPC namespace to use")
 		flRestartPolicy     = cmd.String([]string{"-restart"}, "no", "Restart policy to apply when a container exits")
+		flRestartDelay      = cmd.String([]string{"-restart-delay"}, "", "Delay before the first restart, 100ms by default")
+		flRestartMaxDelay   = cmd.String([]string{"-restart-max-delay"}, "", "Maximum delay between restarts")
+		flRestartReset      = cmd.String([]string{"-restart-reset-window"}, "", "Run time after which the restart delay is reset, 10s by default")
 		flReadonlyRootfs    = cmd.Bool([]string{"-read-only"}, false, "Mount the container's root filesystem as read only")
+		flReadonlyTmpfs     = cmd.Bool([]string{"-read-only-tmpfs"}, false, "Mount a tmpfs at the daemon's default writable paths of a read only container")
 		flLoggingDriver     = cmd.String([]string{"-log-driver"}, "", "Logging driver for container")
 		flCgroupParent      = cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
 		flVolumeDriver      = cmd.String([]string{"-volume-driver"}, "", "Optional volume driver for the container")
 		flStopSignal        = cmd.String([]string{"-stop-signal"}, signal.DefaultStopSignal, fmt.Sprintf("Signal to stop a container, %v by default", signal.DefaultStopSignal))
+		flStopTimeout       = cmd.Int([]string{"-stop-timeout"}, 10, "Timeout (in seconds) to stop a container")
 		flIsolation         = cmd.String([]string{"-isolation"}, "", "Container isolation level")
 		flShmSize           = cmd.String([]string{"-shm-size"}, "", "Size of /dev/shm, default value is 64MB")
 	)
@@ -104,6 +119,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 	cmd.Var(&flTmpfs, []string{"-tmpfs"}, "Mount a tmpfs directory")
 	cmd.Var(&flLinks, []string{"-link"}, "Add link to another container")
 	cmd.Var(&flDevices, []string{"-device"}, "Add a host device to the container")
+	cmd.Var(&flDeviceCgroupRules, []string{"-device-cgroup-rule"}, "Add a rule to the cgroup allowed devices list")
 	cmd.Var(&flLabels, []string{"l", "-label"}, "Set meta data on a container")
 	cmd.Var(&flLabelsFile, []string{"-label-file"}, "Read in a line delimited file of labels")
 	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
@@ -119,7 +135,11 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 	cmd.Var(&flCapDrop, []string{"-cap-drop"}, "Drop Linux capabilities")
 	cmd.Var(&flGroupAdd, []string{"-group-add"}, "Add additional groups to join")
 	cmd.Var(&flSecurityOpt, []string{"-security-opt"}, "Security Options")
+	cmd.Var(flSysctls, []string{"-sysctl"}, "Sysctl options")
 	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options")
+	cmd.Var(flSecrets, []string{"-secret"}, "Mount a secret of the daemon in the container")
+	cmd.Var(&flNetEgressRate, []string{"-net-egress-rate"}, "Limit the egress rate (bytes per second) of an interface ([interface:]rate)")
+	cmd.Var(&flNetIngressRate, []string{"-net-ingress-rate"}, "Limit the ingress rate (bytes per second) of an interface ([interface:]rate)")
 	cmd.Var(&flLoggingOpts, []string{"-log-opt"}, "Log driver options")
 
 	cmd.Require(flag.Min, 1)
@@ -313,10 +333,36 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		return nil, nil, nil, cmd, fmt.Errorf("--uts: invalid UTS mode")
 	}
 
+	usernsMode := container.UsernsMode(*flUsernsMode)
+	if !usernsMode.Valid() {
+		return nil, nil, nil, cmd, fmt.Errorf("--userns: invalid user namespace mode")
+	}
+
+	networkRateLimits, err := ParseNetworkRateLimits(flNetEgressRate.GetAll(), flNetIngressRate.GetAll())
+	if err != nil {
+		return nil, nil, nil, cmd, err
+	}
+
 	restartPolicy, err := ParseRestartPolicy(*flRestartPolicy)
 	if err != nil {
 		return nil, nil, nil, cmd, err
 	}
+	for _, d := range []struct {
+		flag  string
+		value string
+		delay *time.Duration
+	}{
+		{"--restart-delay", *flRestartDelay, &restartPolicy.InitialDelay},
+		{"--restart-max-delay", *flRestartMaxDelay, &restartPolicy.MaxDelay},
+		{"--restart-reset-window", *flRestartReset, &restartPolicy.ResetWindow},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if *d.delay, err = time.ParseDuration(d.value); err != nil || *d.delay < 0 {
+			return nil, nil, nil, cmd, fmt.Errorf("invalid duration for %s: %s", d.flag, d.value)
+		}
+	}
 
 	loggingOpts, err := parseLoggingOpts(*flLoggingDriver, flLoggingOpts.GetAll())
 	if err != nil {
@@ -348,7 +394,10 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		BlkioDeviceReadIOps:  flDeviceReadIOps.GetList(),
 		BlkioDeviceWriteIOps: flDeviceWriteIOps.GetList(),
 		Ulimits:              flUlimits.GetList(),
+		DeviceCgroupRules:    flDeviceCgroupRules.GetAll(),
 		Devices:              deviceMappings,
+		PidsLimit:            *flPidsLimit,
+		NetworkRateLimits:    networkRateLimits,
 	}
 
 	config := &container.Config{
@@ -376,6 +425,10 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		StopSignal:      *flStopSignal,
 	}
 
+	if cmd.IsSet("-stop-timeout") {
+		config.StopTimeout = flStopTimeout
+	}
+
 	hostConfig := &container.HostConfig{
 		Binds:           binds,
 		ContainerIDFile: *flContainerIDFile,
@@ -398,12 +451,16 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		IpcMode:        ipcMode,
 		PidMode:        pidMode,
 		UTSMode:        utsMode,
+		UsernsMode:     usernsMode,
 		CapAdd:         strslice.New(flCapAdd.GetAll()...),
 		CapDrop:        strslice.New(flCapDrop.GetAll()...),
 		GroupAdd:       flGroupAdd.GetAll(),
 		RestartPolicy:  restartPolicy,
+		Secrets:        flSecrets.GetList(),
 		SecurityOpt:    securityOpts,
+		Sysctls:        flSysctls.GetAll(),
 		ReadonlyRootfs: *flReadonlyRootfs,
+		ReadonlyTmpfs:  *flReadonlyTmpfs,
 		LogConfig:      container.LogConfig{Type: *flLoggingDriver, Config: loggingOpts},
 		VolumeDriver:   *flVolumeDriver,
 		Isolation:      container.IsolationLevel(*flIsolation),
@@ -412,6 +469,10 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		Tmpfs:          tmpfs,
 	}
 
+	if cmd.IsSet("-init") {
+		hostConfig.Init = flInit
+	}
+
 	// When allocating stdin in attached mode, close stdin at client disconnect
 	if config.OpenStdin && config.AttachStdin {
 		config.StdinOnce = true
@@ -486,10 +547,11 @@ func parseLoggingOpts(loggingDriver string, loggingOpts []string) (map[string]st
 func parseSecurityOpts(securityOpts []string) ([]string, error) {
 	for key, opt := range securityOpts {
 		con := strings.SplitN(opt, ":", 2)
-		if len(con) == 1 {
+		if len(con) == 1 && con[0] != "no-new-privileges" {
 			return securityOpts, fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
-		if con[0] == "seccomp" && con[1] != "unconfined" {
+		// the recorded profile is written by the daemon, on its host
+		if con[0] == "seccomp" && con[1] != "unconfined" && !strings.HasPrefix(con[1], "record:") {
 			f, err := ioutil.ReadFile(con[1])
 			if err != nil {
 				return securityOpts, fmt.Errorf("Opening seccomp profile (%s) failed: %v", con[1], err)
@@ -536,6 +598,25 @@ func ParseRestartPolicy(policy string) (container.RestartPolicy, error) {
 				return p, err
 			}
 
+			p.MaximumRetryCount = count
+		}
+	case "on-exit-codes":
+		if len(parts) < 2 || len(parts) > 3 || parts[1] == "" {
+			return p, fmt.Errorf("exit codes format is not valid, usage: 'on-exit-codes:CODE[,CODE...][:N]'")
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			code, err := strconv.Atoi(c)
+			if err != nil {
+				return p, fmt.Errorf("invalid exit code %q", c)
+			}
+			p.ExitCodes = append(p.ExitCodes, code)
+		}
+		if len(parts) == 3 {
+			count, err := strconv.Atoi(parts[2])
+			if err != nil {
+				return p, err
+			}
+
 			p.MaximumRetryCount = count
 		}
 	default:
@@ -630,6 +711,20 @@ func ValidDeviceMode(mode string) bool {
 	return true
 }
 
+// DeviceCgroupRuleRegexp matches a device cgroup rule, such as "c 189:* rmw",
+// with submatches for its type, major and minor numbers and access.
+var DeviceCgroupRuleRegexp = regexp.MustCompile(`^([acb]) ([0-9]+|\*):([0-9]+|\*) ([rwm]{1,3})$`)
+
+// ValidateDeviceCgroupRule validates a device cgroup rule, which is in the
+// form 'type major:minor access' as written to devices.allow, where major
+// and minor can be '*'.
+func ValidateDeviceCgroupRule(val string) (string, error) {
+	if DeviceCgroupRuleRegexp.MatchString(val) {
+		return val, nil
+	}
+	return val, fmt.Errorf("invalid device cgroup rule format: '%s'", val)
+}
+
 // ValidateDevice validates a path for devices
 // It will make sure 'val' is in the form:
 //    [host-dir:]container-path[:mode]
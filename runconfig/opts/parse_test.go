@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/runconfig"
@@ -527,6 +529,20 @@ func TestParseModes(t *testing.T) {
 	if !hostconfig.UTSMode.Valid() {
 		t.Fatalf("Expected a valid UTSMode, got %v", hostconfig.UTSMode)
 	}
+	// userns ko
+	if _, _, _, _, err := parseRun([]string{"--userns=container:", "img", "cmd"}); err == nil || err.Error() != "--userns: invalid user namespace mode" {
+		t.Fatalf("Expected an error with message '--userns: invalid user namespace mode', got %v", err)
+	}
+	// userns ok
+	for _, mode := range []string{"host", "private"} {
+		_, hostconfig, _, _, err = parseRun([]string{"--userns=" + mode, "img", "cmd"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(hostconfig.UsernsMode) != mode {
+			t.Fatalf("Expected UsernsMode %s, got %v", mode, hostconfig.UsernsMode)
+		}
+	}
 	// shm-size ko
 	if _, _, _, _, err = parseRun([]string{"--shm-size=a128m", "img", "cmd"}); err == nil || err.Error() != "invalid size: 'a128m'" {
 		t.Fatalf("Expected an error with message 'invalid size: a128m', got %v", err)
@@ -548,6 +564,8 @@ func TestParseRestartPolicy(t *testing.T) {
 		"always:2:3":         "maximum restart count not valid with restart policy of \"always\"",
 		"on-failure:invalid": `strconv.ParseInt: parsing "invalid": invalid syntax`,
 		"on-failure:2:5":     "restart count format is not valid, usage: 'on-failure:N' or 'on-failure'",
+		"on-exit-codes":      "exit codes format is not valid, usage: 'on-exit-codes:CODE[,CODE...][:N]'",
+		"on-exit-codes:1,a":  `invalid exit code "a"`,
 	}
 	valids := map[string]container.RestartPolicy{
 		"": {},
@@ -559,6 +577,15 @@ func TestParseRestartPolicy(t *testing.T) {
 			Name:              "on-failure",
 			MaximumRetryCount: 1,
 		},
+		"on-exit-codes:1,137": {
+			Name:      "on-exit-codes",
+			ExitCodes: []int{1, 137},
+		},
+		"on-exit-codes:2:3": {
+			Name:              "on-exit-codes",
+			ExitCodes:         []int{2},
+			MaximumRetryCount: 3,
+		},
 	}
 	for restart, expectedError := range invalids {
 		if _, _, _, _, err := parseRun([]string{fmt.Sprintf("--restart=%s", restart), "img", "cmd"}); err == nil || err.Error() != expectedError {
@@ -570,7 +597,7 @@ func TestParseRestartPolicy(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if hostconfig.RestartPolicy != expected {
+		if !reflect.DeepEqual(hostconfig.RestartPolicy, expected) {
 			t.Fatalf("Expected %v, got %v", expected, hostconfig.RestartPolicy)
 		}
 	}
@@ -813,3 +840,127 @@ func TestVolumeSplitN(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRestartDelays(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--restart=always", "--restart-delay=1s", "--restart-max-delay=1m", "--restart-reset-window=5m", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := hostconfig.RestartPolicy
+	if p.InitialDelay != time.Second || p.MaxDelay != time.Minute || p.ResetWindow != 5*time.Minute {
+		t.Fatalf("Expected delays of 1s, 1m and 5m, got %v, %v and %v", p.InitialDelay, p.MaxDelay, p.ResetWindow)
+	}
+	if _, _, _, _, err := parseRun([]string{"--restart-delay=soon", "img", "cmd"}); err == nil || err.Error() != "invalid duration for --restart-delay: soon" {
+		t.Fatalf("Expected an error for an invalid duration, got %v", err)
+	}
+}
+
+func TestParseStopTimeout(t *testing.T) {
+	config, _, _, _, err := parseRun([]string{"img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.StopTimeout != nil {
+		t.Fatalf("Expected no stop timeout, got %d", *config.StopTimeout)
+	}
+	config, _, _, _, err = parseRun([]string{"--stop-timeout=30", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.StopTimeout == nil || *config.StopTimeout != 30 {
+		t.Fatalf("Expected a stop timeout of 30, got %v", config.StopTimeout)
+	}
+}
+
+func TestParseInit(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostconfig.Init != nil {
+		t.Fatalf("Expected Init to be unset, got %v", *hostconfig.Init)
+	}
+	for _, tc := range []struct {
+		arg      string
+		expected bool
+	}{
+		{"--init", true},
+		{"--init=false", false},
+	} {
+		_, hostconfig, _, _, err := parseRun([]string{tc.arg, "img", "cmd"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hostconfig.Init == nil || *hostconfig.Init != tc.expected {
+			t.Fatalf("%s: expected Init to be %v, got %v", tc.arg, tc.expected, hostconfig.Init)
+		}
+	}
+}
+
+func TestParseDeviceCgroupRule(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--device-cgroup-rule=c 189:* rmw", "--device-cgroup-rule=b 8:* r", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"c 189:* rmw", "b 8:* r"}
+	if !reflect.DeepEqual(hostconfig.DeviceCgroupRules, expected) {
+		t.Fatalf("Expected device cgroup rules %v, got %v", expected, hostconfig.DeviceCgroupRules)
+	}
+
+	for _, rule := range []string{"c 189 rmw", "c 189:* x", "d 1:1 r", "c *:*"} {
+		if _, _, _, _, err := parseRun([]string{"--device-cgroup-rule=" + rule, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error for the invalid rule %q", rule)
+		}
+	}
+}
+
+func TestParsePidsLimit(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--pids-limit=100", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostconfig.PidsLimit != 100 {
+		t.Fatalf("Expected a pids limit of 100, got %d", hostconfig.PidsLimit)
+	}
+}
+
+func TestParseNetworkRateLimits(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--net-egress-rate=1mb", "--net-ingress-rate=eth0:2mb", "--net-ingress-rate=eth1:512kb", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []container.NetworkRateLimit{
+		{Interface: "eth0", EgressRate: 1024 * 1024, IngressRate: 2 * 1024 * 1024},
+		{Interface: "eth1", IngressRate: 512 * 1024},
+	}
+	if !reflect.DeepEqual(hostconfig.NetworkRateLimits, expected) {
+		t.Fatalf("Expected network rate limits %v, got %v", expected, hostconfig.NetworkRateLimits)
+	}
+
+	for _, rate := range []string{"eth0:", ":1mb", "eth0:-1", "eth0:fast"} {
+		if _, _, _, _, err := parseRun([]string{"--net-egress-rate=" + rate, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error for the invalid rate %q", rate)
+		}
+	}
+}
+
+func TestParseSysctls(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--sysctl=net.ipv4.ip_forward=1", "--sysctl=kernel.shmmax=1073741824", "--sysctl=fs.mqueue.queues_max=512", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"net.ipv4.ip_forward":  "1",
+		"kernel.shmmax":        "1073741824",
+		"fs.mqueue.queues_max": "512",
+	}
+	if !reflect.DeepEqual(hostconfig.Sysctls, expected) {
+		t.Fatalf("Expected sysctls %v, got %v", expected, hostconfig.Sysctls)
+	}
+
+	for _, sysctl := range []string{"net.ipv4.ip_forward", "=1", "kernel.hostname=foo", "vm.swappiness=10"} {
+		if _, _, _, _, err := parseRun([]string{"--sysctl=" + sysctl, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error for the invalid sysctl %q", sysctl)
+		}
+	}
+}
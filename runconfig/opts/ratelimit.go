@@ -0,0 +1,67 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/engine-api/types/container"
+	"github.com/docker/go-units"
+)
+
+// defaultRateLimitInterface is the interface a network rate limit applies to
+// if none is given.
+const defaultRateLimitInterface = "eth0"
+
+// ValidateNetworkRate validates that the specified string has a valid
+// [interface:]rate format.
+func ValidateNetworkRate(val string) (string, error) {
+	if _, _, err := parseNetworkRate(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func parseNetworkRate(val string) (string, uint64, error) {
+	iface, rateStr := defaultRateLimitInterface, val
+	if i := strings.LastIndex(val, ":"); i >= 0 {
+		iface, rateStr = val[:i], val[i+1:]
+	}
+	rate, err := units.RAMInBytes(rateStr)
+	if iface == "" || err != nil || rate < 0 {
+		return "", 0, fmt.Errorf("invalid network rate: %s. The correct format is [<interface>:]<number>[<unit>]. Number must be a positive integer, or 0 for unlimited. Unit is optional and can be kb, mb, or gb", val)
+	}
+	return iface, uint64(rate), nil
+}
+
+// ParseNetworkRateLimits combines the egress and ingress rates, in the
+// [interface:]rate format, into the rate limits of each interface.
+func ParseNetworkRateLimits(egress, ingress []string) ([]container.NetworkRateLimit, error) {
+	var limits []container.NetworkRateLimit
+	index := make(map[string]int)
+	for _, rates := range []struct {
+		values  []string
+		ingress bool
+	}{
+		{egress, false},
+		{ingress, true},
+	} {
+		for _, val := range rates.values {
+			iface, rate, err := parseNetworkRate(val)
+			if err != nil {
+				return nil, err
+			}
+			i, ok := index[iface]
+			if !ok {
+				i = len(limits)
+				index[iface] = i
+				limits = append(limits, container.NetworkRateLimit{Interface: iface})
+			}
+			if rates.ingress {
+				limits[i].IngressRate = rate
+			} else {
+				limits[i].EgressRate = rate
+			}
+		}
+	}
+	return limits, nil
+}
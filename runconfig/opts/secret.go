@@ -0,0 +1,82 @@
+package opts
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/engine-api/types/container"
+)
+
+// SecretOpt holds the secrets of the daemon to mount in a container
+type SecretOpt struct {
+	values []container.SecretReference
+}
+
+// NewSecretOpt creates a new SecretOpt
+func NewSecretOpt() *SecretOpt {
+	return &SecretOpt{}
+}
+
+// Set parses a secret, either its name or a comma separated list of
+// key=value options among source, target, uid, gid and mode, such as
+// source=db-password,target=/etc/db/password,mode=0440
+func (o *SecretOpt) Set(val string) error {
+	if !strings.Contains(val, "=") {
+		o.values = append(o.values, container.SecretReference{Name: val})
+		return nil
+	}
+
+	var ref container.SecretReference
+	for _, field := range strings.Split(val, ",") {
+		arr := strings.SplitN(field, "=", 2)
+		if len(arr) != 2 {
+			return fmt.Errorf("invalid secret %s: expected key=value, got %s", val, field)
+		}
+		key, value := arr[0], arr[1]
+		switch key {
+		case "source", "src":
+			ref.Name = value
+		case "target":
+			ref.Target = value
+		case "uid", "gid":
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 0 {
+				return fmt.Errorf("invalid secret %s: invalid %s %s", val, key, value)
+			}
+			if key == "uid" {
+				ref.UID = id
+			} else {
+				ref.GID = id
+			}
+		case "mode":
+			mode, err := strconv.ParseUint(value, 8, 32)
+			if err != nil || os.FileMode(mode)&^os.ModePerm != 0 {
+				return fmt.Errorf("invalid secret %s: invalid mode %s", val, value)
+			}
+			ref.Mode = os.FileMode(mode)
+		default:
+			return fmt.Errorf("invalid secret %s: unknown option %s", val, key)
+		}
+	}
+	if ref.Name == "" {
+		return fmt.Errorf("invalid secret %s: source is required", val)
+	}
+	o.values = append(o.values, ref)
+	return nil
+}
+
+// String returns the secrets as a string.
+func (o *SecretOpt) String() string {
+	var out []string
+	for _, ref := range o.values {
+		out = append(out, ref.Name)
+	}
+	return fmt.Sprintf("%v", out)
+}
+
+// GetList returns the secret references.
+func (o *SecretOpt) GetList() []container.SecretReference {
+	return o.values
+}
@@ -0,0 +1,46 @@
+package opts
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSecretOpt(t *testing.T) {
+	o := NewSecretOpt()
+	if err := o.Set("db-password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Set("source=tls-key,target=/etc/ssl/key.pem,uid=33,gid=33,mode=0440"); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := o.GetList()
+	if len(refs) != 2 {
+		t.Fatalf("Expected 2 secrets, got %v", refs)
+	}
+	if refs[0].Name != "db-password" || refs[0].Target != "" || refs[0].Mode != 0 {
+		t.Fatalf("Unexpected secret %+v", refs[0])
+	}
+	if refs[1].Name != "tls-key" || refs[1].Target != "/etc/ssl/key.pem" || refs[1].UID != 33 || refs[1].GID != 33 || refs[1].Mode != os.FileMode(0440) {
+		t.Fatalf("Unexpected secret %+v", refs[1])
+	}
+	if o.String() != "[db-password tls-key]" {
+		t.Fatalf("Unexpected secrets %s", o.String())
+	}
+}
+
+func TestSecretOptInvalid(t *testing.T) {
+	for _, val := range []string{
+		"target=/etc/key",
+		"source=key,target",
+		"source=key,uid=-1",
+		"source=key,gid=www-data",
+		"source=key,mode=0999",
+		"source=key,mode=04400",
+		"source=key,readonly=true",
+	} {
+		if err := NewSecretOpt().Set(val); err == nil {
+			t.Fatalf("Expected an error for the secret %s", val)
+		}
+	}
+}
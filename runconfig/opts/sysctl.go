@@ -0,0 +1,51 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namespaces of the sysctls a container can set.
+const (
+	SysctlNamespaceIPC = "ipc"
+	SysctlNamespaceNet = "net"
+)
+
+// ipcSysctls are the sysctls of the IPC namespace. The fs.mqueue.* sysctls
+// belong to it as well.
+var ipcSysctls = map[string]bool{
+	"kernel.msgmax":          true,
+	"kernel.msgmnb":          true,
+	"kernel.msgmni":          true,
+	"kernel.sem":             true,
+	"kernel.shmall":          true,
+	"kernel.shmmax":          true,
+	"kernel.shmmni":          true,
+	"kernel.shm_rmid_forced": true,
+}
+
+// SysctlNamespace returns the namespace the sysctl key belongs to. Only the
+// sysctls of the IPC and network namespaces are accepted, as the others
+// would change the settings of the host.
+func SysctlNamespace(key string) (string, error) {
+	switch {
+	case ipcSysctls[key], strings.HasPrefix(key, "fs.mqueue."):
+		return SysctlNamespaceIPC, nil
+	case strings.HasPrefix(key, "net."):
+		return SysctlNamespaceNet, nil
+	}
+	return "", fmt.Errorf("sysctl %s is not whitelisted, only the sysctls of the IPC and network namespaces can be set", key)
+}
+
+// ValidateSysctl validates a sysctl in the form key=value, where key is a
+// whitelisted namespaced sysctl.
+func ValidateSysctl(val string) (string, error) {
+	arr := strings.SplitN(val, "=", 2)
+	if len(arr) != 2 || arr[0] == "" {
+		return "", fmt.Errorf("invalid sysctl %s: expected key=value", val)
+	}
+	if _, err := SysctlNamespace(arr[0]); err != nil {
+		return "", err
+	}
+	return val, nil
+}
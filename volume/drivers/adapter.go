@@ -1,6 +1,10 @@
 package volumedrivers
 
-import "github.com/docker/docker/volume"
+import (
+	"time"
+
+	"github.com/docker/docker/volume"
+)
 
 type volumeDriverAdapter struct {
 	name  string
@@ -12,6 +16,7 @@ func (a *volumeDriverAdapter) Name() string {
 }
 
 func (a *volumeDriverAdapter) Create(name string, opts map[string]string) (volume.Volume, error) {
+	defer driverCalls.ObserveSince(time.Now(), a.name, "create")
 	err := a.proxy.Create(name, opts)
 	if err != nil {
 		return nil, err
@@ -23,10 +28,12 @@ func (a *volumeDriverAdapter) Create(name string, opts map[string]string) (volum
 }
 
 func (a *volumeDriverAdapter) Remove(v volume.Volume) error {
+	defer driverCalls.ObserveSince(time.Now(), a.name, "remove")
 	return a.proxy.Remove(v.Name())
 }
 
 func (a *volumeDriverAdapter) List() ([]volume.Volume, error) {
+	defer driverCalls.ObserveSince(time.Now(), a.name, "list")
 	ls, err := a.proxy.List()
 	if err != nil {
 		return nil, err
@@ -45,6 +52,7 @@ func (a *volumeDriverAdapter) List() ([]volume.Volume, error) {
 }
 
 func (a *volumeDriverAdapter) Get(name string) (volume.Volume, error) {
+	defer driverCalls.ObserveSince(time.Now(), a.name, "get")
 	v, err := a.proxy.Get(name)
 	if err != nil {
 		return nil, err
@@ -82,16 +90,19 @@ func (a *volumeAdapter) Path() string {
 	if len(a.eMount) > 0 {
 		return a.eMount
 	}
+	defer driverCalls.ObserveSince(time.Now(), a.driverName, "path")
 	m, _ := a.proxy.Path(a.name)
 	return m
 }
 
 func (a *volumeAdapter) Mount() (string, error) {
+	defer driverCalls.ObserveSince(time.Now(), a.driverName, "mount")
 	var err error
 	a.eMount, err = a.proxy.Mount(a.name)
 	return a.eMount, err
 }
 
 func (a *volumeAdapter) Unmount() error {
+	defer driverCalls.ObserveSince(time.Now(), a.driverName, "unmount")
 	return a.proxy.Unmount(a.name)
 }
@@ -0,0 +1,17 @@
+package volumedrivers
+
+import (
+	"github.com/docker/docker/pkg/metrics"
+)
+
+// driverCalls records the latency of the calls to volume driver plugins.
+var driverCalls = metrics.NewHistogram(
+	"engine_daemon_volume_driver_seconds",
+	"The number of seconds it takes to process each volume driver call",
+	metrics.DefBuckets,
+	"driver", "method",
+)
+
+func init() {
+	metrics.MustRegister(driverCalls)
+}